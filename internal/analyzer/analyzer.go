@@ -2,7 +2,11 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -11,51 +15,202 @@ import (
 	"github.com/ibeckermayer/scroll4me/internal/types"
 )
 
+// triageBatchSize is how many posts go in each triage request. Triage
+// replies are short, so batches can be larger than for full analysis.
+const triageBatchSize = 100
+
+// maxConcurrentBatches bounds in-flight LLM calls so later batches can react
+// to the quota reported by earlier ones
+const maxConcurrentBatches = 4
+
 // Provider defines the interface for LLM providers
 type Provider interface {
 	Analyze(ctx context.Context, posts []types.Post, interests config.InterestsConfig) ([]types.Analysis, error)
 }
 
+// TrendSummarizer is implemented by providers that can summarize trends
+type TrendSummarizer interface {
+	SummarizeTrends(ctx context.Context, trends []types.Trend, interests config.InterestsConfig) (string, error)
+}
+
+// TopicSuggester is implemented by providers that can find candidate
+// interest topics in posts
+type TopicSuggester interface {
+	SuggestTopics(ctx context.Context, posts []types.Post) ([]types.InterestTopic, error)
+}
+
+// SectionGrouper is implemented by providers that can group a digest's
+// posts into topic sections
+type SectionGrouper interface {
+	GroupIntoSections(ctx context.Context, posts []types.PostWithAnalysis) ([]types.TopicSection, error)
+}
+
+// DiscussionSummarizer is implemented by providers that can rewrite post
+// summaries to take in the replies to them (Post.Discussion)
+type DiscussionSummarizer interface {
+	SummarizeDiscussions(ctx context.Context, posts []types.PostWithAnalysis) (map[string]string, error)
+}
+
+// Calibrator is implemented by providers that can show the LLM posts the
+// user rated, as examples of their taste
+type Calibrator interface {
+	SetExamples(examples []types.RatedPost)
+}
+
+// Triager is implemented by providers that can pick out the posts that
+// could be relevant, returning their IDs
+type Triager interface {
+	Triage(ctx context.Context, posts []types.Post, interests config.InterestsConfig) ([]string, error)
+}
+
+// CacheWarmer is implemented by providers with a prompt cache, reporting
+// whether the instructions the batches of a call share (e.g.
+// providers.CallAnalyze) are long enough to be cached
+type CacheWarmer interface {
+	CachesInstructions(call string, interests config.InterestsConfig) bool
+}
+
+// QuotaReporter is implemented by providers that track their rate-limit quota
+type QuotaReporter interface {
+	Quota() (providers.Quota, bool)
+}
+
 // Analyzer handles LLM-based post analysis
 type Analyzer struct {
-	provider  Provider
-	interests config.InterestsConfig
-	batchSize int
+	provider      Provider
+	fallback      Provider // Cheaper model to downgrade to, or nil
+	fallbackModel string
+	triage        Provider // Cheaper model that screens posts first, or nil
+	quotaAction   string
+	interests     config.InterestsConfig
+	batchSize     int
 }
 
 // New creates a new analyzer with the appropriate provider based on config
 func New(analysisConfig config.AnalysisConfig, interests config.InterestsConfig) (*Analyzer, error) {
-	var provider Provider
+	provider, err := newProvider(analysisConfig, analysisConfig.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	// Configs written before quota_action existed don't set it
+	quotaAction := analysisConfig.QuotaAction
+	if quotaAction == "" {
+		quotaAction = config.QuotaActionDefer
+	}
 
+	a := &Analyzer{
+		provider:    provider,
+		quotaAction: quotaAction,
+		interests:   interests,
+		batchSize:   analysisConfig.BatchSize,
+	}
+
+	switch quotaAction {
+	case config.QuotaActionDowngrade:
+		if analysisConfig.FallbackModel == "" {
+			return nil, fmt.Errorf("quota_action %q requires fallback_model", config.QuotaActionDowngrade)
+		}
+		if a.fallback, err = newProvider(analysisConfig, analysisConfig.FallbackModel); err != nil {
+			return nil, err
+		}
+		a.fallbackModel = analysisConfig.FallbackModel
+	case config.QuotaActionDefer, config.QuotaActionFail:
+	default:
+		return nil, fmt.Errorf("unknown quota_action: %s (use %q, %q, or %q)", quotaAction,
+			config.QuotaActionDefer, config.QuotaActionDowngrade, config.QuotaActionFail)
+	}
+
+	if analysisConfig.TriageModel != "" {
+		if a.triage, err = newProvider(analysisConfig, analysisConfig.TriageModel); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+// NewWithProvider creates an analyzer that sends batches of batchSize posts
+// to the given provider, failing if its quota runs out. Used to run the
+// pipeline against a fake provider.
+func NewWithProvider(provider Provider, interests config.InterestsConfig, batchSize int) *Analyzer {
+	return &Analyzer{
+		provider:    provider,
+		quotaAction: config.QuotaActionFail,
+		interests:   interests,
+		batchSize:   batchSize,
+	}
+}
+
+// newProvider constructs the configured provider for the given model
+func newProvider(analysisConfig config.AnalysisConfig, model string) (Provider, error) {
 	switch analysisConfig.LLMProvider {
 	case config.ProviderAnthropic:
-		provider = providers.NewAnthropicProvider(analysisConfig.APIKey, analysisConfig.Model)
+		provider := providers.NewAnthropicProvider(analysisConfig.APIKey, model, analysisConfig.BaseURL, analysisConfig.ExtraHeaders)
+		provider.SetPromptLanguage(analysisConfig.PromptLanguage)
+		return provider, nil
 	// case config.ProviderOpenAI:
-	// 	provider = providers.NewOpenAIProvider(analysisConfig.APIKey, analysisConfig.Model)
+	// 	return providers.NewOpenAIProvider(analysisConfig.APIKey, model), nil
 	default:
 		return nil, fmt.Errorf("unknown LLM provider: %s", analysisConfig.LLMProvider)
 	}
+}
 
-	return &Analyzer{
-		provider:  provider,
-		interests: interests,
-		batchSize: analysisConfig.BatchSize,
-	}, nil
+// SetExamples has the provider, and the fallback provider if any, show the
+// LLM the given posts the user rated when analyzing. Providers that can't
+// are left as they are.
+func (a *Analyzer) SetExamples(examples []types.RatedPost) {
+	for _, provider := range []Provider{a.provider, a.fallback} {
+		if calibrator, ok := provider.(Calibrator); ok {
+			calibrator.SetExamples(examples)
+		}
+	}
+}
+
+// quotaLow reports whether the primary provider last reported a nearly
+// exhausted quota
+func (a *Analyzer) quotaLow() bool {
+	reporter, ok := a.provider.(QuotaReporter)
+	if !ok {
+		return false
+	}
+	quota, known := reporter.Quota()
+	return known && quota.Low()
 }
 
-// AnalyzePosts processes posts through the LLM for relevance scoring
-func (a *Analyzer) AnalyzePosts(ctx context.Context, posts []types.Post) ([]types.Analysis, error) {
+// AnalyzePosts processes posts through the LLM for relevance scoring.
+// With a triage model, only the posts it picks out are analyzed in full.
+// When the provider's quota runs low, batches are downgraded or deferred
+// according to the configured quota action; deferred posts are returned
+// so they can be analyzed on the next run, along with any posts a reply
+// that was cut short left without an analysis.
+func (a *Analyzer) AnalyzePosts(ctx context.Context, posts []types.Post) ([]types.Analysis, []types.Post, error) {
 	if len(posts) == 0 {
-		return nil, nil
+		return nil, nil, nil
+	}
+
+	var triaged []types.Analysis
+	if a.triage != nil {
+		if posts, triaged = a.triagePosts(ctx, posts); len(posts) == 0 {
+			return triaged, nil, nil
+		}
 	}
 
 	// Calculate number of batches
 	numBatches := (len(posts) + a.batchSize - 1) / a.batchSize
 
-	// Pre-allocate results slice (one slice per batch)
+	// Pre-allocate results slices (one slice per batch)
 	results := make([][]types.Analysis, numBatches)
+	deferred := make([][]types.Post, numBatches)
+	unanswered := make([][]types.Post, numBatches) // Left out of a reply cut short
+
+	var mu sync.Mutex
+	downgraded := 0
 
 	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxConcurrentBatches)
+
+	warmup := a.newCacheWarmup(a.provider, providers.CallAnalyze)
 
 	// Process batches concurrently
 	for i := 0; i < len(posts); i += a.batchSize {
@@ -65,18 +220,55 @@ func (a *Analyzer) AnalyzePosts(ctx context.Context, posts []types.Post) ([]type
 		batch := posts[start:end]
 
 		g.Go(func() error {
-			analyses, err := a.provider.Analyze(ctx, batch, a.interests)
+			if batchIdx == 0 {
+				defer warmup.done()
+			} else {
+				warmup.wait(ctx)
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+
+			provider := a.provider
+			if a.quotaLow() {
+				switch a.quotaAction {
+				case config.QuotaActionDefer:
+					deferred[batchIdx] = batch
+					return nil
+				case config.QuotaActionDowngrade:
+					provider = a.fallback
+					mu.Lock()
+					downgraded++
+					mu.Unlock()
+				}
+			}
+
+			analyses, err := provider.Analyze(ctx, batch, a.interests)
+			if errors.Is(err, providers.ErrRateLimited) {
+				switch {
+				case a.quotaAction == config.QuotaActionDefer:
+					deferred[batchIdx] = batch
+					return nil
+				case a.quotaAction == config.QuotaActionDowngrade && provider != a.fallback:
+					mu.Lock()
+					downgraded++
+					mu.Unlock()
+					analyses, err = a.fallback.Analyze(ctx, batch, a.interests)
+				}
+			}
 			if err != nil {
 				return fmt.Errorf("failed to analyze batch %d: %w", batchIdx, err)
 			}
 			results[batchIdx] = analyses
+			unanswered[batchIdx] = missingAnalyses(batch, analyses)
+			log.Printf("Analyzed batch %d of %d (%d posts)", batchIdx+1, numBatches, len(analyses))
 			return nil
 		})
 	}
 
 	// Wait for all goroutines and check for errors
 	if err := g.Wait(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Flatten results in order
@@ -84,6 +276,180 @@ func (a *Analyzer) AnalyzePosts(ctx context.Context, posts []types.Post) ([]type
 	for _, batchResult := range results {
 		allAnalyses = append(allAnalyses, batchResult...)
 	}
+	allAnalyses = append(allAnalyses, triaged...)
+	var allDeferred, allUnanswered []types.Post
+	for _, batch := range deferred {
+		allDeferred = append(allDeferred, batch...)
+	}
+	for _, batch := range unanswered {
+		allUnanswered = append(allUnanswered, batch...)
+	}
 
-	return allAnalyses, nil
+	if downgraded > 0 {
+		log.Printf("LLM quota low: analyzed %d batches with fallback model %s", downgraded, a.fallbackModel)
+	}
+	if len(allDeferred) > 0 {
+		log.Printf("LLM quota low: deferred %d posts to the next run", len(allDeferred))
+	}
+	if len(allUnanswered) > 0 {
+		log.Printf("Replies left %d posts without an analysis: deferred to the next run", len(allUnanswered))
+	}
+
+	return allAnalyses, append(allDeferred, allUnanswered...), nil
+}
+
+// cacheWarmup holds the later batches of a call back until the first has
+// finished, so they read the instructions they share from the provider's
+// prompt cache instead of each writing them to it. It lets them all run at
+// once if the instructions are too short to be cached, where waiting would
+// only add latency.
+type cacheWarmup struct {
+	warmed chan struct{}
+	done   func() // Called by the first batch once it has finished
+}
+
+// newCacheWarmup returns the warm-up for the batches of call to provider
+func (a *Analyzer) newCacheWarmup(provider Provider, call string) cacheWarmup {
+	warmed := make(chan struct{})
+	w := cacheWarmup{warmed: warmed, done: sync.OnceFunc(func() { close(warmed) })}
+	if warmer, ok := provider.(CacheWarmer); !ok || !warmer.CachesInstructions(call, a.interests) {
+		w.done()
+	}
+	return w
+}
+
+// wait blocks until the first batch has finished, or ctx is done
+func (w cacheWarmup) wait(ctx context.Context) {
+	select {
+	case <-w.warmed:
+	case <-ctx.Done():
+	}
+}
+
+// missingAnalyses returns the posts of batch that analyses has no entry for
+func missingAnalyses(batch []types.Post, analyses []types.Analysis) []types.Post {
+	answered := make(map[string]bool, len(analyses))
+	for _, a := range analyses {
+		answered[a.PostID] = true
+	}
+	var missing []types.Post
+	for _, p := range batch {
+		if !answered[p.ID] {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// triagePosts has the triage model pick out the posts worth a full
+// analysis. Returns those, in order, and an analysis scoring 0 for each of
+// the rest. Mentions are always kept, and so is every post of a batch the
+// triage model fails on.
+func (a *Analyzer) triagePosts(ctx context.Context, posts []types.Post) ([]types.Post, []types.Analysis) {
+	triager, ok := a.triage.(Triager)
+	if !ok {
+		log.Println("Triage model's provider can't triage - analyzing every post")
+		return posts, nil
+	}
+
+	keep := make(map[string]bool)
+	var candidates []types.Post
+	for _, p := range posts {
+		if p.Source == types.SourceMentions {
+			keep[p.ID] = true
+		} else {
+			candidates = append(candidates, p)
+		}
+	}
+
+	var mu sync.Mutex
+	var g errgroup.Group
+	g.SetLimit(maxConcurrentBatches)
+	warmup := a.newCacheWarmup(a.triage, providers.CallTriage)
+	for i := 0; i < len(candidates); i += triageBatchSize {
+		batch := candidates[i:min(i+triageBatchSize, len(candidates))]
+		first := i == 0
+		g.Go(func() error {
+			if first {
+				defer warmup.done()
+			} else {
+				// A cancelled wait fails the triage call below, keeping the batch
+				warmup.wait(ctx)
+			}
+			relevant, err := triager.Triage(ctx, batch, a.interests)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Failed to triage %d posts - analyzing them all: %v", len(batch), err)
+				for _, p := range batch {
+					keep[p.ID] = true
+				}
+				return nil
+			}
+			for _, id := range relevant {
+				keep[id] = true
+			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	var kept []types.Post
+	var dropped []types.Analysis
+	now := time.Now()
+	for _, p := range posts {
+		if keep[p.ID] {
+			kept = append(kept, p)
+			continue
+		}
+		dropped = append(dropped, types.Analysis{
+			PostID:     p.ID,
+			Reason:     "Ruled out by the triage model",
+			AnalyzedAt: now,
+			RuledOut:   true,
+		})
+	}
+	log.Printf("Triage kept %d of %d posts for full analysis", len(kept), len(posts))
+	return kept, dropped
+}
+
+// SummarizeTrends asks the LLM for a short "trending context" note about
+// the given trends, written with the user's interests in mind
+func (a *Analyzer) SummarizeTrends(ctx context.Context, trends []types.Trend) (string, error) {
+	summarizer, ok := a.provider.(TrendSummarizer)
+	if !ok {
+		return "", errors.New("LLM provider can't summarize trends")
+	}
+	return summarizer.SummarizeTrends(ctx, trends, a.interests)
+}
+
+// SuggestTopics asks the LLM for the subjects that come up most in posts,
+// for a user who hasn't configured any interests to pick from
+func (a *Analyzer) SuggestTopics(ctx context.Context, posts []types.Post) ([]types.InterestTopic, error) {
+	suggester, ok := a.provider.(TopicSuggester)
+	if !ok {
+		return nil, errors.New("LLM provider can't suggest topics")
+	}
+	return suggester.SuggestTopics(ctx, posts)
+}
+
+// GroupIntoSections asks the LLM to group posts into topic sections such as
+// "AI" or "Politics", for a digest rendered by section
+func (a *Analyzer) GroupIntoSections(ctx context.Context, posts []types.PostWithAnalysis) ([]types.TopicSection, error) {
+	grouper, ok := a.provider.(SectionGrouper)
+	if !ok {
+		return nil, errors.New("LLM provider can't group posts into sections")
+	}
+	return grouper.GroupIntoSections(ctx, posts)
+}
+
+// SummarizeDiscussions asks the LLM to rewrite the summaries of posts to
+// take in the replies fetched into their Discussion. Returns the new
+// summaries by post ID.
+func (a *Analyzer) SummarizeDiscussions(ctx context.Context, posts []types.PostWithAnalysis) (map[string]string, error) {
+	summarizer, ok := a.provider.(DiscussionSummarizer)
+	if !ok {
+		return nil, errors.New("LLM provider can't summarize discussions")
+	}
+	return summarizer.SummarizeDiscussions(ctx, posts)
 }
@@ -2,8 +2,12 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/anthropics/anthropic-sdk-go"
@@ -13,18 +17,36 @@ import (
 	"github.com/ibeckermayer/scroll4me/internal/types"
 )
 
+// streamProgressInterval is how often progress is logged while a reply
+// streams in
+const streamProgressInterval = 15 * time.Second
+
+// ErrIncomplete is returned, along with the part that arrived, when a reply
+// stops short: it hit the token limit, or the stream broke off
+var ErrIncomplete = errors.New("Claude's reply was cut short")
+
 // AnthropicProvider implements the Provider interface using Anthropic's Claude API
 type AnthropicProvider struct {
 	client   *anthropic.Client
 	provider string // e.g. "anthropic"
 	model    string
+	language string // Language replies are written in, or "" for the model's choice
+	examples []types.RatedPost
+	quotaTracker
 }
 
-// NewAnthropicProvider creates a new Anthropic provider
-func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
-	client := anthropic.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+// NewAnthropicProvider creates a new Anthropic provider. If baseURL is set,
+// requests go there instead of Anthropic's API (e.g. to a gateway), and
+// headers are added to every request.
+func NewAnthropicProvider(apiKey, model, baseURL string, headers map[string]string) *AnthropicProvider {
+	opts := []option.RequestOption{option.WithAPIKey(apiKey)}
+	if baseURL != "" {
+		opts = append(opts, option.WithBaseURL(baseURL))
+	}
+	for name, value := range headers {
+		opts = append(opts, option.WithHeader(name, value))
+	}
+	client := anthropic.NewClient(opts...)
 	return &AnthropicProvider{
 		client:   &client,
 		provider: config.ProviderAnthropic,
@@ -32,50 +54,377 @@ func NewAnthropicProvider(apiKey, model string) *AnthropicProvider {
 	}
 }
 
-// Analyze sends posts to Claude for relevance analysis
+// SetPromptLanguage makes every prompt ask for replies in language (e.g.
+// "German"), whatever language the posts are in. "" leaves it to the model.
+func (c *AnthropicProvider) SetPromptLanguage(language string) {
+	c.language = language
+}
+
+// SetExamples makes analysis prompts show the given posts the user rated,
+// to calibrate scoring to their taste. Nil removes them.
+func (c *AnthropicProvider) SetExamples(examples []types.RatedPost) {
+	c.examples = examples
+}
+
+// Tools Claude is made to call to give structured replies. Their input
+// schemas match what ParseAnalysisResponse and the other parse functions
+// expect, wrapped in an object since tool input must be one.
+var (
+	analysesTool = anthropic.ToolParam{
+		Name:        "record_analyses",
+		Description: anthropic.String("Record the analysis of every post, one entry per post."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"analyses": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"post_id":         map[string]any{"type": "string"},
+							"relevance_score": map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+							"topics":          map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "maxItems": 3},
+							"summary":         map[string]any{"type": "string"},
+							"engagement_bait": map[string]any{"type": "boolean"},
+							"reason":          map[string]any{"type": "string"},
+							"urgency":         map[string]any{"type": "number", "minimum": 0, "maximum": 1},
+						},
+						"required": []string{"post_id", "relevance_score", "topics", "summary", "engagement_bait", "reason", "urgency"},
+					},
+				},
+			},
+			Required: []string{"analyses"},
+		},
+	}
+	topicsTool = anthropic.ToolParam{
+		Name:        "record_topics",
+		Description: anthropic.String("Record the subjects found in the posts, most common first."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"topics": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"keyword":     map[string]any{"type": "string"},
+							"description": map[string]any{"type": "string"},
+							"post_count":  map[string]any{"type": "integer", "minimum": 0},
+						},
+						"required": []string{"keyword", "description", "post_count"},
+					},
+				},
+			},
+			Required: []string{"topics"},
+		},
+	}
+	triageTool = anthropic.ToolParam{
+		Name:        "record_triage",
+		Description: anthropic.String("Record the IDs of the posts that could be relevant."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"relevant_post_ids": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			},
+			Required: []string{"relevant_post_ids"},
+		},
+	}
+	sectionsTool = anthropic.ToolParam{
+		Name:        "record_sections",
+		Description: anthropic.String("Record the sections the posts are grouped into."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"sections": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"name":     map[string]any{"type": "string"},
+							"post_ids": map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+						},
+						"required": []string{"name", "post_ids"},
+					},
+				},
+			},
+			Required: []string{"sections"},
+		},
+	}
+	discussionTool = anthropic.ToolParam{
+		Name:        "record_summaries",
+		Description: anthropic.String("Record the rewritten summary of every post, one entry per post."),
+		InputSchema: anthropic.ToolInputSchemaParam{
+			Properties: map[string]any{
+				"summaries": map[string]any{
+					"type": "array",
+					"items": map[string]any{
+						"type": "object",
+						"properties": map[string]any{
+							"post_id": map[string]any{"type": "string"},
+							"summary": map[string]any{"type": "string"},
+						},
+						"required": []string{"post_id", "summary"},
+					},
+				},
+			},
+			Required: []string{"summaries"},
+		},
+	}
+)
+
+// Analyze sends posts to Claude for relevance analysis. If the reply is cut
+// short or doesn't parse, the analyses in it that are complete are
+// returned; the analyzer defers the posts left without one.
 func (c *AnthropicProvider) Analyze(ctx context.Context, posts []types.Post, interests config.InterestsConfig) ([]types.Analysis, error) {
-	prompt := buildPrompt(posts, interests)
+	instructions, batch := buildPrompt(posts, interests, c.examples, c.language)
+	input, err := c.completeCached(ctx, CallAnalyze, instructions, batch, &analysesTool)
+	if err != nil && !errors.Is(err, ErrIncomplete) {
+		return nil, err
+	}
+	if err == nil {
+		var results []byte
+		if results, err = toolField(input, "analyses"); err == nil {
+			var analyses []types.Analysis
+			if analyses, err = ParseAnalysisResponse(results); err == nil {
+				return analyses, nil
+			}
+		}
+	}
+
+	// Cut short or unparseable: keep whatever analyses came through whole
+	if analyses := SalvageAnalyses([]byte(input)); len(analyses) > 0 {
+		log.Printf("Salvaged %d of %d analyses from a broken reply (%v)", len(analyses), len(posts), err)
+		return analyses, nil
+	}
+	return nil, err
+}
+
+// SummarizeTrends asks Claude for a short "trending context" paragraph
+// about the given trends
+func (c *AnthropicProvider) SummarizeTrends(ctx context.Context, trends []types.Trend, interests config.InterestsConfig) (string, error) {
+	return c.complete(ctx, CallTrends, buildTrendsPrompt(trends, interests, c.language), nil)
+}
+
+// SuggestTopics asks Claude for the subjects that come up most in posts, as
+// candidate interests
+func (c *AnthropicProvider) SuggestTopics(ctx context.Context, posts []types.Post) ([]types.InterestTopic, error) {
+	input, err := c.complete(ctx, CallTopics, buildTopicsPrompt(posts, c.language), &topicsTool)
+	if err != nil {
+		return nil, err
+	}
+	topics, err := toolField(input, "topics")
+	if err != nil {
+		return nil, err
+	}
+	return parseTopicsResponse(topics)
+}
+
+// Triage asks Claude for the IDs of the posts that could be relevant to
+// interests, to analyze only those in full
+func (c *AnthropicProvider) Triage(ctx context.Context, posts []types.Post, interests config.InterestsConfig) ([]string, error) {
+	instructions, batch := buildTriagePrompt(posts, interests)
+	input, err := c.completeCached(ctx, CallTriage, instructions, batch, &triageTool)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := toolField(input, "relevant_post_ids")
+	if err != nil {
+		return nil, err
+	}
+	var relevant []string
+	if err := json.Unmarshal(ids, &relevant); err != nil {
+		return nil, fmt.Errorf("failed to parse triage JSON: %w (response was: %.500s)", err, string(ids))
+	}
+	return relevant, nil
+}
+
+// GroupIntoSections asks Claude to group a digest's posts into topic
+// sections
+func (c *AnthropicProvider) GroupIntoSections(ctx context.Context, posts []types.PostWithAnalysis) ([]types.TopicSection, error) {
+	input, err := c.complete(ctx, CallSections, buildSectionsPrompt(posts, c.language), &sectionsTool)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := toolField(input, "sections")
+	if err != nil {
+		return nil, err
+	}
+	return parseSectionsResponse(sections)
+}
+
+// SummarizeDiscussions asks Claude to rewrite the summaries of posts to
+// take in the replies to them. Returns the new summaries by post ID.
+func (c *AnthropicProvider) SummarizeDiscussions(ctx context.Context, posts []types.PostWithAnalysis) (map[string]string, error) {
+	input, err := c.complete(ctx, CallDiscussion, buildDiscussionPrompt(posts, c.language), &discussionTool)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := toolField(input, "summaries")
+	if err != nil {
+		return nil, err
+	}
+	return parseDiscussionResponse(summaries)
+}
+
+// complete sends prompt to Claude and returns its text reply, or if tool
+// is set, makes Claude call the tool and returns the tool's input JSON.
+// The schema only guides the model; the API doesn't enforce it, so callers
+// still parse and check the input. Every exchange is cached for debugging,
+// and its token usage logged under call (e.g. CallAnalyze). The reply is
+// streamed; if it stops short, whatever arrived is returned with an
+// ErrIncomplete error.
+func (c *AnthropicProvider) complete(ctx context.Context, call, prompt string, tool *anthropic.ToolParam) (string, error) {
+	return c.completeCached(ctx, call, "", prompt, tool)
+}
 
-	// Use prefilling to ensure Claude continues with valid JSON (starting after the "[")
-	message, err := c.client.Messages.New(ctx, anthropic.MessageNewParams{
+// CachesInstructions reports whether the instructions that call's batches
+// share (CallAnalyze or CallTriage) are long enough for the model's prompt
+// cache, so batches after the first can read them from it. The length is
+// estimated at four characters a token.
+func (c *AnthropicProvider) CachesInstructions(call string, interests config.InterestsConfig) bool {
+	var instructions string
+	var tool *anthropic.ToolParam
+	switch call {
+	case CallAnalyze:
+		instructions, _ = buildPrompt(nil, interests, c.examples, c.language)
+		tool = &analysesTool
+	case CallTriage:
+		instructions, _ = buildTriagePrompt(nil, interests)
+		tool = &triageTool
+	default:
+		return false
+	}
+	schema, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return false
+	}
+	minTokens := 1024
+	if strings.Contains(c.model, "haiku") {
+		minTokens = 2048
+	}
+	return (len(instructions)+len(schema))/4 >= minTokens
+}
+
+// completeCached is complete for a prompt that starts with instructions
+// shared by the other calls of a run, such as the interests and task of
+// every analysis batch. They're sent as a block of their own marked for
+// Anthropic's prompt cache, so calls within a few minutes of each other
+// read them (with the tool definition before them) from the cache at a
+// tenth of the input price. Prefixes shorter than the model's minimum
+// (1024 or 2048 tokens) aren't cached, and cost as usual.
+func (c *AnthropicProvider) completeCached(ctx context.Context, call, instructions, prompt string, tool *anthropic.ToolParam) (string, error) {
+	var blocks []anthropic.ContentBlockParamUnion
+	if instructions != "" {
+		blocks = append(blocks, anthropic.ContentBlockParamUnion{OfText: &anthropic.TextBlockParam{
+			Text:         instructions,
+			CacheControl: anthropic.NewCacheControlEphemeralParam(),
+		}})
+	}
+	blocks = append(blocks, anthropic.NewTextBlock(prompt))
+	params := anthropic.MessageNewParams{
 		Model:     anthropic.Model(c.model),
 		MaxTokens: 4096,
-		Messages: []anthropic.MessageParam{
-			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
-			anthropic.NewAssistantMessage(anthropic.NewTextBlock("[")),
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("failed to call Claude API: %w", err)
+		Messages:  []anthropic.MessageParam{anthropic.NewUserMessage(blocks...)},
+	}
+	if tool != nil {
+		params.Tools = []anthropic.ToolUnionParam{{OfTool: tool}}
+		params.ToolChoice = anthropic.ToolChoiceParamOfTool(tool.Name)
 	}
 
-	// Extract text from response
+	var httpResp *http.Response
+	stream := c.client.Messages.NewStreaming(ctx, params, option.WithResponseInto(&httpResp))
+	defer stream.Close()
+	if httpResp != nil {
+		c.update(httpResp.Header, "anthropic-ratelimit-")
+	}
+
+	// Put the reply together as it streams in, logging progress on long ones
+	var message anthropic.Message
+	var received int
+	var err error
+	lastProgress := time.Now()
+	for stream.Next() {
+		event := stream.Current()
+		if err = message.Accumulate(event); err != nil {
+			break
+		}
+		received += len(event.Delta.Text) + len(event.Delta.PartialJSON)
+		if time.Since(lastProgress) >= streamProgressInterval {
+			log.Printf("Receiving %s reply: %d KB so far", call, received/1024)
+			lastProgress = time.Now()
+		}
+	}
+	if err == nil {
+		err = stream.Err()
+	}
+	if err != nil && message.ID == "" {
+		var apiErr *anthropic.Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+			return "", fmt.Errorf("%w: %v", ErrRateLimited, err)
+		}
+		return "", fmt.Errorf("failed to call Claude API: %w", err)
+	}
+
+	// Extract the text, or the tool call, from the response
 	var responseText string
 	for _, block := range message.Content {
-		if block.Type == "text" {
+		if tool == nil && block.Type == "text" {
 			responseText = block.Text
 			break
 		}
+		if tool != nil && block.Type == "tool_use" && block.Name == tool.Name {
+			responseText = string(block.Input)
+			break
+		}
+	}
+
+	if err := store.RecordTokenUsage(store.TokenUsage{
+		At:               time.Now(),
+		Provider:         c.provider,
+		Model:            c.model,
+		Call:             call,
+		InputTokens:      message.Usage.InputTokens,
+		OutputTokens:     message.Usage.OutputTokens,
+		CacheWriteTokens: message.Usage.CacheCreationInputTokens,
+		CacheReadTokens:  message.Usage.CacheReadInputTokens,
+	}); err != nil {
+		log.Printf("Failed to record token usage: %v", err)
 	}
 
 	// Cache the prompt/response for debugging
 	if cachePath, err := store.SaveLLMExchange(store.LLMExchange{
-		Timestamp: time.Now(),
-		Provider:  c.provider,
-		Model:     c.model,
-		Prompt:    prompt,
-		Response:  responseText,
+		Timestamp:        time.Now(),
+		Provider:         c.provider,
+		Model:            c.model,
+		Prompt:           instructions + prompt,
+		Response:         responseText,
+		InputTokens:      message.Usage.InputTokens,
+		OutputTokens:     message.Usage.OutputTokens,
+		CacheWriteTokens: message.Usage.CacheCreationInputTokens,
+		CacheReadTokens:  message.Usage.CacheReadInputTokens,
 	}); err != nil {
 		log.Printf("Failed to cache LLM exchange: %v", err)
 	} else {
 		log.Printf("Cached LLM exchange to: %s", cachePath)
 	}
 
+	if err != nil {
+		return responseText, fmt.Errorf("%w: stream broke off: %v", ErrIncomplete, err)
+	}
+	if message.StopReason == anthropic.StopReasonMaxTokens {
+		return responseText, fmt.Errorf("%w: cut off at %d tokens", ErrIncomplete, params.MaxTokens)
+	}
 	if responseText == "" {
-		return nil, fmt.Errorf("Claude returned empty response")
+		return "", fmt.Errorf("Claude returned empty response")
 	}
+	return responseText, nil
+}
 
-	// Prepend "[" since we used prefilling - the response continues from after the "["
-	fullJSON := "[" + responseText
-	return ParseAnalysisResponse([]byte(fullJSON))
+// toolField returns the named field of a tool call's input JSON
+func toolField(input, field string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(input), &fields); err != nil {
+		return nil, fmt.Errorf("failed to parse tool input: %w (input was: %.500s)", err, input)
+	}
+	value, ok := fields[field]
+	if !ok {
+		return nil, fmt.Errorf("tool input has no %q (input was: %.500s)", field, input)
+	}
+	return value, nil
 }
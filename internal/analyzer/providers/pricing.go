@@ -0,0 +1,65 @@
+package providers
+
+import "strings"
+
+// What an LLM call was for, as logged with its token usage
+const (
+	CallAnalyze    = "analyze"
+	CallTrends     = "trends"
+	CallTopics     = "topics"
+	CallSections   = "sections"
+	CallTriage     = "triage"
+	CallDiscussion = "discussion"
+)
+
+// Price is what a model charges, in US dollars per million tokens
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Prompt cache writes and reads are billed at these multiples of the input
+// price (for the default five-minute cache)
+const (
+	cacheWriteFactor = 1.25
+	cacheReadFactor  = 0.1
+)
+
+// Cost returns the dollar cost of a call with the given token counts
+func (p Price) Cost(inputTokens, outputTokens int64) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1e6
+}
+
+// CacheCost returns the dollar cost of a call's prompt cache writes and
+// reads, which Cost leaves out
+func (p Price) CacheCost(writeTokens, readTokens int64) float64 {
+	return (float64(writeTokens)*cacheWriteFactor + float64(readTokens)*cacheReadFactor) * p.Input / 1e6
+}
+
+// modelPrices are Anthropic's list prices by model name prefix. Dated
+// snapshots (e.g. claude-sonnet-4-5-20250929) match their family's prefix.
+var modelPrices = map[string]Price{
+	"claude-opus-4-5":   {Input: 5, Output: 25},
+	"claude-opus-4":     {Input: 15, Output: 75},
+	"claude-sonnet-4":   {Input: 3, Output: 15},
+	"claude-haiku-4-5":  {Input: 1, Output: 5},
+	"claude-3-7-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-opus":     {Input: 15, Output: 75},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+}
+
+// PriceOf returns the list price of model, matching the longest known
+// prefix of its name. Reports false for unknown models, such as a
+// gateway's own aliases.
+func PriceOf(model string) (Price, bool) {
+	var price Price
+	matched := ""
+	for prefix, p := range modelPrices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(matched) {
+			price, matched = p, prefix
+		}
+	}
+	return price, matched != ""
+}
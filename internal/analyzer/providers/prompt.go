@@ -1,21 +1,51 @@
 package providers
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ibeckermayer/scroll4me/internal/article"
 	"github.com/ibeckermayer/scroll4me/internal/config"
 	"github.com/ibeckermayer/scroll4me/internal/types"
 )
 
+// How many candidate interest topics buildTopicsPrompt asks for
+const (
+	minSuggestedTopics = 5
+	maxSuggestedTopics = 10
+)
+
+// How many topic sections buildSectionsPrompt asks for
+const (
+	minTopicSections = 2
+	maxTopicSections = 7
+)
+
+// Limits on the replies shown per post in a discussion prompt
+const (
+	maxDiscussionReplies = 15
+	maxReplyChars        = 280
+)
+
+// maxTriageChars caps the text of each post in a triage prompt
+const maxTriageChars = 500
+
+// maxExampleChars caps the text of each rated post shown as an example
+const maxExampleChars = 400
+
 // AnalysisResult represents the expected JSON structure from any LLM provider
 type AnalysisResult struct {
 	PostID         string   `json:"post_id"`
 	RelevanceScore float64  `json:"relevance_score"`
 	Topics         []string `json:"topics"`
 	Summary        string   `json:"summary"`
+	EngagementBait bool     `json:"engagement_bait"`
+	Reason         string   `json:"reason"`
+	Urgency        float64  `json:"urgency"`
 }
 
 // ParseAnalysisResponse parses raw JSON bytes from an LLM provider into Analysis objects.
@@ -25,24 +55,61 @@ func ParseAnalysisResponse(jsonBytes []byte) ([]types.Analysis, error) {
 	if err := json.Unmarshal(jsonBytes, &results); err != nil {
 		return nil, fmt.Errorf("failed to parse analysis JSON: %w (response was: %.500s)", err, string(jsonBytes))
 	}
+	return toAnalyses(results), nil
+}
+
+// SalvageAnalyses recovers the complete analyses from the start of a tool
+// input JSON that was cut short, e.g. `{"analyses": [{...}, {...}, {"po`.
+// Returns nil if none are complete.
+func SalvageAnalyses(partial []byte) []types.Analysis {
+	start := bytes.IndexByte(partial, '[')
+	if start < 0 {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(partial[start:]))
+	if _, err := dec.Token(); err != nil {
+		return nil
+	}
+	var results []AnalysisResult
+	for dec.More() {
+		var r AnalysisResult
+		if err := dec.Decode(&r); err != nil {
+			break
+		}
+		results = append(results, r)
+	}
+	if len(results) == 0 {
+		return nil
+	}
+	return toAnalyses(results)
+}
 
+// toAnalyses converts parsed LLM results to analyses stamped with the
+// current time, with scores the model put out of range clamped to 0-1
+func toAnalyses(results []AnalysisResult) []types.Analysis {
 	now := time.Now()
 	analyses := make([]types.Analysis, len(results))
 	for i, r := range results {
 		analyses[i] = types.Analysis{
 			PostID:         r.PostID,
-			RelevanceScore: r.RelevanceScore,
+			RelevanceScore: min(max(r.RelevanceScore, 0), 1),
 			Topics:         r.Topics,
 			Summary:        r.Summary,
+			EngagementBait: r.EngagementBait,
+			Reason:         r.Reason,
 			AnalyzedAt:     now,
+			Urgency:        min(max(r.Urgency, 0), 1),
 		}
 	}
-
-	return analyses, nil
+	return analyses
 }
 
-// buildPrompt constructs the LLM prompt for analyzing posts
-func buildPrompt(posts []types.Post, interests config.InterestsConfig) string {
+// buildPrompt constructs the LLM prompt for analyzing posts, asking for
+// summaries and topics in language unless it's empty. Posts the user rated
+// are shown as examples of their taste. The prompt comes in two parts:
+// instructions, the same for every batch of a run, and the posts, so
+// providers can cache the instructions (see AnthropicProvider.complete).
+func buildPrompt(posts []types.Post, interests config.InterestsConfig, examples []types.RatedPost, language string) (instructions, batch string) {
 	var sb strings.Builder
 
 	sb.WriteString("You are analyzing social media posts for relevance to a user's interests.\n\n")
@@ -59,46 +126,426 @@ func buildPrompt(posts []types.Post, interests config.InterestsConfig) string {
 
 	// Specific interests if configured
 	if len(interests.Keywords) > 0 {
-		sb.WriteString(fmt.Sprintf("Keywords: %s\n", strings.Join(interests.Keywords, ", ")))
+		sb.WriteString(fmt.Sprintf("Keywords: %s\n", formatKeywords(interests.Keywords)))
 	}
 	if len(interests.PriorityAccounts) > 0 {
-		sb.WriteString(fmt.Sprintf("Priority accounts: %s\n", strings.Join(interests.PriorityAccounts, ", ")))
+		sb.WriteString(fmt.Sprintf("Priority accounts: %s\n", formatAccounts(interests.PriorityAccounts)))
 	}
+	// Posts containing a muted keyword are dropped before analysis, but the
+	// keywords are kept here on purpose: the model catches paraphrases and
+	// other near-misses the whole-word filter doesn't see
 	if len(interests.MutedKeywords) > 0 {
 		sb.WriteString(fmt.Sprintf("Muted keywords (score 0): %s\n", strings.Join(interests.MutedKeywords, ", ")))
 	}
-	if len(interests.MutedAccounts) > 0 {
-		sb.WriteString(fmt.Sprintf("Muted accounts (score 0): %s\n", strings.Join(interests.MutedAccounts, ", ")))
+
+	if len(examples) > 0 {
+		sb.WriteString("\n## Posts the User Rated\n\n")
+		sb.WriteString("The user rated these posts themselves. They show their taste better than the keywords do, so ")
+		sb.WriteString("score posts like the wanted ones high and posts like the unwanted ones low.\n\n")
+		for _, e := range examples {
+			verdict := "Not wanted"
+			if e.Good {
+				verdict = "Wanted"
+			}
+			sb.WriteString(fmt.Sprintf("- %s: @%s: %s\n", verdict, e.AuthorHandle, shorten(e.Content, maxExampleChars)))
+			if e.Note != "" {
+				sb.WriteString(fmt.Sprintf("  User's note: %s\n", e.Note))
+			}
+		}
 	}
 
-	sb.WriteString("\n## Posts to Analyze\n\n")
+	// Instructions
+	sb.WriteString("\n## Task\n\n")
+	sb.WriteString("For each post, provide:\n")
+	sb.WriteString("1. relevance_score (0.0 to 1.0): How relevant is this to the user's interests? Where an author profile is given, weigh the author's credibility on the topic.\n")
+	sb.WriteString("2. topics (array, max 3): Key topics detected\n")
+	sb.WriteString("3. summary (string): One sentence summary\n")
+	sb.WriteString("4. engagement_bait (boolean): true if the post exists mainly to farm engagement (e.g. \"wrong answers only\", rage bait, \"repost if you agree\")\n")
+	sb.WriteString("5. reason (string): Why it got that score, in one short sentence naming the interests, keywords, or mutes it matches or misses\n")
+	sb.WriteString("6. urgency (0.0 to 1.0): How time-sensitive it is for the user. Near 1 only for outages, security advisories, or breaking news within their interests that they'd want to know about right away; 0 for anything that can wait for the next digest\n\n")
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("Write every summary, topic, and reason in %s, whatever language the post is written in.\n\n", language))
+	}
+
+	instructions = sb.String()
+	sb.Reset()
+
+	sb.WriteString("## Posts to Analyze\n\n")
 
 	// Posts
 	for i, p := range posts {
 		sb.WriteString(fmt.Sprintf("### Post %d (ID: %s)\n", i+1, p.ID))
-		sb.WriteString(fmt.Sprintf("Author: @%s (%s)\n", p.AuthorHandle, p.AuthorName))
+		sb.WriteString(fmt.Sprintf("Author: @%s (%s)", p.AuthorHandle, p.AuthorName))
+		if p.AuthorVerified || (p.AuthorProfile != nil && p.AuthorProfile.Verified) {
+			sb.WriteString(" [verified]")
+		}
+		sb.WriteString("\n")
+		if ap := p.AuthorProfile; ap != nil {
+			sb.WriteString(fmt.Sprintf("Author profile: %d followers", ap.Followers))
+			if ap.Bio != "" {
+				sb.WriteString("; bio: " + strings.Join(strings.Fields(ap.Bio), " "))
+			}
+			sb.WriteString("\n")
+		}
+		if len(p.ThreadParts) > 1 {
+			sb.WriteString(fmt.Sprintf("Thread: %d posts by the author, combined below\n", len(p.ThreadParts)))
+		}
+		if r := p.ReplyTo; r != nil {
+			sb.WriteString(fmt.Sprintf("In reply to @%s: %s\n", r.AuthorHandle, r.Content))
+		}
 		sb.WriteString(fmt.Sprintf("Content: %s\n", p.Content))
-		sb.WriteString(fmt.Sprintf("Engagement: %d likes, %d retweets, %d replies\n", p.Likes, p.Retweets, p.Replies))
+		if p.Edited {
+			sb.WriteString(fmt.Sprintf("Edited since first seen; originally: %s\n", p.FirstSeenContent))
+		}
+		for _, alt := range p.MediaAltText {
+			sb.WriteString(fmt.Sprintf("Image: %s\n", alt))
+		}
+		sb.WriteString(fmt.Sprintf("Engagement: %d likes, %d retweets, %d replies", p.Likes, p.Retweets, p.Replies))
+		if p.QuoteTweets > 0 {
+			sb.WriteString(fmt.Sprintf(", %d quotes", p.QuoteTweets))
+		}
+		if p.Views > 0 {
+			sb.WriteString(fmt.Sprintf(", %d views (%.2f%% like rate)", p.Views, p.LikeRate()*100))
+		}
+		sb.WriteString("\n")
+		if c := p.Card; c != nil {
+			sb.WriteString(fmt.Sprintf("Link: %s (%s)", c.Title, c.Domain))
+			if c.Description != "" {
+				sb.WriteString(" - " + c.Description)
+			}
+			sb.WriteString("\n")
+			if c.Excerpt != "" {
+				sb.WriteString(fmt.Sprintf("Linked article excerpt: %s\n", c.Excerpt))
+			}
+		}
+		if p.Poll != nil {
+			sb.WriteString(fmt.Sprintf("Poll: %s\n", formatPoll(p.Poll)))
+		}
 		if p.IsRetweet {
 			sb.WriteString("Type: Retweet\n")
 		}
+		if n := len(p.RetweetedBy); n > 1 {
+			sb.WriteString(fmt.Sprintf("Reposted by %d accounts in the user's timelines\n", n))
+		}
+		if n := len(p.AlsoCoveredBy); n > 0 {
+			sb.WriteString(fmt.Sprintf("Posted near-identically by %d other accounts\n", n))
+		}
 		if p.IsQuoteTweet {
 			sb.WriteString("Type: Quote Tweet\n")
+			if q := p.QuotedPost; q != nil {
+				sb.WriteString(fmt.Sprintf("Quoting @%s: %s\n", q.AuthorHandle, q.Content))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if slices.ContainsFunc(posts, func(p types.Post) bool { return p.Card != nil && p.Card.Excerpt != "" }) {
+		sb.WriteString("Where a linked article excerpt is given, judge and summarize the post by what the article says, especially when the post itself is little more than the link (\"great thread on this 👇\").\n\n")
+	}
+	sb.WriteString("Give a result for every post, identified by its post_id.\n")
+
+	return instructions, sb.String()
+}
+
+// buildTrendsPrompt constructs the LLM prompt for summarizing what's
+// trending on X, asking for the note in language unless it's empty
+func buildTrendsPrompt(trends []types.Trend, interests config.InterestsConfig, language string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are writing a short \"what's happening\" note for the top of a user's daily digest of X posts.\n\n")
+
+	sb.WriteString("## User Interests\n")
+	if interests.CustomInstructions != "" {
+		sb.WriteString(interests.CustomInstructions + "\n")
+	} else {
+		sb.WriteString("User specified no particular interests.\n")
+	}
+	if len(interests.Keywords) > 0 {
+		sb.WriteString(fmt.Sprintf("Keywords: %s\n", formatKeywords(interests.Keywords)))
+	}
+
+	sb.WriteString("\n## Trending on X\n\n")
+	for _, t := range trends {
+		sb.WriteString(fmt.Sprintf("%d. %s", t.Rank, t.Name))
+		var details []string
+		if t.Category != "" {
+			details = append(details, t.Category)
+		}
+		if t.PostCount > 0 {
+			details = append(details, fmt.Sprintf("%d posts", t.PostCount))
+		}
+		if len(details) > 0 {
+			sb.WriteString(" (" + strings.Join(details, ", ") + ")")
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n## Task\n\n")
+	sb.WriteString("In 2-4 sentences, say what the trends are about and what connects them, mentioning any that touch the user's interests first. ")
+	sb.WriteString("Don't speculate beyond what the trend names make clear; if a trend is ambiguous, leave it out.\n\n")
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("Write the note in %s, whatever language the trends are in.\n\n", language))
+	}
+	sb.WriteString("Respond with ONLY the plain text of the note. No markdown headings, no lists, no preamble.\n")
+
+	return sb.String()
+}
+
+// buildTopicsPrompt constructs the LLM prompt for finding candidate
+// interest topics in a feed, for users who haven't set any. Descriptions
+// are asked for in language unless it's empty; keywords stay in the posts'
+// language so they match post text.
+func buildTopicsPrompt(posts []types.Post, language string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are helping a new user of a daily digest of X posts say what they're interested in. ")
+	sb.WriteString("They haven't configured any interests yet, so find them in the posts their own feed shows them.\n\n")
+
+	sb.WriteString("## Posts\n\n")
+	for _, p := range posts {
+		sb.WriteString(fmt.Sprintf("@%s: %s\n", p.AuthorHandle, p.Content))
+		if q := p.QuotedPost; q != nil {
+			sb.WriteString(fmt.Sprintf("  Quoting @%s: %s\n", q.AuthorHandle, q.Content))
+		}
+	}
+
+	sb.WriteString("\n## Task\n\n")
+	sb.WriteString(fmt.Sprintf("Group the posts by subject and name the %d-%d subjects that come up most. ", minSuggestedTopics, maxSuggestedTopics))
+	sb.WriteString("Prefer specific subjects (\"Go programming\", \"Formula 1\") over broad ones (\"tech\", \"sports\"), and leave out ads, memes, and one-off posts. For each, provide:\n")
+	sb.WriteString("1. keyword (string): 1-3 words a post about it would likely contain, usable as an interest keyword\n")
+	sb.WriteString("2. description (string): One short sentence on what the posts about it discuss\n")
+	sb.WriteString("3. post_count (integer): How many of the posts are about it\n\n")
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("Write each description in %s. Keep each keyword in the language the posts use, since it's matched against their text.\n\n", language))
+	}
+
+	sb.WriteString("List the most common subject first.\n")
+
+	return sb.String()
+}
+
+// buildTriagePrompt constructs the LLM prompt for picking out the posts
+// that could be relevant to the user's interests, for a full analysis. Like
+// buildPrompt, it returns the instructions apart from the batch of posts.
+func buildTriagePrompt(posts []types.Post, interests config.InterestsConfig) (instructions, batch string) {
+	var sb strings.Builder
+
+	sb.WriteString("You are screening social media posts for a user's daily digest. ")
+	sb.WriteString("A stronger model will analyze the posts you pick; the rest are dropped.\n\n")
+
+	sb.WriteString("## User's Interests\n")
+	if interests.CustomInstructions != "" {
+		sb.WriteString(interests.CustomInstructions + "\n")
+	}
+	if len(interests.Keywords) > 0 {
+		sb.WriteString(fmt.Sprintf("Keywords: %s\n", formatKeywords(interests.Keywords)))
+	}
+	if len(interests.PriorityAccounts) > 0 {
+		sb.WriteString(fmt.Sprintf("Priority accounts: %s\n", formatAccounts(interests.PriorityAccounts)))
+	}
+	// Kept for near-misses of the muted keyword filter, as in buildPrompt
+	if len(interests.MutedKeywords) > 0 {
+		sb.WriteString(fmt.Sprintf("Not interested in: %s\n", strings.Join(interests.MutedKeywords, ", ")))
+	}
+
+	sb.WriteString("\n## Task\n\n")
+	sb.WriteString("List the IDs of the posts that could be relevant to the user's interests. ")
+	sb.WriteString("When unsure, include the post: a missed post is worse than an extra one.\n")
+	instructions = sb.String()
+	sb.Reset()
+
+	sb.WriteString("## Posts\n\n")
+	for _, p := range posts {
+		sb.WriteString(fmt.Sprintf("[%s] @%s: %s\n", p.ID, p.AuthorHandle, shorten(p.Content, maxTriageChars)))
+		if q := p.QuotedPost; q != nil {
+			sb.WriteString(fmt.Sprintf("  Quoting @%s: %s\n", q.AuthorHandle, shorten(q.Content, maxTriageChars)))
+		}
+		if c := p.Card; c != nil && c.Title != "" {
+			sb.WriteString(fmt.Sprintf("  Link: %s (%s)\n", c.Title, c.Domain))
+		}
+	}
+	return instructions, sb.String()
+}
+
+// buildSectionsPrompt constructs the LLM prompt for grouping a digest's
+// posts into topic sections, named in language unless it's empty
+func buildSectionsPrompt(posts []types.PostWithAnalysis, language string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are organizing a daily digest of X posts into sections a reader can skim by subject.\n\n")
+
+	sb.WriteString("## Posts\n\n")
+	for _, p := range posts {
+		sb.WriteString(fmt.Sprintf("### Post %s by @%s\n", p.Post.ID, p.Post.AuthorHandle))
+		if a := p.Analysis; a != nil {
+			sb.WriteString(fmt.Sprintf("Summary: %s\n", a.Summary))
+			if len(a.Topics) > 0 {
+				sb.WriteString(fmt.Sprintf("Topics: %s\n", strings.Join(a.Topics, ", ")))
+			}
+		} else {
+			sb.WriteString(fmt.Sprintf("Content: %s\n", p.Post.Content))
 		}
 		sb.WriteString("\n")
 	}
 
-	// Instructions
 	sb.WriteString("## Task\n\n")
-	sb.WriteString("For each post, provide:\n")
-	sb.WriteString("1. relevance_score (0.0 to 1.0): How relevant is this to the user's interests?\n")
-	sb.WriteString("2. topics (array, max 3): Key topics detected\n")
-	sb.WriteString("3. summary (string): One sentence summary\n\n")
+	sb.WriteString(fmt.Sprintf("Group the posts into %d-%d sections by subject. ", minTopicSections, maxTopicSections))
+	sb.WriteString("Name each section in 1-3 words, broad enough to hold several posts (\"AI\", \"Politics\", \"Sports\"). ")
+	sb.WriteString("Put every post in exactly one section, and posts that fit no other section in one named \"Other\". For each section, provide:\n")
+	sb.WriteString("1. name (string): The section's name\n")
+	sb.WriteString("2. post_ids (array of strings): The IDs of its posts\n\n")
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("Write each name in %s.\n\n", language))
+	}
+
+	return sb.String()
+}
+
+// buildDiscussionPrompt constructs the LLM prompt for rewriting the
+// summaries of posts to take in the replies to them (Post.Discussion),
+// asking for the summaries in language unless it's empty
+func buildDiscussionPrompt(posts []types.PostWithAnalysis, language string) string {
+	var sb strings.Builder
+
+	sb.WriteString("You are summarizing posts for a user's daily digest of X posts, taking in what the replies to each post add.\n\n")
+
+	sb.WriteString("## Posts\n\n")
+	for _, p := range posts {
+		sb.WriteString(fmt.Sprintf("### Post %s by @%s\n", p.Post.ID, p.Post.AuthorHandle))
+		sb.WriteString(fmt.Sprintf("Content: %s\n", p.Post.Content))
+		if p.Analysis != nil {
+			sb.WriteString(fmt.Sprintf("Current summary: %s\n", p.Analysis.Summary))
+		}
+		sb.WriteString("Replies:\n")
+		for _, r := range p.Post.Discussion[:min(len(p.Post.Discussion), maxDiscussionReplies)] {
+			sb.WriteString(fmt.Sprintf("- @%s (%d likes): %s\n", r.AuthorHandle, r.Likes, shorten(r.Content, maxReplyChars)))
+		}
+		sb.WriteString("\n")
+	}
 
-	sb.WriteString("IMPORTANT: Respond with ONLY a valid JSON array. No markdown, no code blocks, no explanation - just the raw JSON starting with [ and ending with ].\n\n")
-	sb.WriteString("Example structure:\n")
-	sb.WriteString(`[{"post_id": "...", "relevance_score": 0.85, "topics": ["AI", "tech"], "summary": "Discussion about..."}]`)
-	sb.WriteString("\n")
+	sb.WriteString("## Task\n\n")
+	sb.WriteString("For each post, rewrite its summary in one or two sentences that say what the post claims and what the discussion adds: ")
+	sb.WriteString("corrections, pushback, missing context, or broad agreement (e.g. \"replies point out the benchmark is flawed\"). ")
+	sb.WriteString("Weigh replies by their substance and likes, not their number, and ignore jokes and spam. ")
+	sb.WriteString("If the replies add nothing of note, give the current summary unchanged. For each post, provide:\n")
+	sb.WriteString("1. post_id (string): The post's ID\n")
+	sb.WriteString("2. summary (string): The rewritten summary\n\n")
+	if language != "" {
+		sb.WriteString(fmt.Sprintf("Write every summary in %s.\n\n", language))
+	}
 
 	return sb.String()
 }
+
+// parseDiscussionResponse parses the JSON array of summaries replied to
+// buildDiscussionPrompt into summaries by post ID, dropping empty ones
+func parseDiscussionResponse(jsonBytes []byte) (map[string]string, error) {
+	var results []struct {
+		PostID  string `json:"post_id"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal(jsonBytes, &results); err != nil {
+		return nil, fmt.Errorf("failed to parse summaries JSON: %w (response was: %.500s)", err, string(jsonBytes))
+	}
+	summaries := make(map[string]string, len(results))
+	for _, r := range results {
+		if summary := strings.TrimSpace(r.Summary); summary != "" {
+			summaries[r.PostID] = summary
+		}
+	}
+	return summaries, nil
+}
+
+// parseSectionsResponse parses the JSON array of sections replied to
+// buildSectionsPrompt, dropping unnamed and empty ones
+func parseSectionsResponse(jsonBytes []byte) ([]types.TopicSection, error) {
+	var sections []types.TopicSection
+	if err := json.Unmarshal(jsonBytes, &sections); err != nil {
+		return nil, fmt.Errorf("failed to parse sections JSON: %w (response was: %.500s)", err, string(jsonBytes))
+	}
+	var kept []types.TopicSection
+	for _, s := range sections {
+		if s.Name = strings.TrimSpace(s.Name); s.Name != "" && len(s.PostIDs) > 0 {
+			kept = append(kept, s)
+		}
+	}
+	return kept, nil
+}
+
+// parseTopicsResponse parses the JSON array of topics replied to
+// buildTopicsPrompt, dropping entries without a keyword
+func parseTopicsResponse(jsonBytes []byte) ([]types.InterestTopic, error) {
+	var topics []types.InterestTopic
+	if err := json.Unmarshal(jsonBytes, &topics); err != nil {
+		return nil, fmt.Errorf("failed to parse topics JSON: %w (response was: %.500s)", err, string(jsonBytes))
+	}
+	var kept []types.InterestTopic
+	for _, t := range topics {
+		if t.Keyword = strings.TrimSpace(t.Keyword); t.Keyword != "" {
+			kept = append(kept, t)
+		}
+	}
+	return kept, nil
+}
+
+// formatKeywords lists keywords, noting the scoring rules of any that matter
+// more or less than usual, e.g. "AI, golang (weight 2: matters more)"
+func formatKeywords(keywords []config.Keyword) string {
+	parts := make([]string, len(keywords))
+	for i, k := range keywords {
+		parts[i] = formatRules(k.Keyword, k.EffectiveWeight(), k.MinScore)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatAccounts lists priority accounts the way formatKeywords lists
+// keywords
+func formatAccounts(accounts []config.PriorityAccount) string {
+	parts := make([]string, len(accounts))
+	for i, a := range accounts {
+		parts[i] = formatRules(a.Handle, a.EffectiveWeight(), a.MinScore)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatRules notes a keyword's or account's weight and minimum score, if
+// set, e.g. "golang (weight 2: matters more; score at least 0.8)"
+func formatRules(name string, weight, minScore float64) string {
+	var notes []string
+	switch {
+	case weight > 1:
+		notes = append(notes, fmt.Sprintf("weight %g: matters more", weight))
+	case weight < 1:
+		notes = append(notes, fmt.Sprintf("weight %g: matters less", weight))
+	}
+	if minScore > 0 {
+		notes = append(notes, fmt.Sprintf("score at least %g", minScore))
+	}
+	if len(notes) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(notes, "; "))
+}
+
+// shorten puts text on one line and cuts it to at most n bytes at a word
+// boundary, marking the cut with an ellipsis
+func shorten(text string, n int) string {
+	return article.TruncateWords(strings.Join(strings.Fields(text), " "), n)
+}
+
+// formatPoll renders a poll on one line, e.g. "Yes (62%), No (38%) - 1204 votes, closed"
+func formatPoll(poll *types.Poll) string {
+	choices := make([]string, len(poll.Choices))
+	for i, c := range poll.Choices {
+		if c.Percent > 0 {
+			choices[i] = fmt.Sprintf("%s (%.0f%%)", c.Label, c.Percent)
+		} else {
+			choices[i] = c.Label
+		}
+	}
+	state := "open"
+	if poll.Closed {
+		state = "closed"
+	}
+	return fmt.Sprintf("%s - %d votes, %s", strings.Join(choices, ", "), poll.TotalVotes, state)
+}
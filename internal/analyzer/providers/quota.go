@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// ErrRateLimited is returned when the provider rejects a request because
+// the account's rate limit or quota is exhausted.
+var ErrRateLimited = errors.New("LLM provider rate limit reached")
+
+// lowQuotaFraction is how much of a rate limit must remain before the
+// quota is considered nearly exhausted
+const lowQuotaFraction = 0.1
+
+// Quota is a snapshot of the rate-limit headers from the latest response
+type Quota struct {
+	RequestsLimit     int
+	RequestsRemaining int
+	TokensLimit       int
+	TokensRemaining   int
+}
+
+// Low reports whether either the request or token budget is nearly used up
+func (q Quota) Low() bool {
+	low := func(remaining, limit int) bool {
+		return limit > 0 && float64(remaining) < float64(limit)*lowQuotaFraction
+	}
+	return low(q.RequestsRemaining, q.RequestsLimit) || low(q.TokensRemaining, q.TokensLimit)
+}
+
+// quotaTracker records the most recent quota seen across concurrent calls
+type quotaTracker struct {
+	mu    sync.Mutex
+	quota Quota
+	known bool
+}
+
+// update parses rate-limit headers with the given prefix,
+// e.g. "anthropic-ratelimit-"
+func (t *quotaTracker) update(h http.Header, prefix string) {
+	header := func(name string) (int, bool) {
+		n, err := strconv.Atoi(h.Get(prefix + name))
+		return n, err == nil
+	}
+
+	var q Quota
+	var ok bool
+	if q.RequestsLimit, ok = header("requests-limit"); !ok {
+		return
+	}
+	q.RequestsRemaining, _ = header("requests-remaining")
+	q.TokensLimit, _ = header("tokens-limit")
+	q.TokensRemaining, _ = header("tokens-remaining")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.quota = q
+	t.known = true
+}
+
+// Quota returns the latest quota snapshot, if any response has reported one
+func (t *quotaTracker) Quota() (Quota, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.quota, t.known
+}
@@ -1,30 +1,151 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"maps"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/chromedp/cdproto/network"
 	"github.com/pkg/browser"
 
 	"github.com/ibeckermayer/scroll4me/internal/analyzer"
+	"github.com/ibeckermayer/scroll4me/internal/article"
 	"github.com/ibeckermayer/scroll4me/internal/auth"
+	browseropts "github.com/ibeckermayer/scroll4me/internal/browser"
 	"github.com/ibeckermayer/scroll4me/internal/config"
 	"github.com/ibeckermayer/scroll4me/internal/digest"
+	"github.com/ibeckermayer/scroll4me/internal/insights"
+	"github.com/ibeckermayer/scroll4me/internal/media"
+	"github.com/ibeckermayer/scroll4me/internal/ranking"
+	"github.com/ibeckermayer/scroll4me/internal/remotesync"
 	"github.com/ibeckermayer/scroll4me/internal/scraper"
 	"github.com/ibeckermayer/scroll4me/internal/store"
 	"github.com/ibeckermayer/scroll4me/internal/types"
 )
 
+// ErrConfigInvalid is returned by ReloadConfig when the config on disk can't
+// be parsed or fails validation. The current config stays in effect.
+var ErrConfigInvalid = errors.New("config not reloaded, keeping the current one")
+
+// syncTimeout bounds each remote sync upload
+const syncTimeout = time.Minute
+
+// outboxCheckInterval is how often queued deliveries are retried
+const outboxCheckInterval = time.Minute
+
+// maxDeliveryAttempts is how many times a delivery is tried while its
+// target is reachable before it's given up on
+const maxDeliveryAttempts = 10
+
+// Author affinity tuning
+const (
+	affinityLearningRate    = 0.2 // Weight of each new observation in the moving average
+	minAffinityObservations = 3   // Posts seen before an author's affinity affects ranking
+)
+
+// topicMemoryMaxAge is how long a digest's posts count against later posts
+// on the same subject
+const topicMemoryMaxAge = 14 * 24 * time.Hour
+
+// Guest mode limits
+const (
+	guestMaxPosts       = 20               // Posts read per page
+	guestScrapeInterval = 15 * time.Second // Minimum pause between page loads (up to double, randomized)
+)
+
+// graphPageFile is the graph view page, rewritten in the cache directory on
+// each view
+const graphPageFile = "graph.html"
+
+// habitsReportInterval is how often digests include the reading habits
+// section, and the period it covers
+const habitsReportInterval = 30 * 24 * time.Hour
+
+// unreadRollupAge is how old a digest that was never opened has to be
+// before the next digest lists it as unread
+const unreadRollupAge = 24 * time.Hour
+
+// Reduced-scope retry of a failed full run
+const (
+	reducedRunPostsDivisor  = 3 // posts_per_scrape is divided by this
+	reducedRunTimeoutFactor = 2 // Scrape timeouts are multiplied by this
+)
+
+// Focus digest sources
+const (
+	focusFreshness   = 6 * time.Hour // Posts analyzed within this are used instead of scraping
+	focusScrapePosts = 20            // Feed posts read when there are none
+)
+
+// Author profile enrichment limits
+const (
+	authorProfileMaxAge      = 7 * 24 * time.Hour // Profiles older than this are fetched again
+	maxAuthorProfilesFetched = 10                 // Profile pages loaded per run
+)
+
+// postHistoryMaxAge is how long a post's text history is kept after it was
+// last scraped, for spotting edits
+const postHistoryMaxAge = 30 * 24 * time.Hour
+
+// maxThreadUnrolls caps how many conversation pages are loaded per scrape
+const maxThreadUnrolls = 10
+
+// maxReplyParentFetches caps how many conversation pages are loaded per
+// scrape to find the posts replies answer
+const maxReplyParentFetches = 10
+
+// Discussion summary limits: conversation pages loaded per run unless
+// analysis.max_discussion_fetches says otherwise, for digest posts with at
+// least minDiscussionReplies replies
+const (
+	defaultDiscussionFetches = 5
+	minDiscussionReplies     = 10
+)
+
+// Media download limits
+const (
+	mediaFetchTimeout     = 30 * time.Second
+	mediaFetchConcurrency = 4
+)
+
+// Linked article fetching limits
+const (
+	articleFetchTimeout     = 15 * time.Second
+	articleFetchConcurrency = 4
+	articleExcerptChars     = 1500
+)
+
 // App holds the application state.
 type App struct {
 	mu          sync.RWMutex
 	authManager *auth.Manager // immutable after creation
+	outboxMu    sync.Mutex    // serializes reads and writes of the delivery outbox
+	runs        pipelineRuns  // runs in progress, for CancelPipeline
 
 	// Mutable fields - use getSnapshot() for concurrent access.
 	config   *config.Config
 	scraper  *scraper.Scraper
 	analyzer *analyzer.Analyzer
+	breaks   *breakpoints // Where full runs pause, or nil
+	profiles []string     // Interest profiles full runs use instead of [interests], if any
+	// Snapshot directory scrapes replay from, kept across ReloadConfig, if
+	// UseReplay was called
+	replayDir string
 }
 
 // snapshot holds fields that may be replaced by ReloadConfig.
@@ -33,6 +154,10 @@ type snapshot struct {
 	config   *config.Config
 	scraper  *scraper.Scraper
 	analyzer *analyzer.Analyzer
+	budget   *runBudget   // Time budget of the run, or nil
+	breaks   *breakpoints // Where the run pauses, or nil
+	profiles []string     // Interest profiles a full run uses, if any
+	profile  string       // Interest profile config and analyzer apply, if any
 }
 
 // getSnapshot returns a snapshot of mutable fields under read lock.
@@ -43,11 +168,14 @@ func (a *App) getSnapshot() snapshot {
 		config:   a.config,
 		scraper:  a.scraper,
 		analyzer: a.analyzer,
+		breaks:   a.breaks,
+		profiles: a.profiles,
 	}
 }
 
 // New creates a new App instance.
 func New(cfg *config.Config, authManager *auth.Manager, sc *scraper.Scraper, an *analyzer.Analyzer) *App {
+	recordConfigChanges(cfg)
 	return &App{
 		config:      cfg,
 		authManager: authManager,
@@ -63,9 +191,90 @@ func (a *App) Config() *config.Config {
 	return a.config
 }
 
-// IsAuthenticated checks if X.com credentials are stored.
+// UseRemoteBrowser makes scrapes drive the Chrome at the given DevTools URL
+// instead of launching one, until the next ReloadConfig.
+func (a *App) UseRemoteBrowser(url string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scraper = a.scraper.WithRemoteBrowser(url, false)
+}
+
+// UseReplay switches the scraper to replaying saved HTML snapshots instead
+// of contacting X, for the life of the app. See scraper.WithReplay. Since a
+// replay isn't a real scrape, everything the run caches (step output, post
+// versions, learned topics and affinity, the outbox, usage) goes to a fresh
+// temporary directory from then on, so it can't pollute the user's state.
+func (a *App) UseReplay() error {
+	snapshots, err := store.StepDir(store.Step1HTML)
+	if err != nil {
+		return err
+	}
+	dir, err := os.MkdirTemp("", "scroll4me-replay-")
+	if err != nil {
+		return fmt.Errorf("failed to create replay cache: %w", err)
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.scraper = a.scraper.WithReplay(snapshots)
+	a.replayDir = snapshots
+	config.UseCacheDir(dir)
+	log.Printf("Replaying snapshots from %s, caching this run in %s", snapshots, dir)
+	return nil
+}
+
+// UseProfiles makes full runs analyze their scrape against each of the
+// named interest profiles instead of [interests], building a digest per
+// profile, for the life of the app. No names restores [interests].
+func (a *App) UseProfiles(names []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var profiles []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := a.config.Profiles[name]; !ok {
+			return fmt.Errorf("unknown interest profile %q (configure it under [profiles.%s])", name, name)
+		}
+		profiles = append(profiles, name)
+	}
+	a.profiles = profiles
+	return nil
+}
+
+// withProfile returns a copy of s that analyzes and filters posts against
+// the named interest profile
+func withProfile(s snapshot, name string) (snapshot, error) {
+	cfg, err := s.config.WithProfile(name)
+	if err != nil {
+		return snapshot{}, err
+	}
+	an, err := analyzer.New(cfg.Analysis, cfg.Interests)
+	if err != nil {
+		return snapshot{}, fmt.Errorf("failed to initialize analyzer for profile %q: %w", name, err)
+	}
+	s.config, s.analyzer, s.profile = cfg, an, name
+	return s, nil
+}
+
+// IsAuthenticated checks if X.com credentials are stored, or if scrapes
+// use a remote browser's own session or don't need one (replay).
 func (a *App) IsAuthenticated() bool {
-	return a.authManager.IsAuthenticated()
+	s := a.getSnapshot()
+	return a.authManager.IsAuthenticated() || s.config.Scraping.RemoteDebuggingURL != "" || s.scraper.Replaying()
+}
+
+// sessionCookies returns the stored X session cookies. Scraping through a
+// remote browser with its own session, or replaying snapshots, needs none,
+// so then an empty set is returned instead of an error (not nil, which
+// would mean guest mode).
+func (a *App) sessionCookies(s snapshot) ([]*network.Cookie, error) {
+	cookies, err := a.authManager.GetCookies()
+	if err != nil && (s.config.Scraping.RemoteDebuggingURL != "" || s.scraper.Replaying()) {
+		return []*network.Cookie{}, nil
+	}
+	return cookies, err
 }
 
 // TriggerLogin starts the X.com login flow.
@@ -95,210 +304,2342 @@ func (a *App) TriggerLogout() error {
 // Pipeline Step Methods
 // =============================================================================
 
-// ScrapeForYou performs Step 1: Scrape posts from the X "For You" feed.
+// ScrapePosts performs Step 1: Scrape posts from the configured X feed
+// ("For You" by default, or "Following") and any configured Lists,
+// Communities, searches, and mentions.
 // Logs progress and caches output to step1_posts.
-func (a *App) ScrapeForYou(ctx context.Context) ([]types.Post, error) {
-	cookies, err := a.authManager.GetCookies()
+func (a *App) ScrapePosts(ctx context.Context) ([]types.Post, error) {
+	return a.scrapePosts(ctx, a.getSnapshot())
+}
+
+// scrapePosts implements ScrapePosts with an explicit snapshot.
+func (a *App) scrapePosts(ctx context.Context, s snapshot) ([]types.Post, error) {
+	cookies, err := a.sessionCookies(s)
 	if err != nil {
+		if s.config.Scraping.GuestFallback {
+			log.Printf("No X session (%v) - falling back to guest mode", err)
+			return a.scrapeAsGuest(ctx, s)
+		}
 		return nil, err
 	}
 
-	s := a.getSnapshot()
+	count := s.config.Scraping.PostsPerScrape
+
+	// Timelines are scraped incrementally, stopping at posts from the last run
+	timelines := s.scraper
+	if n := s.config.Scraping.StopAfterKnownPosts; n > 0 {
+		if known := lastScrapedIDs(); len(known) > 0 {
+			timelines = s.scraper.WithKnownPosts(known, n)
+		}
+	}
 
-	log.Printf("Scraping %d posts from For You feed...", s.config.Scraping.PostsPerScrape)
-	posts, err := s.scraper.ScrapeForYou(ctx, cookies, s.config.Scraping.PostsPerScrape)
+	var posts []types.Post
+	switch s.config.Scraping.Feed {
+	case config.FeedFollowing:
+		log.Printf("Scraping %d posts from Following feed...", count)
+		posts, err = timelines.ScrapeFollowing(ctx, cookies, count)
+	case config.FeedForYou, "":
+		log.Printf("Scraping %d posts from For You feed...", count)
+		posts, err = timelines.ScrapeForYou(ctx, cookies, count)
+	case config.FeedNone:
+		log.Println("Home feed scraping disabled")
+	default:
+		return nil, fmt.Errorf("unknown feed: %s (use %q, %q, or %q)",
+			s.config.Scraping.Feed, config.FeedForYou, config.FeedFollowing, config.FeedNone)
+	}
+	if errors.Is(err, scraper.ErrSessionInvalid) && s.config.Scraping.GuestFallback {
+		log.Println("X session is invalid - falling back to guest mode")
+		return a.scrapeAsGuest(ctx, s)
+	}
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Scraped %d posts", len(posts))
 
-	// Cache output
-	if cachePath, err := store.SaveStepOutput(store.Step1Posts, posts); err != nil {
-		log.Printf("Failed to cache posts: %v", err)
-	} else {
-		log.Printf("Cached posts to: %s", cachePath)
+	// Lists, communities, profiles, searches, and mentions are supplementary sources - a failing one shouldn't lose the rest
+	for _, source := range timelineSources(s.config.Scraping) {
+		for _, name := range source.names {
+			label := fmt.Sprintf(source.label, name)
+			log.Printf("Scraping %d posts from %s...", count, label)
+			more, err := source.scrape(timelines, ctx, cookies, name, count)
+			if err != nil {
+				// A timed out scrape still returns the posts it collected
+				log.Printf("Failed to scrape %s: %v", label, err)
+			}
+			posts = mergePosts(posts, more)
+		}
+	}
+	if s.config.Scraping.IncludeMentions {
+		log.Printf("Scraping %d posts from mentions...", count)
+		mentions, err := timelines.ScrapeMentions(ctx, cookies, count)
+		if err != nil {
+			log.Printf("Failed to scrape mentions: %v", err)
+		}
+		posts = mergePosts(posts, mentions)
 	}
 
-	return posts, nil
-}
+	// A cancelled run leaves its checkpoints and deferred posts for the next
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-// AnalyzePosts performs Step 2: Analyze posts with LLM for relevance scoring.
-// Logs progress and caches output to step2_analyses.
-func (a *App) AnalyzePosts(ctx context.Context, posts []types.Post) ([]types.Analysis, error) {
-	log.Println("Analyzing posts with LLM...")
+	posts = salvageCheckpoints(posts)
+	if !s.config.Scraping.IncludePromoted {
+		posts = dropPromoted(posts)
+	}
+	if langs := s.config.Scraping.Languages; len(langs) > 0 {
+		posts = dropOtherLanguages(posts, langs)
+	}
+	if s.config.Scraping.CollapseNearDuplicates {
+		posts = collapseNearDuplicates(posts)
+	}
+	detectEdits(posts)
+	if s.config.Scraping.UnrollThreads {
+		posts = unrollThreads(ctx, s, cookies, posts)
+	}
+	if s.config.Scraping.FetchReplyParents {
+		fetchReplyParents(ctx, s, cookies, posts)
+	}
 
-	s := a.getSnapshot()
-	analyses, err := s.analyzer.AnalyzePosts(ctx, posts)
+	// Pick up posts whose analysis was deferred by an earlier run. They're
+	// cleared once analyzed, so a failed run leaves them for the next one.
+	deferred, err := store.LoadDeferredPosts()
 	if err != nil {
-		return nil, err
+		log.Printf("Failed to load deferred posts: %v", err)
+	} else if len(deferred) > 0 {
+		log.Printf("Adding %d posts deferred from an earlier run", len(deferred))
+		posts = mergePosts(posts, deferred)
 	}
-	log.Printf("Analyzed %d posts", len(analyses))
 
-	// Cache output
-	if cachePath, err := store.SaveStepOutput(store.Step2Analyses, analyses); err != nil {
-		log.Printf("Failed to cache analyses: %v", err)
-	} else {
-		log.Printf("Cached analyses to: %s", cachePath)
-	}
+	log.Printf("Scraped %d posts", len(posts))
 
-	return analyses, nil
+	cacheScrapedPosts(posts)
+	return posts, nil
 }
 
-// FilterByRelevance performs Step 3: Filter posts by relevance threshold.
-// Logs progress and caches output to step3_filtered.
-func (a *App) FilterByRelevance(posts []types.Post, analyses []types.Analysis) []types.PostWithAnalysis {
-	s := a.getSnapshot()
+// timelineSource is one kind of supplementary timeline, such as lists, with
+// the ones configured and the scrape that reads one
+type timelineSource struct {
+	label  string // Names one in logs, e.g. "list %s"
+	names  []string
+	scrape func(s *scraper.Scraper, ctx context.Context, cookies []*network.Cookie, name string, count int) ([]types.Post, error)
+	public bool // Whether X shows them logged out, for guest mode
+}
 
-	analysisMap := make(map[string]*types.Analysis)
-	for i := range analyses {
-		analysisMap[analyses[i].PostID] = &analyses[i]
+// timelineSources returns the supplementary timelines of cfg, in the order
+// they're scraped
+func timelineSources(cfg config.ScrapingConfig) []timelineSource {
+	return []timelineSource{
+		{"list %s", cfg.Lists, (*scraper.Scraper).ScrapeList, true},
+		{"community %s", cfg.Communities, (*scraper.Scraper).ScrapeCommunity, false},
+		{"profile %s", cfg.Profiles, (*scraper.Scraper).ScrapeProfile, true},
+		{"search %q", cfg.Searches, (*scraper.Scraper).ScrapeSearch, false},
 	}
+}
 
-	var relevantPosts []types.PostWithAnalysis
-	for _, post := range posts {
-		analysis, ok := analysisMap[post.ID]
-		if !ok {
+// scrapeAsGuest is the degraded Step 1 used without a valid session: it
+// scrapes the configured Lists and Profiles logged out, which works only for
+// public ones. Fewer posts are read per page and each page load is spaced
+// out, since logged-out traffic is rate limited more aggressively.
+func (a *App) scrapeAsGuest(ctx context.Context, s snapshot) ([]types.Post, error) {
+	if len(s.config.Scraping.Lists) == 0 && len(s.config.Scraping.Profiles) == 0 {
+		return nil, fmt.Errorf("guest mode needs public lists or profiles to scrape - add some under [scraping] or log in")
+	}
+
+	count := min(s.config.Scraping.PostsPerScrape, guestMaxPosts)
+	var posts []types.Post
+	first := true
+	scrape := func(name string, fn func() ([]types.Post, error)) {
+		if !first {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(guestScrapeInterval + time.Duration(rand.Int63n(int64(guestScrapeInterval)))):
+			}
+		}
+		first = false
+
+		log.Printf("Scraping %d posts from %s as guest...", count, name)
+		more, err := fn()
+		if err != nil {
+			// A timed out scrape still returns the posts it collected
+			log.Printf("Failed to scrape %s as guest: %v", name, err)
+		}
+		posts = mergePosts(posts, more)
+	}
+
+	for _, source := range timelineSources(s.config.Scraping) {
+		if !source.public {
 			continue
 		}
-		if analysis.RelevanceScore >= s.config.Analysis.RelevanceThreshold {
-			relevantPosts = append(relevantPosts, types.PostWithAnalysis{
-				Post:     post,
-				Analysis: analysis,
+		for _, name := range source.names {
+			scrape(fmt.Sprintf(source.label, name), func() ([]types.Post, error) {
+				return source.scrape(s.scraper, ctx, nil, name, count)
 			})
 		}
 	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	log.Printf("Found %d posts above relevance threshold (%.0f%%)",
-		len(relevantPosts), s.config.Analysis.RelevanceThreshold*100)
-
-	// Cache output
-	if cachePath, err := store.SaveStepOutput(store.Step3Filtered, relevantPosts); err != nil {
-		log.Printf("Failed to cache filtered posts: %v", err)
-	} else {
-		log.Printf("Cached filtered posts to: %s", cachePath)
+	posts = salvageCheckpoints(posts)
+	if !s.config.Scraping.IncludePromoted {
+		posts = dropPromoted(posts)
+	}
+	if langs := s.config.Scraping.Languages; len(langs) > 0 {
+		posts = dropOtherLanguages(posts, langs)
+	}
+	if s.config.Scraping.CollapseNearDuplicates {
+		posts = collapseNearDuplicates(posts)
 	}
+	detectEdits(posts)
+	log.Printf("Scraped %d posts as guest", len(posts))
 
-	return relevantPosts
+	cacheScrapedPosts(posts)
+	return posts, nil
 }
 
-// BuildDigest performs Step 4: Build and save the digest.
-// Caches the markdown to step4_digests and saves to user output directory.
-// Returns the path to the saved digest file.
-func (a *App) BuildDigest(posts []types.PostWithAnalysis, totalScraped int) (string, error) {
-	log.Println("Building digest...")
-
+// ScrapeBookmarks performs Step 1 against the user's saved bookmarks
+// instead of the feed.
+// Logs progress and caches output to step1_posts.
+func (a *App) ScrapeBookmarks(ctx context.Context) ([]types.Post, error) {
 	s := a.getSnapshot()
-	builder := digest.New(s.config.Digest.OutputDir, s.config.Digest.MaxPosts)
-
-	content, err := builder.Render(posts, totalScraped)
+	cookies, err := a.sessionCookies(s)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	// Cache markdown
-	if cachePath, err := store.SaveTextOutput(store.Step4Digests, content.Markdown, ".md"); err != nil {
-		log.Printf("Failed to cache digest: %v", err)
-	} else {
-		log.Printf("Cached digest to: %s", cachePath)
-	}
+	count := s.config.Scraping.PostsPerScrape
 
-	// Save to user output directory
-	d, err := builder.Save(content)
+	log.Printf("Scraping %d posts from bookmarks...", count)
+	posts, err := s.scraper.ScrapeBookmarks(ctx, cookies, count)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
+	log.Printf("Scraped %d posts", len(posts))
 
-	log.Printf("Digest saved to: %s (%d posts)", d.FilePath, d.PostCount)
-	return d.FilePath, nil
+	cacheScrapedPosts(posts)
+	return posts, nil
 }
 
-// =============================================================================
-// Orchestration Methods
-// =============================================================================
-
-// GenerateDigest performs the full scrape -> analyze -> build digest flow.
-func (a *App) GenerateDigest() error {
-	log.Println("Generate Digest triggered...")
-
-	if !a.authManager.IsAuthenticated() {
-		log.Println("Not authenticated - please login to X first")
-		return nil
+// ReparseSnapshot re-runs DOM extraction with the current selectors over a
+// saved HTML snapshot: the given step1_html file, or the latest one if file
+// is empty. Nothing is cached; the posts are only returned.
+func (a *App) ReparseSnapshot(ctx context.Context, file string) ([]types.Post, error) {
+	var snap store.HTMLSnapshot
+	var err error
+	if file == "" {
+		snap, file, err = store.LoadLatestStepOutput[store.HTMLSnapshot](store.Step1HTML)
+	} else {
+		snap, err = store.LoadStepOutput[store.HTMLSnapshot](file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load HTML snapshot: %w", err)
 	}
+	log.Printf("Loaded HTML snapshot from: %s", file)
+	return a.getSnapshot().scraper.ReparseSnapshot(ctx, snap)
+}
 
-	ctx := context.Background()
+// cacheScrapedPosts caches Step 1 output to step1_posts, logging the result.
+func cacheScrapedPosts(posts []types.Post) {
+	if cachePath, err := store.SaveStepOutput(store.Step1Posts, posts); err != nil {
+		log.Printf("Failed to cache posts: %v", err)
+	} else {
+		log.Printf("Cached posts to: %s", cachePath)
+	}
+}
 
-	// Step 1: Scrape posts
-	posts, err := a.ScrapeForYou(ctx)
+// salvageCheckpoints adds the posts collected by scrapes that didn't finish,
+// whether earlier in this run or in an earlier run that crashed or was
+// killed, and clears their checkpoints
+func salvageCheckpoints(posts []types.Post) []types.Post {
+	checkpoints, err := store.TakeScrapeCheckpoints()
 	if err != nil {
-		log.Printf("Scrape failed: %v", err)
-		return err
+		log.Printf("Failed to load scrape checkpoints: %v", err)
+		return posts
 	}
-	if len(posts) == 0 {
-		log.Println("No posts scraped - nothing to analyze")
+	for target, salvaged := range checkpoints {
+		log.Printf("Salvaging %d posts from an interrupted scrape of %s", len(salvaged), target)
+		posts = mergePosts(posts, salvaged)
+	}
+	return posts
+}
+
+// lastScrapedIDs returns the IDs of the posts from the most recent Step 1
+// output, or nil if there isn't one
+func lastScrapedIDs() map[string]bool {
+	posts, _, err := store.LoadLatestStepOutput[[]types.Post](store.Step1Posts)
+	if err != nil {
 		return nil
 	}
+	ids := make(map[string]bool, len(posts))
+	for _, p := range posts {
+		ids[p.ID] = true
+	}
+	return ids
+}
 
-	// Step 2: Analyze posts with LLM
-	analyses, err := a.AnalyzePosts(ctx, posts)
+// detectEdits records each post's text in the post version history and
+// flags the posts whose text changed since they were first seen, in place.
+// Histories of posts not seen for postHistoryMaxAge are dropped.
+func detectEdits(posts []types.Post) {
+	history, err := store.LoadPostVersions()
 	if err != nil {
-		log.Printf("Analysis failed: %v", err)
-		return err
+		log.Printf("Failed to load post versions: %v", err)
+		return
 	}
 
-	// Step 3: Filter by relevance threshold
-	relevantPosts := a.FilterByRelevance(posts, analyses)
-	if len(relevantPosts) == 0 {
-		log.Println("No posts above relevance threshold - no digest generated")
-		return nil
+	now := time.Now()
+	edited := 0
+	for i := range posts {
+		p := &posts[i]
+		if p.ID == "" {
+			continue
+		}
+		h := history[p.ID]
+		h.LastSeen = now
+		if n := len(h.Versions); n == 0 || !sameText(h.Versions[n-1].Content, p.Content) {
+			h.Versions = append(h.Versions, store.PostVersion{Content: p.Content, SeenAt: now})
+		}
+		history[p.ID] = h
+		if len(h.Versions) > 1 {
+			p.Edited = true
+			p.FirstSeenContent = h.Versions[0].Content
+			edited++
+		}
+	}
+	if edited > 0 {
+		log.Printf("%d posts were edited since first seen", edited)
 	}
 
-	// Step 4: Build and save digest
-	digestPath, err := a.BuildDigest(relevantPosts, len(posts))
-	if err != nil {
-		log.Printf("Failed to build digest: %v", err)
-		return err
+	for id, h := range history {
+		if now.Sub(h.LastSeen) > postHistoryMaxAge {
+			delete(history, id)
+		}
+	}
+	if err := store.SavePostVersions(history); err != nil {
+		log.Printf("Failed to save post versions: %v", err)
 	}
+}
 
-	// Step 5: Open the digest in the default text editor
-	if err := browser.OpenFile(digestPath); err != nil {
-		log.Printf("Failed to open digest: %v", err)
-		// Don't return error - digest was built successfully
+// sameText reports whether two scrapes of a post's text agree, ignoring
+// whitespace and truncation (one being the other cut short, with or without
+// an ellipsis)
+func sameText(a, b string) bool {
+	a = strings.TrimSuffix(strings.Join(strings.Fields(a), " "), "…")
+	b = strings.TrimSuffix(strings.Join(strings.Fields(b), " "), "…")
+	if a == "" || b == "" {
+		return a == b
 	}
+	return strings.HasPrefix(a, b) || strings.HasPrefix(b, a)
+}
 
-	return nil
+// dropPromoted removes ads from posts
+func dropPromoted(posts []types.Post) []types.Post {
+	var kept []types.Post
+	for _, p := range posts {
+		if !p.IsPromoted {
+			kept = append(kept, p)
+		}
+	}
+	if dropped := len(posts) - len(kept); dropped > 0 {
+		log.Printf("Dropped %d promoted posts", dropped)
+	}
+	return kept
 }
 
-// ViewLastDigest opens the most recent digest file.
-func (a *App) ViewLastDigest() error {
-	s := a.getSnapshot()
+// collapseNearDuplicates keeps one post of each group of near-duplicates
+// (see ranking.NearDuplicates), the one with the most likes and reposts,
+// and lists the rest on it. Mentions are left alone, since each is someone
+// talking to the user.
+func collapseNearDuplicates(posts []types.Post) []types.Post {
+	var candidates []types.Post
+	var index []int // Of each candidate in posts
+	for i, p := range posts {
+		if p.Source != types.SourceMentions {
+			candidates = append(candidates, p)
+			index = append(index, i)
+		}
+	}
 
-	path, err := digest.GetLatestDigest(s.config.Digest.OutputDir)
-	if err != nil {
-		log.Printf("No digest found: %v", err)
-		return err
+	dropped := make(map[int]bool)
+	for _, group := range ranking.NearDuplicates(candidates) {
+		best := slices.MaxFunc(group, func(i, j int) int {
+			return cmp.Compare(candidates[i].Likes+candidates[i].Retweets, candidates[j].Likes+candidates[j].Retweets)
+		})
+		rep := &posts[index[best]]
+		for _, i := range group {
+			if i == best {
+				continue
+			}
+			p := candidates[i]
+			rep.AlsoCoveredBy = append(rep.AlsoCoveredBy, types.PostRef{ID: p.ID, AuthorHandle: p.AuthorHandle, URL: p.OriginalURL})
+			dropped[index[i]] = true
+		}
+	}
+	if len(dropped) == 0 {
+		return posts
 	}
 
-	log.Printf("Opening digest: %s", path)
-	return browser.OpenFile(path)
+	kept := make([]types.Post, 0, len(posts)-len(dropped))
+	for i, p := range posts {
+		if !dropped[i] {
+			kept = append(kept, p)
+		}
+	}
+	log.Printf("Collapsed %d near-duplicate posts", len(dropped))
+	return kept
 }
 
-// ReloadConfig reloads the configuration from disk.
-func (a *App) ReloadConfig() error {
-	cfg, err := config.Load()
-	if err != nil {
-		return err
+// dropOtherLanguages removes posts X detected as being in none of the given
+// languages. Only primary subtags are compared, so "pt" keeps "pt-BR" and
+// vice versa. Posts whose language X couldn't tell are kept.
+func dropOtherLanguages(posts []types.Post, languages []string) []types.Post {
+	allowed := make(map[string]bool, len(languages))
+	for _, l := range languages {
+		allowed[types.PrimaryLang(l)] = true
+	}
+	var kept []types.Post
+	for _, p := range posts {
+		if lang := types.PrimaryLang(p.Lang); lang == "" || allowed[lang] {
+			kept = append(kept, p)
+		}
 	}
+	if dropped := len(posts) - len(kept); dropped > 0 {
+		log.Printf("Dropped %d posts in other languages than %s", dropped, strings.Join(languages, ", "))
+	}
+	return kept
+}
 
-	// Recreate analyzer with new config
-	newAnalyzer, err := analyzer.New(cfg.Analysis, cfg.Interests)
-	if err != nil {
-		return err
+// unrollThreads stitches self-threads into single posts. A thread shows up
+// in the feed as consecutive posts by one author where the later ones are
+// replies; up to maxThreadUnrolls of them are read in full from their
+// conversation page, and the rest are stitched from what the feed showed.
+func unrollThreads(ctx context.Context, s snapshot, cookies []*network.Cookie, posts []types.Post) []types.Post {
+	var result []types.Post
+	unrolled := 0
+	for i := 0; i < len(posts); {
+		head := posts[i]
+
+		// Gather the feed's consecutive self-replies following head
+		end := i + 1
+		for end < len(posts) && posts[end].IsReply && strings.EqualFold(posts[end].AuthorHandle, head.AuthorHandle) {
+			end++
+		}
+		thread := posts[i:end]
+		i = end
+
+		if len(thread) == 1 {
+			result = append(result, head)
+			continue
+		}
+
+		if unrolled < maxThreadUnrolls && head.OriginalURL != "" && s.budget.allow("thread unrolling") {
+			log.Printf("Unrolling thread by @%s...", head.AuthorHandle)
+			if full, err := s.scraper.ScrapeThread(ctx, cookies, head); err != nil {
+				log.Printf("Failed to unroll thread %s: %v", head.OriginalURL, err)
+			} else if len(full) >= len(thread) {
+				thread = full
+			}
+			unrolled++
+		}
+
+		parts := make([]string, len(thread))
+		for j, p := range thread {
+			parts[j] = p.Content
+		}
+		head.ThreadParts = parts
+		head.Content = strings.Join(parts, "\n\n")
+		result = append(result, head)
 	}
 
-	a.mu.Lock()
+	if unrolled > 0 {
+		log.Printf("Unrolled %d threads", unrolled)
+	}
+	return result
+}
+
+// fetchReplyParents attaches the post each reply answers, in place, for up
+// to maxReplyParentFetches replies. Replies to the same author are left
+// alone: those are threads, which unrollThreads handles. A parent that is
+// also among posts is attached as that copy, which has its analysis context.
+// Where the GraphQL data named the parent, both cases are settled without
+// loading the reply's page.
+func fetchReplyParents(ctx context.Context, s snapshot, cookies []*network.Cookie, posts []types.Post) {
+	byID := make(map[string]types.Post, len(posts))
+	for _, p := range posts {
+		byID[p.ID] = p
+	}
+
+	fetched := 0
+	for i := range posts {
+		reply := &posts[i]
+		if !reply.IsReply || reply.ReplyTo != nil || reply.OriginalURL == "" || len(reply.ThreadParts) > 1 {
+			continue
+		}
+		if reply.ReplyToHandle != "" && strings.EqualFold(reply.ReplyToHandle, reply.AuthorHandle) {
+			continue // A self-thread
+		}
+		if known, ok := byID[reply.ReplyToID]; ok && reply.ReplyToID != "" {
+			known.ReplyTo = nil
+			reply.ReplyTo = &known
+			continue
+		}
+		if fetched >= maxReplyParentFetches || !s.budget.allow("reply parents") {
+			break
+		}
+		fetched++
+
+		parent, err := s.scraper.ScrapeReplyParent(ctx, cookies, *reply)
+		if err != nil {
+			log.Printf("Failed to fetch the parent of reply %s: %v", reply.OriginalURL, err)
+			continue
+		}
+		if strings.EqualFold(parent.AuthorHandle, reply.AuthorHandle) {
+			continue // A self-thread after all
+		}
+		if known, ok := byID[parent.ID]; ok {
+			parent = known
+		}
+		parent.ReplyTo = nil // Only one level up
+		reply.ReplyTo = &parent
+	}
+
+	if fetched > 0 {
+		log.Printf("Fetched the parents of %d replies", fetched)
+	}
+}
+
+// mergePosts appends posts from more that aren't already in posts (by ID).
+// A post already there picks up the accounts the new copy was reposted by.
+func mergePosts(posts []types.Post, more []types.Post) []types.Post {
+	seen := make(map[string]int, len(posts)) // Index in posts
+	for i, p := range posts {
+		seen[p.ID] = i
+	}
+	for _, p := range more {
+		if i, ok := seen[p.ID]; ok {
+			posts[i].MergeRetweeters(p)
+			continue
+		}
+		seen[p.ID] = len(posts)
+		posts = append(posts, p)
+	}
+	return posts
+}
+
+// AnalyzePosts performs Step 2: Analyze posts with LLM for relevance scoring.
+// Logs progress and caches output to step2_analyses.
+func (a *App) AnalyzePosts(ctx context.Context, posts []types.Post) ([]types.Analysis, error) {
+	s := a.getSnapshot()
+	posts, _ = dropMuted(s.config.Interests, posts)
+	return a.analyzePosts(ctx, s, posts)
+}
+
+// analyzePosts implements AnalyzePosts with an explicit snapshot.
+func (a *App) analyzePosts(ctx context.Context, s snapshot, posts []types.Post) ([]types.Analysis, error) {
+	if s.config.Analysis.FetchLinkedArticles && s.budget.allow("linked article excerpts") {
+		fetchLinkedArticles(ctx, posts)
+	}
+	if s.config.Analysis.EnrichAuthors {
+		attachAuthorProfiles(posts)
+	}
+
+	if ratings, err := store.LoadRatings(); err != nil {
+		log.Printf("Failed to load ratings: %v", err)
+	} else {
+		examples := calibrationExamples(ratings)
+		if len(examples) > 0 {
+			log.Printf("Calibrating with %d rated posts", len(examples))
+		}
+		s.analyzer.SetExamples(examples)
+	}
+
+	log.Println("Analyzing posts with LLM...")
+	analyses, deferred, err := s.analyzer.AnalyzePosts(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+	log.Printf("Analyzed %d posts", len(analyses))
+
+	// Cache output
+	if cachePath, err := store.SaveStepOutput(store.Step2Analyses, analyses); err != nil {
+		log.Printf("Failed to cache analyses: %v", err)
+	} else {
+		log.Printf("Cached analyses to: %s", cachePath)
+	}
+
+	// Only now are posts deferred by an earlier run done with; those
+	// deferred again are saved back
+	ids := make([]string, len(posts))
+	for i, p := range posts {
+		ids[i] = p.ID
+	}
+	if err := store.RemoveDeferredPosts(ids); err != nil {
+		log.Printf("Failed to clear deferred posts: %v", err)
+	}
+	if len(deferred) > 0 {
+		if err := store.SaveDeferredPosts(deferred); err != nil {
+			log.Printf("Failed to save deferred posts: %v", err)
+		}
+	}
+
+	return analyses, nil
+}
+
+// fetchLinkedArticles attaches an excerpt of each linked page to posts with
+// link cards, in place. Failures are logged and leave the card as-is.
+func fetchLinkedArticles(ctx context.Context, posts []types.Post) {
+	fetcher := article.NewFetcher(articleFetchTimeout)
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, articleFetchConcurrency)
+	var fetched atomic.Int32
+	for i := range posts {
+		card := posts[i].Card
+		if card == nil || card.URL == "" || card.Excerpt != "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			excerpt, err := fetcher.Excerpt(ctx, card.URL, articleExcerptChars)
+			if err != nil {
+				log.Printf("Failed to fetch linked article %s: %v", card.URL, err)
+				return
+			}
+			card.Excerpt = excerpt
+			fetched.Add(1)
+		}()
+	}
+	wg.Wait()
+
+	log.Printf("Fetched %d linked articles", fetched.Load())
+}
+
+// attachAuthorProfiles sets each post's author profile from the cache, in
+// place
+func attachAuthorProfiles(posts []types.Post) {
+	profiles, err := store.LoadAuthorProfiles()
+	if err != nil {
+		log.Printf("Failed to load author profiles: %v", err)
+		return
+	}
+	for i := range posts {
+		if profile, ok := profiles[normalizeHandle(posts[i].AuthorHandle)]; ok {
+			posts[i].AuthorProfile = &profile
+		}
+	}
+}
+
+// enrichAuthors fetches the profiles of authors of the given posts that
+// aren't cached or were fetched more than authorProfileMaxAge ago, up to
+// maxAuthorProfilesFetched, best posts' authors first. Failures are logged.
+func (a *App) enrichAuthors(ctx context.Context, s snapshot, posts []types.PostWithAnalysis) {
+	profiles, err := store.LoadAuthorProfiles()
+	if err != nil {
+		log.Printf("Failed to load author profiles: %v", err)
+		return
+	}
+
+	ranked := slices.Clone(posts)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Rank() > ranked[j].Rank() })
+	var stale []string
+	seen := make(map[string]bool)
+	for _, p := range ranked {
+		handle := normalizeHandle(p.Post.AuthorHandle)
+		if handle == "" || seen[handle] {
+			continue
+		}
+		seen[handle] = true
+		if profile, ok := profiles[handle]; !ok || time.Since(profile.FetchedAt) > authorProfileMaxAge {
+			stale = append(stale, handle)
+		}
+	}
+	if len(stale) == 0 {
+		return
+	}
+	stale = stale[:min(len(stale), maxAuthorProfilesFetched)]
+
+	cookies, err := a.sessionCookies(s)
+	if err != nil {
+		log.Printf("Not fetching author profiles: %v", err)
+		return
+	}
+	log.Printf("Fetching %d author profiles...", len(stale))
+	fetched, err := s.scraper.ScrapeAuthorProfiles(ctx, cookies, stale)
+	if err != nil {
+		log.Printf("Failed to fetch author profiles: %v", err)
+		return
+	}
+	maps.Copy(profiles, fetched)
+	if err := store.SaveAuthorProfiles(profiles); err != nil {
+		log.Printf("Failed to save author profiles: %v", err)
+	}
+}
+
+// summarizeDiscussions reads the replies to the most replied-to of the
+// posts a digest will show, up to analysis.max_discussion_fetches, into their
+// Discussion, and has the LLM rewrite their summaries to take them in.
+// Re-caches the filtered posts with the new summaries. Failures are logged.
+func (a *App) summarizeDiscussions(ctx context.Context, s snapshot, posts []types.PostWithAnalysis) {
+	ranker, err := ranking.New(s.config.Digest)
+	if err != nil {
+		log.Printf("Not summarizing discussions: %v", err)
+		return
+	}
+	shown := make(map[string]bool)
+	for _, p := range shownPosts(ranker.Rank(posts), s.config.Digest.MaxPosts) {
+		shown[p.Post.ID] = true
+	}
+	var candidates []int // Indexes in posts
+	for i, p := range posts {
+		if shown[p.Post.ID] && p.Analysis != nil && p.Post.Replies >= minDiscussionReplies && p.Post.OriginalURL != "" {
+			candidates = append(candidates, i)
+		}
+	}
+	if len(candidates) == 0 {
+		return
+	}
+	slices.SortStableFunc(candidates, func(i, j int) int { return cmp.Compare(posts[j].Post.Replies, posts[i].Post.Replies) })
+	maxFetches := s.config.Analysis.MaxDiscussionFetches
+	if maxFetches == 0 {
+		maxFetches = defaultDiscussionFetches
+	}
+	candidates = candidates[:min(len(candidates), maxFetches)]
+
+	cookies, err := a.sessionCookies(s)
+	if err != nil {
+		log.Printf("Not summarizing discussions: %v", err)
+		return
+	}
+	log.Printf("Reading the replies to %d posts...", len(candidates))
+	var discussed []types.PostWithAnalysis
+	for _, i := range candidates {
+		replies, err := s.scraper.ScrapeReplies(ctx, cookies, posts[i].Post)
+		if err != nil {
+			log.Printf("Failed to read the replies to %s: %v", posts[i].Post.OriginalURL, err)
+			continue
+		}
+		if len(replies) == 0 {
+			continue
+		}
+		posts[i].Post.Discussion = replies
+		discussed = append(discussed, posts[i])
+	}
+	if len(discussed) == 0 {
+		return
+	}
+
+	summaries, err := s.analyzer.SummarizeDiscussions(ctx, discussed)
+	if err != nil {
+		log.Printf("Failed to summarize discussions: %v", err)
+		return
+	}
+	rewritten := 0
+	for i := range posts {
+		summary, ok := summaries[posts[i].Post.ID]
+		if !ok || posts[i].Analysis == nil || posts[i].Post.Discussion == nil {
+			continue
+		}
+		updated := *posts[i].Analysis
+		updated.Summary = summary
+		posts[i].Analysis = &updated
+		rewritten++
+	}
+	log.Printf("Summarized the discussion of %d posts", rewritten)
+
+	if cachePath, err := store.SaveStepOutput(store.Step3Filtered, posts); err != nil {
+		log.Printf("Failed to cache posts with discussion summaries: %v", err)
+	} else {
+		log.Printf("Cached posts with discussion summaries to: %s", cachePath)
+	}
+}
+
+// groupIntoSections has the LLM group the feed posts a digest of posts
+// will show into topic sections, and records each post's section on it, in
+// place. The posts are cached to step3_filtered again with their sections,
+// so re-rendered digests keep them. Failures are logged and leave the
+// digest as one list.
+func groupIntoSections(ctx context.Context, s snapshot, posts []types.PostWithAnalysis) {
+	ranker, err := ranking.New(s.config.Digest)
+	if err != nil {
+		log.Printf("Not grouping posts into sections: %v", err)
+		return
+	}
+	shown := shownPosts(ranker.Rank(posts), s.config.Digest.MaxPosts)
+	if len(shown) == 0 {
+		return
+	}
+
+	log.Printf("Grouping %d posts into topic sections...", len(shown))
+	sections, err := s.analyzer.GroupIntoSections(ctx, shown)
+	if err != nil {
+		log.Printf("Failed to group posts into sections: %v", err)
+		return
+	}
+	sectionOf := make(map[string]string)
+	for _, section := range sections {
+		for _, id := range section.PostIDs {
+			sectionOf[id] = section.Name
+		}
+	}
+	for i := range posts {
+		posts[i].Section = sectionOf[posts[i].Post.ID]
+	}
+	log.Printf("Grouped posts into %d sections", len(sections))
+
+	if cachePath, err := store.SaveStepOutput(store.Step3Filtered, posts); err != nil {
+		log.Printf("Failed to cache sectioned posts: %v", err)
+	} else {
+		log.Printf("Cached sectioned posts to: %s", cachePath)
+	}
+}
+
+// FilterByRelevance performs Step 3: Filter posts by relevance threshold.
+// Logs progress and caches output to step3_filtered.
+func (a *App) FilterByRelevance(posts []types.Post, analyses []types.Analysis) []types.PostWithAnalysis {
+	return a.filterAndLearn(a.getSnapshot(), posts, analyses)
+}
+
+// filterAndLearn implements FilterByRelevance with an explicit snapshot.
+func (a *App) filterAndLearn(s snapshot, posts []types.Post, analyses []types.Analysis) []types.PostWithAnalysis {
+	relevantPosts := a.filterByRelevance(s, posts, analyses, s.config.Analysis.RelevanceThreshold)
+	updateAuthorAffinity(posts, analyses, relevantPosts)
+	return relevantPosts
+}
+
+// filterByRelevance implements FilterByRelevance with an explicit threshold.
+func (a *App) filterByRelevance(s snapshot, posts []types.Post, analyses []types.Analysis, threshold float64) []types.PostWithAnalysis {
+	analysisMap := make(map[string]*types.Analysis)
+	for i := range analyses {
+		analysisMap[analyses[i].PostID] = &analyses[i]
+	}
+
+	// Posts are muted before analysis, but cached analyses may predate a mute
+	muted := newMutes(s.config.Interests)
+
+	var priors *feedbackPriors
+	if s.config.Analysis.FeedbackWeight > 0 {
+		priors = loadFeedbackPriors(s.config.Interests.Keywords)
+	}
+
+	var relevantPosts []types.PostWithAnalysis
+	var baitCount, lowRateCount, mutedCount, ratedCount int
+	for _, post := range posts {
+		analysis, ok := analysisMap[post.ID]
+		if !ok {
+			continue
+		}
+		if muted.match(post) {
+			mutedCount++
+			continue
+		}
+		if s.config.Analysis.ExcludeEngagementBait && analysis.EngagementBait {
+			baitCount++
+			continue
+		}
+		if post.Views > 0 && post.LikeRate() < s.config.Analysis.MinLikeRate {
+			lowRateCount++
+			continue
+		}
+		// Posts the triage model ruled out have no summary to show, so
+		// min_score and ratings mustn't lift them into the digest
+		if analysis.RuledOut {
+			continue
+		}
+		if score := ruleScore(s.config.Interests, post, analysis); score != analysis.RelevanceScore {
+			weighted := *analysis
+			weighted.RelevanceScore = score
+			analysis = &weighted
+		}
+		if adjust := priors.adjustment(post, analysis, s.config.Analysis.FeedbackWeight); adjust != 0 {
+			adjusted := *analysis
+			adjusted.RelevanceScore = min(max(analysis.RelevanceScore+adjust, 0), 1)
+			analysis = &adjusted
+			ratedCount++
+		}
+		// Mentions get their own digest section regardless of relevance
+		if analysis.RelevanceScore >= threshold || post.Source == types.SourceMentions {
+			relevantPosts = append(relevantPosts, types.PostWithAnalysis{
+				Post:     post,
+				Analysis: analysis,
+			})
+		}
+	}
+
+	if weight := s.config.Digest.AuthorAffinityWeight; weight != 0 {
+		applyAuthorAffinity(relevantPosts, weight)
+	}
+	if penalty := s.config.Digest.RepetitionPenalty; penalty > 0 {
+		applyTopicMemory(relevantPosts, penalty)
+	}
+
+	if mutedCount > 0 {
+		log.Printf("Excluded %d muted posts", mutedCount)
+	}
+	if baitCount > 0 {
+		log.Printf("Excluded %d engagement bait posts", baitCount)
+	}
+	if lowRateCount > 0 {
+		log.Printf("Excluded %d posts below minimum like rate (%.2f%%)", lowRateCount, s.config.Analysis.MinLikeRate*100)
+	}
+	if ratedCount > 0 {
+		log.Printf("Adjusted the relevance of %d posts by your ratings", ratedCount)
+	}
+	log.Printf("Found %d posts above relevance threshold (%.0f%%)",
+		len(relevantPosts), threshold*100)
+
+	// Cache output
+	if cachePath, err := store.SaveStepOutput(store.Step3Filtered, relevantPosts); err != nil {
+		log.Printf("Failed to cache filtered posts: %v", err)
+	} else {
+		log.Printf("Cached filtered posts to: %s", cachePath)
+	}
+
+	return relevantPosts
+}
+
+// ruleScore returns a post's relevance score after the scoring rules of the
+// interest keywords and priority accounts it matches: multiplied by the
+// keyword weight and its author's weight, capped at 1, then raised to the
+// highest min_score among them
+func ruleScore(interests config.InterestsConfig, post types.Post, analysis *types.Analysis) float64 {
+	weight := keywordWeight(interests.Keywords, post, analysis)
+	var floor float64
+	text := matchText(post, analysis)
+	for _, k := range interests.Keywords {
+		if text.has(k.Keyword) {
+			floor = max(floor, k.MinScore)
+		}
+	}
+	author := normalizeHandle(post.AuthorHandle)
+	for _, a := range interests.PriorityAccounts {
+		if normalizeHandle(a.Handle) == author {
+			weight *= a.EffectiveWeight()
+			floor = max(floor, a.MinScore)
+		}
+	}
+	return max(min(analysis.RelevanceScore*weight, 1), floor)
+}
+
+// keywordWeight returns the largest weight among the interest keywords a post
+// matches (in its text or analyzed topics), or 1 if it matches none. A post
+// that only matches down-weighted keywords gets the smallest of those.
+func keywordWeight(keywords []config.Keyword, post types.Post, analysis *types.Analysis) float64 {
+	text := matchText(post, analysis)
+
+	boost, penalty := 1.0, 1.0
+	for _, k := range keywords {
+		if !text.has(k.Keyword) {
+			continue
+		}
+		boost = max(boost, k.EffectiveWeight())
+		penalty = min(penalty, k.EffectiveWeight())
+	}
+	if boost > 1 {
+		return boost
+	}
+	return penalty
+}
+
+// keywordText is the text interest keywords are matched against, split
+// into words
+type keywordText struct {
+	text  string
+	words []string
+}
+
+// matchText returns the text interest keywords are matched against: the
+// post's content and its analyzed topics
+func matchText(post types.Post, analysis *types.Analysis) keywordText {
+	text := post.Content + "\n" + strings.Join(analysis.Topics, "\n")
+	return keywordText{text: text, words: ranking.Tokens(text)}
+}
+
+// has reports whether keyword appears in the text as whole words, so "go"
+// matches "Go 1.24" but not "good" or "ago"
+func (t keywordText) has(keyword string) bool {
+	return ranking.HasPhrase(t.text, t.words, keyword)
+}
+
+// applyAuthorAffinity sets each post's rank to its relevance shifted by its
+// author's affinity from earlier runs. Authors without enough history keep
+// their plain relevance.
+func applyAuthorAffinity(posts []types.PostWithAnalysis, weight float64) {
+	affinity, err := store.LoadAuthorAffinity()
+	if err != nil {
+		log.Printf("Failed to load author affinity: %v", err)
+		return
+	}
+
+	for i := range posts {
+		aff, ok := affinity[normalizeHandle(posts[i].Post.AuthorHandle)]
+		if !ok || aff.Seen < minAffinityObservations {
+			continue
+		}
+		posts[i].RankScore = posts[i].Analysis.RelevanceScore + weight*(aff.Score-0.5)
+	}
+}
+
+// applyTopicMemory counts, for each post, the posts about the same subject
+// in digests of the last topicMemoryMaxAge, and multiplies its rank by
+// (1 - penalty) per repeat
+func applyTopicMemory(posts []types.PostWithAnalysis, penalty float64) {
+	memory, err := store.LoadTopicMemory()
+	if err != nil {
+		log.Printf("Failed to load topic memory: %v", err)
+		return
+	}
+	earlier := make([]types.PostWithAnalysis, 0, len(memory))
+	for _, m := range memory {
+		earlier = append(earlier, rememberedPost(m))
+	}
+
+	suppressed := 0
+	for i, n := range ranking.CountRepeats(posts, earlier) {
+		if n == 0 {
+			continue
+		}
+		posts[i].Repeats = n
+		posts[i].RankScore = posts[i].Rank() * math.Pow(1-penalty, float64(n))
+		suppressed++
+	}
+	if suppressed > 0 {
+		log.Printf("Down-ranked %d posts on subjects already in recent digests", suppressed)
+	}
+}
+
+// rememberedPost rebuilds enough of a post from the topic memory to compare
+// subjects with
+func rememberedPost(m store.DigestedPost) types.PostWithAnalysis {
+	p := types.PostWithAnalysis{
+		Post:     types.Post{ID: m.ID, Content: m.Content},
+		Analysis: &types.Analysis{PostID: m.ID, Topics: m.Topics, Summary: m.Summary},
+	}
+	if m.QuotedID != "" {
+		p.Post.QuotedPost = &types.Post{ID: m.QuotedID}
+	}
+	if domain, title, ok := strings.Cut(m.Link, "|"); ok {
+		p.Post.Card = &types.LinkCard{Domain: domain, Title: title}
+	}
+	return p
+}
+
+// rememberTopics adds the posts a digest showed to the topic memory,
+// replacing earlier entries for the same posts and dropping entries older
+// than topicMemoryMaxAge
+func rememberTopics(posts []types.PostWithAnalysis) {
+	memory, err := store.LoadTopicMemory()
+	if err != nil {
+		log.Printf("Failed to load topic memory: %v", err)
+		return
+	}
+
+	now := time.Now()
+	shown := make(map[string]bool, len(posts))
+	for _, p := range posts {
+		shown[p.Post.ID] = true
+	}
+	kept := memory[:0]
+	for _, m := range memory {
+		if !shown[m.ID] && now.Sub(m.At) < topicMemoryMaxAge {
+			kept = append(kept, m)
+		}
+	}
+	for _, p := range posts {
+		m := store.DigestedPost{
+			ID:      p.Post.ID,
+			Topics:  p.Analysis.Topics,
+			Summary: p.Analysis.Summary,
+			Content: p.Post.Content,
+			At:      now,
+		}
+		if q := p.Post.QuotedPost; q != nil {
+			m.QuotedID = q.ID
+		}
+		m.Link = ranking.LinkKey(p.Post.Card)
+		kept = append(kept, m)
+	}
+
+	if err := store.SaveTopicMemory(kept); err != nil {
+		log.Printf("Failed to save topic memory: %v", err)
+	}
+}
+
+// updateAuthorAffinity folds this run's outcome into each analyzed author's
+// affinity: included posts pull it toward 1, filtered-out posts toward 0.
+func updateAuthorAffinity(posts []types.Post, analyses []types.Analysis, relevantPosts []types.PostWithAnalysis) {
+	affinity, err := store.LoadAuthorAffinity()
+	if err != nil {
+		log.Printf("Failed to load author affinity: %v", err)
+		return
+	}
+
+	analyzed := make(map[string]bool, len(analyses))
+	for _, a := range analyses {
+		analyzed[a.PostID] = true
+	}
+	included := make(map[string]bool, len(relevantPosts))
+	for _, p := range relevantPosts {
+		included[p.Post.ID] = true
+	}
+
+	now := time.Now()
+	for _, post := range posts {
+		if !analyzed[post.ID] || post.Source == types.SourceMentions {
+			continue
+		}
+		outcome := 0.0
+		if included[post.ID] {
+			outcome = 1
+		}
+
+		handle := normalizeHandle(post.AuthorHandle)
+		aff, ok := affinity[handle]
+		if ok {
+			aff.Score += affinityLearningRate * (outcome - aff.Score)
+		} else {
+			aff.Score = outcome
+		}
+		aff.Seen++
+		if included[post.ID] {
+			aff.Included++
+		}
+		aff.UpdatedAt = now
+		affinity[handle] = aff
+	}
+
+	if err := store.SaveAuthorAffinity(affinity); err != nil {
+		log.Printf("Failed to save author affinity: %v", err)
+	}
+}
+
+// SelectHeadlines is the zero-LLM alternative to Steps 2-3: it picks the posts
+// with the most engagement from priority accounts within the headlines window.
+// If no priority accounts are configured, all accounts are considered.
+// Logs progress and caches output to step3_filtered.
+func (a *App) SelectHeadlines(posts []types.Post) []types.PostWithAnalysis {
+	s := a.getSnapshot()
+
+	priority := make(map[string]bool)
+	for _, account := range s.config.Interests.PriorityAccounts {
+		priority[normalizeHandle(account.Handle)] = true
+	}
+	if len(priority) == 0 {
+		log.Println("No priority accounts configured - considering all accounts for headlines")
+	}
+
+	var cutoff time.Time
+	if s.config.Digest.HeadlinesWindowHours > 0 {
+		cutoff = time.Now().Add(-time.Duration(s.config.Digest.HeadlinesWindowHours) * time.Hour)
+	}
+
+	var headlines []types.PostWithAnalysis
+	for _, post := range posts {
+		if len(priority) > 0 && !priority[normalizeHandle(post.AuthorHandle)] {
+			continue
+		}
+		if !cutoff.IsZero() && !post.Timestamp.IsZero() && post.Timestamp.Before(cutoff) {
+			continue
+		}
+		headlines = append(headlines, types.PostWithAnalysis{Post: post})
+	}
+
+	// Most engaging first; rankers keep this order for posts without analysis
+	sort.SliceStable(headlines, func(i, j int) bool {
+		return headlines[i].Post.Engagement() > headlines[j].Post.Engagement()
+	})
+
+	log.Printf("Selected %d headline posts (window: %dh)", len(headlines), s.config.Digest.HeadlinesWindowHours)
+
+	// Cache output
+	if cachePath, err := store.SaveStepOutput(store.Step3Filtered, headlines); err != nil {
+		log.Printf("Failed to cache headline posts: %v", err)
+	} else {
+		log.Printf("Cached headline posts to: %s", cachePath)
+	}
+
+	return headlines
+}
+
+// ImportModeratedAccounts reads the accounts muted and blocked on X from its
+// settings pages and adds those missing from interests.muted_accounts to
+// the config file, then reloads it. Returns the handles added.
+func (a *App) ImportModeratedAccounts(ctx context.Context) ([]string, error) {
+	s := a.getSnapshot()
+	cookies, err := a.sessionCookies(s)
+	if err != nil {
+		return nil, err
+	}
+	muted, blocked, err := s.scraper.ScrapeModeratedAccounts(ctx, cookies)
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge into the file as it is now, not the config loaded at startup
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	known := make(map[string]bool, len(cfg.Interests.MutedAccounts))
+	for _, handle := range cfg.Interests.MutedAccounts {
+		known[normalizeHandle(handle)] = true
+	}
+	var added []string
+	for _, handle := range slices.Concat(muted, blocked) {
+		if h := normalizeHandle(handle); !known[h] {
+			known[h] = true
+			added = append(added, "@"+handle)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	cfg.Interests.MutedAccounts = append(cfg.Interests.MutedAccounts, added...)
+	if err := cfg.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	return added, a.ReloadConfig()
+}
+
+// SuggestInterests finds the subjects that come up most in the feed, as
+// candidate interest keywords. It uses the latest scraped posts, or scrapes
+// the feed if there are none yet.
+func (a *App) SuggestInterests(ctx context.Context) ([]types.InterestTopic, error) {
+	s := a.getSnapshot()
+	posts, path, err := store.LoadLatestStepOutput[[]types.Post](store.Step1Posts)
+	if err == nil && len(posts) > 0 {
+		log.Printf("Finding topics in %d posts from %s", len(posts), path)
+	} else {
+		if posts, err = a.scrapePosts(ctx, s); err != nil {
+			return nil, err
+		}
+		if len(posts) == 0 {
+			return nil, errors.New("no posts scraped")
+		}
+	}
+	return s.analyzer.SuggestTopics(ctx, posts)
+}
+
+// AddInterestKeywords adds keywords to interests.keywords in the config
+// file, skipping ones already there, and reloads the config. Returns the
+// keywords added.
+func (a *App) AddInterestKeywords(keywords []string) ([]string, error) {
+	// Merge into the file as it is now, not the config loaded at startup
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	known := make(map[string]bool, len(cfg.Interests.Keywords))
+	for _, k := range cfg.Interests.Keywords {
+		known[strings.ToLower(k.Keyword)] = true
+	}
+	var added []string
+	for _, keyword := range keywords {
+		if k := strings.ToLower(keyword); !known[k] {
+			known[k] = true
+			added = append(added, keyword)
+			cfg.Interests.Keywords = append(cfg.Interests.Keywords, config.Keyword{Keyword: keyword})
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	if err := cfg.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+	return added, a.ReloadConfig()
+}
+
+// normalizeHandle lowercases a handle and strips any leading "@".
+func normalizeHandle(handle string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(handle), "@"))
+}
+
+// BuildDigest performs Step 4: Build and save the digest.
+// Caches the markdown to step4_digests and saves to user output directory.
+// Returns the path to the saved digest file.
+func (a *App) BuildDigest(posts []types.PostWithAnalysis, totalScraped int) (string, error) {
+	s := a.getSnapshot()
+	return a.buildDigest(s, posts, totalScraped, s.config.Digest.MaxPosts, digestExtras{})
+}
+
+// digestExtras are optional parts of a digest that only a full run produces
+type digestExtras struct {
+	reduced  string           // Why this is a reduced run, if it is
+	trending *digest.Trending // What's trending on X, if scraped
+	// Candidate interests, if none are configured
+	suggestedTopics []types.InterestTopic
+	focus           time.Duration // Reading time to fit the posts to, for a focus digest
+	muted           int           // Posts dropped before analysis for matching a mute
+	// If set, the posts the digest shows are added to it instead of the
+	// topic memory, for the run to remember once all its digests are built
+	learning *runLearning
+}
+
+// runLearning gathers what the digests of a full run teach the learned
+// stores, author affinity and topic memory, so a run with several interest
+// profiles updates them once for all its digests. Otherwise a post would
+// count as both included and excluded for its author, and one profile's
+// digest would count against the next one's repeats.
+type runLearning struct {
+	posts    []types.Post
+	analyses map[string]types.Analysis         // By post ID
+	included map[string]types.PostWithAnalysis // By post ID; in any digest's filtered posts
+	shown    []types.PostWithAnalysis          // In any digest, without repeats
+}
+
+func newRunLearning() *runLearning {
+	return &runLearning{
+		analyses: make(map[string]types.Analysis),
+		included: make(map[string]types.PostWithAnalysis),
+	}
+}
+
+// addFiltered records one digest's analyzed posts and those it kept
+func (l *runLearning) addFiltered(posts []types.Post, analyses []types.Analysis, relevantPosts []types.PostWithAnalysis) {
+	l.posts = mergePosts(l.posts, posts)
+	for _, a := range analyses {
+		l.analyses[a.PostID] = a
+	}
+	for _, p := range relevantPosts {
+		l.included[p.Post.ID] = p
+	}
+}
+
+// addShown records the posts one digest showed
+func (l *runLearning) addShown(posts []types.PostWithAnalysis) {
+	seen := make(map[string]bool, len(l.shown))
+	for _, p := range l.shown {
+		seen[p.Post.ID] = true
+	}
+	for _, p := range posts {
+		if !seen[p.Post.ID] {
+			seen[p.Post.ID] = true
+			l.shown = append(l.shown, p)
+		}
+	}
+}
+
+// save updates author affinity and topic memory with the whole run
+func (l *runLearning) save() {
+	if len(l.posts) > 0 {
+		updateAuthorAffinity(l.posts, slices.Collect(maps.Values(l.analyses)), slices.Collect(maps.Values(l.included)))
+	}
+	if len(l.shown) > 0 {
+		rememberTopics(l.shown)
+	}
+}
+
+// buildDigest implements BuildDigest with an explicit post limit and extras.
+func (a *App) buildDigest(s snapshot, posts []types.PostWithAnalysis, totalScraped int, maxPosts int, extras digestExtras) (string, error) {
+	log.Println("Building digest...")
+
+	ranker, err := ranking.New(s.config.Digest)
+	if err != nil {
+		return "", err
+	}
+	posts = ranker.Rank(posts)
+	if extras.focus > 0 {
+		posts = digest.FitReadingTime(posts, extras.focus)
+		maxPosts = len(posts)
+	}
+
+	if s.config.Digest.DownloadMedia && s.budget.allow("media downloads") {
+		downloadMedia(posts, maxPosts)
+	}
+
+	builder := digest.New(s.config.Digest.OutputDir, maxPosts)
+	if extras.focus > 0 {
+		builder.SetFocus(extras.focus)
+	}
+	builder.SetGroupByCommunity(s.config.Digest.GroupByCommunity)
+	builder.SetGroupByTopic(s.config.Digest.GroupByTopic)
+	setFeedbackLinks(builder, s.config.Digest.FeedbackPort)
+	builder.SetArticleExcerpts(s.config.Digest.ArticleExcerpts)
+	builder.SetProfile(s.profile)
+	if s.config.Digest.Format == config.FormatText {
+		builder.SetPlainText(s.config.Digest.TextWidth)
+	}
+	if extras.reduced != "" {
+		builder.SetReducedRun(extras.reduced)
+	}
+	if extras.trending != nil {
+		builder.SetTrending(extras.trending)
+	}
+	if skipped := s.budget.skippedSteps(); len(skipped) > 0 {
+		builder.SetOverBudget(s.budget.limit, skipped)
+	}
+	if len(extras.suggestedTopics) > 0 {
+		builder.SetSuggestedTopics(extras.suggestedTopics)
+	}
+	builder.SetMuted(extras.muted)
+
+	// Once a month, close the digest with a look at how digests get read,
+	// and open it with any earlier digests that went unread
+	var habits *digest.ReadingHabits
+	var unread []digest.Digest
+	if usage, err := store.LoadUsage(); err != nil {
+		log.Printf("Failed to load usage log: %v", err)
+	} else {
+		if !usage.HabitsReportedAt.IsZero() && time.Since(usage.HabitsReportedAt) >= habitsReportInterval {
+			habits = readingHabits(usage, time.Now().Add(-habitsReportInterval))
+			builder.SetReadingHabits(habits)
+		}
+		if extras.focus == 0 {
+			unread = unreadDigests(usage, time.Now().Add(-unreadRollupAge))
+			builder.SetUnread(unread)
+		}
+	}
+
+	content, err := builder.Render(posts, totalScraped)
+	if err != nil {
+		return "", err
+	}
+
+	// Cache markdown
+	if cachePath, err := store.SaveTextOutput(store.Step4Digests, content.Markdown, ".md"); err != nil {
+		log.Printf("Failed to cache digest: %v", err)
+	} else {
+		log.Printf("Cached digest to: %s", cachePath)
+	}
+
+	// Save to user output directory
+	d, err := builder.Save(content)
+	if err != nil {
+		return "", err
+	}
+
+	log.Printf("Digest saved to: %s (%d posts)", d.FilePath, d.PostCount)
+	store.RecordDigest(d.FilePath)
+	if extras.learning != nil {
+		extras.learning.addShown(shownPosts(posts, maxPosts))
+	} else {
+		rememberTopics(shownPosts(posts, maxPosts))
+	}
+	recordDigestGenerated(d.FilePath, habits != nil, unread)
+
+	a.syncDigest(s, d.FilePath)
+	return d.FilePath, nil
+}
+
+// shownPosts returns the analyzed feed posts among the first maxPosts, the
+// ones a digest of the ranked posts shows outside its mentions section
+func shownPosts(posts []types.PostWithAnalysis, maxPosts int) []types.PostWithAnalysis {
+	var shown []types.PostWithAnalysis
+	feed := 0
+	for _, p := range posts {
+		if p.Post.Source == types.SourceMentions {
+			continue
+		}
+		if feed++; feed > maxPosts {
+			break
+		}
+		if p.Analysis != nil {
+			shown = append(shown, p)
+		}
+	}
+	return shown
+}
+
+// downloadMedia caches the media of the posts a digest of at most maxPosts
+// will show (every mention, and the first maxPosts others of the ranked
+// posts) locally and records the paths on those posts, in place. Failures
+// are logged and leave that file out.
+func downloadMedia(posts []types.PostWithAnalysis, maxPosts int) {
+	dir, err := store.MediaCacheDir()
+	if err != nil {
+		log.Printf("Failed to locate media cache: %v", err)
+		return
+	}
+	downloader := media.NewDownloader(dir, mediaFetchTimeout)
+
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, mediaFetchConcurrency)
+	feed := 0
+	for i := range posts {
+		post := &posts[i].Post
+		if post.Source != types.SourceMentions {
+			if feed++; feed > maxPosts {
+				continue
+			}
+		}
+		post.LocalMedia = make([]string, len(post.MediaURLs))
+		for j, mediaURL := range post.MediaURLs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				path, err := downloader.Fetch(ctx, mediaURL)
+				if err != nil {
+					log.Printf("Failed to download media %s: %v", mediaURL, err)
+					return
+				}
+				post.LocalMedia[j] = path
+			}()
+		}
+	}
+	wg.Wait()
+
+	// Drop failed downloads
+	for i := range posts {
+		post := &posts[i].Post
+		local := post.LocalMedia[:0]
+		for _, path := range post.LocalMedia {
+			if path != "" {
+				local = append(local, path)
+			}
+		}
+		post.LocalMedia = local
+	}
+}
+
+// beginRun starts recording the artifacts of a run for its manifest
+func beginRun(kind string, cfg *config.Config) *store.Run {
+	hash := ""
+	if settings, err := cfg.Settings(); err != nil {
+		log.Printf("Failed to read config settings: %v", err)
+	} else {
+		hash = settingsHash(settings)
+	}
+	return store.BeginRun(kind, hash)
+}
+
+// finishRun saves the manifest of a run begun with beginRun
+func finishRun(run *store.Run, err error) {
+	if path, saveErr := run.Finish(err); saveErr != nil {
+		log.Printf("Failed to save run manifest: %v", saveErr)
+	} else if path != "" {
+		log.Printf("Run manifest saved to: %s", path)
+	}
+}
+
+// sweepOrphanedChrome kills Chrome left running by scroll4me processes that
+// have exited since, so a crashed CLI run doesn't leak it for as long as
+// the tray app runs. A run's own Chrome is closed by its context.
+func sweepOrphanedChrome() {
+	if _, err := browseropts.SweepOrphans(); err != nil {
+		log.Printf("Failed to look for orphaned Chrome processes: %v", err)
+	}
+}
+
+// settingsHash fingerprints config settings, so runs with the same config
+// can be told apart from runs after a change
+func settingsHash(settings map[string]string) string {
+	h := sha256.New()
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		fmt.Fprintf(h, "%s=%s\n", key, settings[key])
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// recordDigestGenerated adds a new digest to the usage log, noting whether
+// it carried the reading habits section and which unread digests it listed
+func recordDigestGenerated(path string, reportedHabits bool, rolledUp []digest.Digest) {
+	usage, err := store.LoadUsage()
+	if err != nil {
+		log.Printf("Failed to load usage log: %v", err)
+		return
+	}
+	now := time.Now()
+	if reportedHabits || usage.HabitsReportedAt.IsZero() {
+		usage.HabitsReportedAt = now
+	}
+	usage.Events = append(usage.Events, store.UsageEvent{Kind: store.UsageDigestGenerated, At: now, Digest: path})
+	for _, d := range rolledUp {
+		usage.Events = append(usage.Events, store.UsageEvent{Kind: store.UsageDigestRolledUp, At: now, Digest: d.FilePath})
+	}
+	if err := store.SaveUsage(usage); err != nil {
+		log.Printf("Failed to save usage log: %v", err)
+	}
+}
+
+// unreadDigests returns the digests generated before the given time that
+// the user never opened, nor were listed as unread in a later digest
+// already, oldest first. The automatic open on generation doesn't count,
+// since nobody may have been there to read it. Deleted digests are left
+// out.
+func unreadDigests(usage store.UsageLog, before time.Time) []digest.Digest {
+	handled := make(map[string]bool)
+	for _, e := range usage.Events {
+		switch e.Kind {
+		case store.UsageDigestOpened, store.UsageDigestRolledUp:
+			handled[e.Digest] = true
+		}
+	}
+
+	var unread []digest.Digest
+	for _, e := range usage.Events {
+		if e.Kind != store.UsageDigestGenerated || !e.At.Before(before) || handled[e.Digest] {
+			continue
+		}
+		if _, err := os.Stat(e.Digest); err != nil {
+			continue
+		}
+		unread = append(unread, digest.Digest{FilePath: e.Digest, CreatedAt: e.At})
+	}
+	return unread
+}
+
+// UnreadDigests returns the digests that have never been opened, oldest
+// first, including recent ones not yet old enough to be listed in the next
+// digest.
+func (a *App) UnreadDigests() ([]digest.Digest, error) {
+	usage, err := store.LoadUsage()
+	if err != nil {
+		return nil, err
+	}
+	return unreadDigests(usage, time.Now()), nil
+}
+
+// ReadingHabits summarizes the local usage log since the given time.
+func (a *App) ReadingHabits(since time.Time) (*digest.ReadingHabits, error) {
+	usage, err := store.LoadUsage()
+	if err != nil {
+		return nil, err
+	}
+	return readingHabits(usage, since), nil
+}
+
+// readingHabits tallies digests generated since the given time and how
+// they were come back to
+func readingHabits(usage store.UsageLog, since time.Time) *digest.ReadingHabits {
+	h := &digest.ReadingHabits{Since: since}
+	generatedAt := make(map[string]time.Time)
+	firstOpen := make(map[string]time.Time)
+	for _, e := range usage.Events {
+		if e.At.Before(since) {
+			continue
+		}
+		switch e.Kind {
+		case store.UsageDigestGenerated:
+			h.Generated++
+			generatedAt[e.Digest] = e.At
+		case store.UsageDigestOpened:
+			h.Opens++
+			if _, ok := firstOpen[e.Digest]; !ok {
+				firstOpen[e.Digest] = e.At
+			}
+		}
+	}
+
+	var total time.Duration
+	for path, opened := range firstOpen {
+		if generated, ok := generatedAt[path]; ok {
+			h.Reopened++
+			total += opened.Sub(generated)
+		}
+	}
+	if h.Reopened > 0 {
+		h.AvgTimeToReopen = total / time.Duration(h.Reopened)
+	}
+	return h
+}
+
+// syncDigest pushes a saved digest to each configured remote sync target.
+// Failures don't fail the run - the digest is already saved locally - but
+// are queued in the outbox and retried once connectivity returns.
+func (a *App) syncDigest(s snapshot, path string) {
+	targets := remotesync.Targets(s.config.Sync)
+	if len(targets) == 0 {
+		return
+	}
+
+	var failed []store.QueuedDelivery
+	for _, target := range targets {
+		item := store.QueuedDelivery{Path: path, Target: target.Name(), QueuedAt: time.Now()}
+		if !remotesync.Reachable(context.Background(), target) {
+			log.Printf("Can't reach %s - queueing digest delivery", target.Name())
+		} else if err := uploadDigest(target, path); err != nil {
+			log.Printf("Failed to sync digest to %s (queued for retry): %v", target.Name(), err)
+			item.Attempts, item.LastError = 1, err.Error()
+		} else {
+			log.Printf("Synced digest to %s", target.Name())
+			continue
+		}
+		failed = append(failed, item)
+	}
+	if len(failed) == 0 {
+		return
+	}
+
+	a.outboxMu.Lock()
+	defer a.outboxMu.Unlock()
+	queue, err := store.LoadOutbox()
+	if err != nil {
+		log.Printf("Failed to load delivery outbox: %v", err)
+		return
+	}
+	if err := store.SaveOutbox(append(queue, failed...)); err != nil {
+		log.Printf("Failed to queue digest delivery: %v", err)
+	}
+}
+
+// uploadDigest uploads one digest to one target within syncTimeout
+func uploadDigest(target remotesync.Target, path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), syncTimeout)
+	defer cancel()
+	return target.Upload(ctx, path)
+}
+
+// FlushOutbox retries the queued digest deliveries whose target can be
+// reached. Deliveries whose file or target no longer exists are dropped;
+// those that failed maxDeliveryAttempts times are moved to the failed
+// deliveries file.
+func (a *App) FlushOutbox() {
+	a.outboxMu.Lock()
+	defer a.outboxMu.Unlock()
+
+	queue, err := store.LoadOutbox()
+	if err != nil {
+		log.Printf("Failed to load delivery outbox: %v", err)
+		return
+	}
+	if len(queue) == 0 {
+		return
+	}
+
+	targets := make(map[string]remotesync.Target)
+	for _, target := range remotesync.Targets(a.getSnapshot().config.Sync) {
+		targets[target.Name()] = target
+	}
+
+	reachable := make(map[string]bool) // By target name, probed once per flush
+	var remaining, failed []store.QueuedDelivery
+	for _, item := range queue {
+		target, ok := targets[item.Target]
+		if !ok {
+			log.Printf("Dropping queued delivery of %s: %s sync is no longer configured", item.Path, item.Target)
+			continue
+		}
+		if _, err := os.Stat(item.Path); err != nil {
+			log.Printf("Dropping queued delivery of %s: %v", item.Path, err)
+			continue
+		}
+		up, probed := reachable[item.Target]
+		if !probed {
+			up = remotesync.Reachable(context.Background(), target)
+			reachable[item.Target] = up
+		}
+		if !up {
+			remaining = append(remaining, item)
+			continue
+		}
+		if err := uploadDigest(target, item.Path); err != nil {
+			item.Attempts++
+			item.LastError = err.Error()
+			if item.Attempts >= maxDeliveryAttempts {
+				log.Printf("Giving up on delivering %s to %s after %d attempts: %v", item.Path, item.Target, item.Attempts, err)
+				failed = append(failed, item)
+				continue
+			}
+			log.Printf("Retry of %s to %s failed: %v", item.Path, item.Target, err)
+			remaining = append(remaining, item)
+			continue
+		}
+		log.Printf("Delivered queued digest %s to %s", item.Path, item.Target)
+	}
+
+	if len(failed) > 0 {
+		if err := store.AddFailedDeliveries(failed); err != nil {
+			log.Printf("Failed to record failed deliveries: %v", err)
+		}
+	}
+	if err := store.SaveOutbox(remaining); err != nil {
+		log.Printf("Failed to save delivery outbox: %v", err)
+	}
+}
+
+// WatchOutbox retries queued deliveries every outboxCheckInterval until ctx is done.
+func (a *App) WatchOutbox(ctx context.Context) {
+	a.FlushOutbox()
+
+	ticker := time.NewTicker(outboxCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.FlushOutbox()
+		}
+	}
+}
+
+// =============================================================================
+// Orchestration Methods
+// =============================================================================
+
+// GenerateDigest performs the full scrape -> analyze -> build digest flow.
+// If the run fails for a reason that may be transient, it's retried once
+// with fewer posts per scrape and longer timeouts, and the resulting digest
+// is marked as a reduced run.
+func (a *App) GenerateDigest() (err error) {
+	log.Println("Generate Digest triggered...")
+
+	s := a.getSnapshot()
+	if !a.IsAuthenticated() && !s.config.Scraping.GuestFallback {
+		log.Println("Not authenticated - please login to X first")
+		return nil
+	}
+
+	defer sweepOrphanedChrome()
+	ctx, done := a.runs.start(context.Background())
+	defer done()
+	run := beginRun("digest", s.config)
+	defer func() { finishRun(run, err) }()
+	s.budget = newRunBudget(s.config.Pipeline.MaxMinutes)
+
+	digestPaths, err := a.runPipeline(ctx, s, "")
+	if err != nil && retryReduced(err) {
+		reduced := reduceScope(s)
+		log.Printf("Run failed (%v) - retrying once with %d posts per scrape and %dx timeouts",
+			err, reduced.config.Scraping.PostsPerScrape, reducedRunTimeoutFactor)
+		reason := fmt.Sprintf("the full run failed (%v), so this digest was built from a smaller scrape (%d posts per source instead of %d).",
+			err, reduced.config.Scraping.PostsPerScrape, s.config.Scraping.PostsPerScrape)
+		digestPaths, err = a.runPipeline(ctx, reduced, reason)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Step 5: Open the digests in the default text editor
+	for _, path := range digestPaths {
+		a.OpenNewDigest(path)
+	}
+	return nil
+}
+
+// runPipeline runs Steps 1-4 with the given snapshot, marking the digests
+// as a reduced run if reduced is set. The scrape is analyzed once per
+// interest profile in s.profiles, or once against [interests] if there are
+// none. Returns the paths of the digests built, which is none if there was
+// nothing to put in a digest.
+func (a *App) runPipeline(ctx context.Context, s snapshot, reduced string) ([]string, error) {
+	targets := []snapshot{s}
+	if len(s.profiles) > 0 {
+		targets = nil
+		for _, name := range s.profiles {
+			ps, err := withProfile(s, name)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, ps)
+		}
+	}
+
+	// Step 1: Scrape posts
+	posts, err := a.scrapePosts(ctx, s)
+	if err != nil {
+		log.Printf("Scrape failed: %v", err)
+		return nil, err
+	}
+	if len(posts) == 0 {
+		log.Println("No posts scraped - nothing to analyze")
+		return nil, nil
+	}
+	if posts, err = breakAfterStep(ctx, s.breaks, BreakAfterScrape, store.Step1Posts, posts); err != nil {
+		return nil, err
+	}
+	extras := digestExtras{reduced: reduced, learning: newRunLearning()}
+	defer extras.learning.save()
+	if s.config.Scraping.IncludeTrends && s.budget.allow("trends") {
+		extras.trending = a.scrapeTrending(ctx, s)
+	}
+
+	var digestPaths []string
+	for _, t := range targets {
+		if t.profile != "" {
+			log.Printf("Analyzing for interest profile %q...", t.profile)
+		}
+		digestPath, err := a.digestPosts(ctx, t, posts, extras)
+		if err != nil {
+			return nil, err
+		}
+		if digestPath != "" {
+			digestPaths = append(digestPaths, digestPath)
+		}
+	}
+	return digestPaths, nil
+}
+
+// digestPosts runs Steps 2-4 on scraped posts with the given snapshot.
+// Returns the digest path, or "" if there was nothing to put in a digest.
+func (a *App) digestPosts(ctx context.Context, s snapshot, posts []types.Post, extras digestExtras) (string, error) {
+	var err error
+	if s.config.Interests.Empty() {
+		log.Println("No interests configured - finding candidate topics in the feed")
+		if extras.suggestedTopics, err = s.analyzer.SuggestTopics(ctx, posts); err != nil {
+			log.Printf("Failed to suggest topics: %v", err)
+		}
+	}
+
+	// Step 2: Analyze posts with LLM, leaving out muted ones
+	scraped := len(posts)
+	posts, extras.muted = dropMuted(s.config.Interests, posts)
+	analyses, err := a.analyzePosts(ctx, s, posts)
+	if err != nil {
+		log.Printf("Analysis failed: %v", err)
+		return "", err
+	}
+	if analyses, err = breakAfterStep(ctx, s.breaks, BreakAfterAnalyze, store.Step2Analyses, analyses); err != nil {
+		return "", err
+	}
+
+	// Step 3: Filter by relevance threshold
+	relevantPosts := a.filterByRelevance(s, posts, analyses, s.config.Analysis.RelevanceThreshold)
+	extras.learning.addFiltered(posts, analyses, relevantPosts)
+	if len(relevantPosts) == 0 {
+		log.Println("No posts above relevance threshold - no digest generated")
+		return "", nil
+	}
+	if relevantPosts, err = breakAfterStep(ctx, s.breaks, BreakAfterFilter, store.Step3Filtered, relevantPosts); err != nil {
+		return "", err
+	}
+	notifyUrgent(ctx, s, relevantPosts)
+	if s.config.Analysis.EnrichAuthors && s.budget.allow("author profiles") {
+		a.enrichAuthors(ctx, s, relevantPosts)
+	}
+	if s.config.Analysis.SummarizeDiscussions && s.budget.allow("discussion summaries") {
+		a.summarizeDiscussions(ctx, s, relevantPosts)
+	}
+	if s.config.Digest.GroupByTopic && s.budget.allow("topic sections") {
+		groupIntoSections(ctx, s, relevantPosts)
+	}
+
+	// Step 4: Build and save digest
+	digestPath, err := a.buildDigest(s, relevantPosts, scraped, s.config.Digest.MaxPosts, extras)
+	if err != nil {
+		log.Printf("Failed to build digest: %v", err)
+		return "", err
+	}
+	if _, err := s.breaks.pauseAt(ctx, BreakAfterDigest, digestPath); err != nil {
+		return "", err
+	}
+	return digestPath, nil
+}
+
+// scrapeTrending scrapes X's Trending list, caches it to step1_trends, and
+// has the LLM put it in context. Returns nil if the trends couldn't be
+// scraped; if only the summary fails, the trends are listed without it.
+func (a *App) scrapeTrending(ctx context.Context, s snapshot) *digest.Trending {
+	cookies, err := a.sessionCookies(s)
+	if err != nil {
+		log.Printf("No X session (%v) - reading trends logged out", err)
+		cookies = nil
+	}
+
+	log.Println("Scraping trends...")
+	trends, err := s.scraper.ScrapeTrends(ctx, cookies)
+	if err != nil {
+		log.Printf("Failed to scrape trends: %v", err)
+		return nil
+	}
+	if len(trends) == 0 {
+		log.Println("No trends found")
+		return nil
+	}
+	if cachePath, err := store.SaveStepOutput(store.Step1Trends, trends); err != nil {
+		log.Printf("Failed to cache trends: %v", err)
+	} else {
+		log.Printf("Cached trends to: %s", cachePath)
+	}
+
+	trending := &digest.Trending{Trends: trends}
+	if trending.Summary, err = s.analyzer.SummarizeTrends(ctx, trends); err != nil {
+		log.Printf("Failed to summarize trends: %v", err)
+	}
+	return trending
+}
+
+// retryReduced reports whether a failed run is worth retrying with reduced
+// scope: only when the feed timed out, loading or before it gave enough
+// posts. Other failures (the LLM, the session, the config, the disk) won't
+// go away by asking for less.
+func retryReduced(err error) bool {
+	return errors.Is(err, scraper.ErrScrapeTimeout) || errors.Is(err, scraper.ErrTooFewPosts)
+}
+
+// reduceScope returns a copy of s that scrapes fewer posts per source with
+// longer timeouts
+func reduceScope(s snapshot) snapshot {
+	cfg := *s.config
+	cfg.Scraping.PostsPerScrape = max(cfg.Scraping.PostsPerScrape/reducedRunPostsDivisor, 1)
+	s.config = &cfg
+	s.scraper = s.scraper.WithLongerTimeouts(reducedRunTimeoutFactor)
+	return s
+}
+
+// FocusDigest builds and opens a micro-digest sized to be read in about the
+// given number of minutes, for a bounded check-in instead of opening X. It
+// picks the best of the posts analyzed within focusFreshness, or of a quick
+// feed scrape if there are none or scrape is set. Mentions are left for the
+// full digest.
+func (a *App) FocusDigest(ctx context.Context, minutes int, scrape bool) (err error) {
+	defer sweepOrphanedChrome()
+	ctx, done := a.runs.start(ctx)
+	defer done()
+	s := a.getSnapshot()
+	run := beginRun("focus", s.config)
+	defer func() { finishRun(run, err) }()
+
+	var posts []types.Post
+	var analyses []types.Analysis
+	if !scrape {
+		var err error
+		since := time.Now().Add(-focusFreshness)
+		if posts, err = stepOutputsSince[types.Post](store.Step1Posts, since); err != nil {
+			return fmt.Errorf("failed to load cached posts: %w", err)
+		}
+		if analyses, err = stepOutputsSince[types.Analysis](store.Step2Analyses, since); err != nil {
+			return fmt.Errorf("failed to load cached analyses: %w", err)
+		}
+		// Later runs may have seen a post again; keep its latest copy
+		slices.Reverse(posts)
+		posts = mergePosts(nil, posts)
+	}
+	if len(analyses) == 0 {
+		log.Printf("No posts analyzed in the last %s - scraping %d feed posts", focusFreshness, focusScrapePosts)
+		quick := quickScope(s)
+		var err error
+		if posts, err = a.scrapePosts(ctx, quick); err != nil {
+			return err
+		}
+		if len(posts) == 0 {
+			return errors.New("no posts scraped")
+		}
+		posts, _ = dropMuted(s.config.Interests, posts)
+		if analyses, err = a.analyzePosts(ctx, quick, posts); err != nil {
+			return err
+		}
+	}
+
+	var relevantPosts []types.PostWithAnalysis
+	for _, p := range a.filterByRelevance(s, posts, analyses, s.config.Analysis.RelevanceThreshold) {
+		if p.Post.Source != types.SourceMentions {
+			relevantPosts = append(relevantPosts, p)
+		}
+	}
+	if len(relevantPosts) == 0 {
+		return fmt.Errorf("no recent posts above relevance threshold (%.0f%%)", s.config.Analysis.RelevanceThreshold*100)
+	}
+
+	budget := time.Duration(minutes) * time.Minute
+	digestPath, err := a.buildDigest(s, relevantPosts, len(posts), len(relevantPosts), digestExtras{focus: budget})
+	if err != nil {
+		return err
+	}
+	a.OpenNewDigest(digestPath)
+	return nil
+}
+
+// quickScope returns a copy of s that reads at most focusScrapePosts posts
+// from the home feed alone, without unrolling threads
+func quickScope(s snapshot) snapshot {
+	cfg := *s.config
+	cfg.Scraping.PostsPerScrape = min(cfg.Scraping.PostsPerScrape, focusScrapePosts)
+	cfg.Scraping.Lists = nil
+	cfg.Scraping.Communities = nil
+	cfg.Scraping.Profiles = nil
+	cfg.Scraping.Searches = nil
+	cfg.Scraping.IncludeMentions = false
+	cfg.Scraping.UnrollThreads = false
+	s.config = &cfg
+	return s
+}
+
+// GenerateHeadlines performs a fast scrape -> select -> build digest flow
+// that skips LLM analysis entirely.
+func (a *App) GenerateHeadlines() (err error) {
+	log.Println("Generate Headlines triggered...")
+
+	s := a.getSnapshot()
+	if !a.IsAuthenticated() && !s.config.Scraping.GuestFallback {
+		log.Println("Not authenticated - please login to X first")
+		return nil
+	}
+
+	defer sweepOrphanedChrome()
+	ctx, done := a.runs.start(context.Background())
+	defer done()
+	run := beginRun("headlines", s.config)
+	defer func() { finishRun(run, err) }()
+
+	posts, err := a.ScrapePosts(ctx)
+	if err != nil {
+		log.Printf("Scrape failed: %v", err)
+		return err
+	}
+
+	headlines := a.SelectHeadlines(posts)
+	if len(headlines) == 0 {
+		log.Println("No posts matched headline criteria - no digest generated")
+		return nil
+	}
+
+	digestPath, err := a.BuildDigest(headlines, len(posts))
+	if err != nil {
+		log.Printf("Failed to build digest: %v", err)
+		return err
+	}
+
+	a.OpenNewDigest(digestPath)
+	return nil
+}
+
+// RegenerateDigest re-runs Steps 3-4 against the latest cached posts and
+// analyses, so a digest can be rebuilt with different parameters without
+// re-scraping or re-analyzing. A zero threshold or maxPosts uses the
+// configured value. Returns the path to the new digest file.
+func (a *App) RegenerateDigest(threshold float64, maxPosts int) (string, error) {
+	s := a.getSnapshot()
+	if threshold == 0 {
+		threshold = s.config.Analysis.RelevanceThreshold
+	}
+	if maxPosts == 0 {
+		maxPosts = s.config.Digest.MaxPosts
+	}
+
+	posts, postsPath, err := store.LoadLatestStepOutput[[]types.Post](store.Step1Posts)
+	if err != nil {
+		return "", fmt.Errorf("failed to load cached posts: %w", err)
+	}
+	analyses, analysesPath, err := store.LoadLatestStepOutput[[]types.Analysis](store.Step2Analyses)
+	if err != nil {
+		return "", fmt.Errorf("failed to load cached analyses: %w", err)
+	}
+	log.Printf("Regenerating digest from %s and %s (threshold %.0f%%, max %d posts)",
+		postsPath, analysesPath, threshold*100, maxPosts)
+
+	relevantPosts := a.filterByRelevance(s, posts, analyses, threshold)
+	if len(relevantPosts) == 0 {
+		return "", fmt.Errorf("no cached posts above relevance threshold (%.0f%%)", threshold*100)
+	}
+
+	return a.buildDigest(s, relevantPosts, len(posts), maxPosts, digestExtras{})
+}
+
+// RerenderDigests re-renders archived digests created since the given time
+// using the current digest format. Each digest is rebuilt from the filtered
+// posts cached at or before its creation time and overwritten in place; one
+// saved in the other file format (markdown or text) gets a new file beside it.
+// Returns the number of digests re-rendered.
+func (a *App) RerenderDigests(since time.Time) (int, error) {
+	s := a.getSnapshot()
+	builder := digest.New(s.config.Digest.OutputDir, s.config.Digest.MaxPosts)
+	builder.SetGroupByCommunity(s.config.Digest.GroupByCommunity)
+	builder.SetGroupByTopic(s.config.Digest.GroupByTopic)
+	setFeedbackLinks(builder, s.config.Digest.FeedbackPort)
+	builder.SetArticleExcerpts(s.config.Digest.ArticleExcerpts)
+	if s.config.Digest.Format == config.FormatText {
+		builder.SetPlainText(s.config.Digest.TextWidth)
+	}
+	ranker, err := ranking.New(s.config.Digest)
+	if err != nil {
+		return 0, err
+	}
+
+	digests, err := digest.ListDigests(s.config.Digest.OutputDir, since)
+	if err != nil {
+		return 0, err
+	}
+
+	var rendered int
+	for _, d := range digests {
+		filteredPath, err := store.StepFileBefore(store.Step3Filtered, d.CreatedAt)
+		if err != nil {
+			log.Printf("Skipping %s: %v", d.FilePath, err)
+			continue
+		}
+		filtered, err := store.LoadStepOutput[[]types.PostWithAnalysis](filteredPath)
+		if err != nil {
+			log.Printf("Skipping %s: %v", d.FilePath, err)
+			continue
+		}
+		if len(filtered) == 0 {
+			log.Printf("Skipping %s: no filtered posts in %s", d.FilePath, filteredPath)
+			continue
+		}
+
+		// Best effort: recover the scraped and muted counts from the
+		// matching step1 cache, as muted by the current config
+		totalScraped, muted := len(filtered), 0
+		if postsPath, err := store.StepFileBefore(store.Step1Posts, d.CreatedAt); err == nil {
+			if posts, err := store.LoadStepOutput[[]types.Post](postsPath); err == nil {
+				interests := s.config.Interests
+				if profile, ok := s.config.Profiles[d.Profile]; ok {
+					interests = profile.Interests
+				}
+				totalScraped = len(posts)
+				muted = countMuted(interests, posts)
+			}
+		}
+
+		builder.SetProfile(d.Profile)
+		builder.SetMuted(muted)
+		content, err := builder.RenderAt(ranker.Rank(filtered), totalScraped, d.CreatedAt)
+		if err != nil {
+			log.Printf("Skipping %s: %v", d.FilePath, err)
+			continue
+		}
+		if _, err := builder.Save(content); err != nil {
+			return rendered, err
+		}
+		log.Printf("Re-rendered %s from %s", d.FilePath, filteredPath)
+		rendered++
+	}
+
+	log.Printf("Re-rendered %d of %d digests", rendered, len(digests))
+	return rendered, nil
+}
+
+// OpenNewDigest opens a digest that was just generated, and records that
+// it was shown. That doesn't mark it read; only the user opening it does
+// (see ViewLastDigest). Failing to open it is logged, not returned, since
+// the digest itself was built.
+func (a *App) OpenNewDigest(path string) {
+	if err := browser.OpenFile(path); err != nil {
+		log.Printf("Failed to open digest: %v", err)
+		return
+	}
+	if err := store.RecordUsage(store.UsageDigestShown, path); err != nil {
+		log.Printf("Failed to record digest open: %v", err)
+	}
+}
+
+// ViewLastDigest opens the most recent digest file.
+func (a *App) ViewLastDigest() error {
+	s := a.getSnapshot()
+
+	path, err := digest.GetLatestDigest(s.config.Digest.OutputDir)
+	if err != nil {
+		log.Printf("No digest found: %v", err)
+		return err
+	}
+
+	log.Printf("Opening digest: %s", path)
+	if err := store.RecordUsage(store.UsageDigestOpened, path); err != nil {
+		log.Printf("Failed to record digest open: %v", err)
+	}
+	return browser.OpenFile(path)
+}
+
+// recordConfigChanges compares cfg with the settings seen last time and
+// adds any differences to the config audit trail. The first call only
+// records a baseline.
+func recordConfigChanges(cfg *config.Config) {
+	settings, err := cfg.Settings()
+	if err != nil {
+		log.Printf("Failed to read config settings: %v", err)
+		return
+	}
+	history, err := store.LoadConfigHistory()
+	if err != nil {
+		log.Printf("Failed to load config history: %v", err)
+		return
+	}
+
+	if history.Settings != nil {
+		changes := config.DiffSettings(history.Settings, settings)
+		if len(changes) == 0 {
+			return
+		}
+		log.Printf("Config changed since last run: %s", strings.Join(changes, "; "))
+		history.Record(store.ConfigChange{At: time.Now(), Changes: changes})
+	}
+	history.Settings = settings
+	if err := store.SaveConfigHistory(history); err != nil {
+		log.Printf("Failed to save config history: %v", err)
+	}
+}
+
+// ViewGraph renders the topics-over-time and author co-occurrence page from
+// analyses cached since the given time, and opens it.
+func (a *App) ViewGraph(since time.Time) error {
+	posts, err := stepOutputsSince[types.Post](store.Step1Posts, since)
+	if err != nil {
+		return err
+	}
+	analyses, err := stepOutputsSince[types.Analysis](store.Step2Analyses, since)
+	if err != nil {
+		return err
+	}
+
+	page, err := insights.RenderHTML(insights.Build(posts, analyses, since))
+	if err != nil {
+		return fmt.Errorf("failed to render graph page: %w", err)
+	}
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(cacheDir, graphPageFile)
+	if err := os.WriteFile(path, page, 0644); err != nil {
+		return err
+	}
+
+	log.Printf("Opening graph view: %s", path)
+	return browser.OpenFile(path)
+}
+
+// stepOutputsSince concatenates the cached outputs of a step written since
+// the given time. Unreadable files are logged and skipped.
+func stepOutputsSince[T any](step store.StepName, since time.Time) ([]T, error) {
+	files, err := store.StepFiles(step)
+	if err != nil {
+		return nil, err
+	}
+	var all []T
+	for _, file := range files {
+		if t, err := store.StepFileTime(file); err != nil || t.Before(since) {
+			continue
+		}
+		items, err := store.LoadStepOutput[[]T](file)
+		if err != nil {
+			log.Printf("Skipping %s: %v", file, err)
+			continue
+		}
+		all = append(all, items...)
+	}
+	return all, nil
+}
+
+// ReloadConfig reloads the configuration from disk. The new config is
+// validated first and only swapped in if it has no problems.
+func (a *App) ReloadConfig() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("%w:\n%w", ErrConfigInvalid, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%w:\n%w", ErrConfigInvalid, err)
+	}
+
+	// Recreate analyzer with new config
+	newAnalyzer, err := analyzer.New(cfg.Analysis, cfg.Interests)
+	if err != nil {
+		return err
+	}
+
+	recordConfigChanges(cfg)
+
+	newScraper := scraper.FromConfig(cfg.Scraping)
+
+	a.mu.Lock()
+	if a.replayDir != "" {
+		newScraper = newScraper.WithReplay(a.replayDir)
+	}
 	a.config = cfg
 	a.analyzer = newAnalyzer
-	a.scraper = scraper.New(cfg.Scraping.Headless, cfg.Scraping.DebugPauseAfterScrape)
+	a.scraper = newScraper
 	a.mu.Unlock()
 
 	log.Println("Configuration reloaded")
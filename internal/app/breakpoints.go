@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ibeckermayer/scroll4me/internal/store"
+)
+
+// Steps of a full run that a breakpoint can follow
+const (
+	BreakAfterScrape  = "scrape"
+	BreakAfterAnalyze = "analyze"
+	BreakAfterFilter  = "filter"
+	BreakAfterDigest  = "digest"
+)
+
+// BreakpointSteps lists the steps a breakpoint can follow, in run order
+var BreakpointSteps = []string{BreakAfterScrape, BreakAfterAnalyze, BreakAfterFilter, BreakAfterDigest}
+
+// ErrStoppedAtBreakpoint is returned by a run stopped at a breakpoint
+var ErrStoppedAtBreakpoint = errors.New("stopped at breakpoint")
+
+// Breakpoint pauses a full run after a step. It's given the step and the
+// file the step's output was saved to, and the run continues when it
+// returns. An error stops the run.
+type Breakpoint func(ctx context.Context, step, path string) error
+
+// breakpoints holds where a full run pauses
+type breakpoints struct {
+	after map[string]bool
+	pause Breakpoint
+}
+
+// SetBreakpoints makes full runs call pause after each of steps (see
+// BreakpointSteps). If the step's output file is edited during the pause,
+// the run continues with the edited data. No steps clears them.
+func (a *App) SetBreakpoints(steps []string, pause Breakpoint) error {
+	after := make(map[string]bool, len(steps))
+	for _, step := range steps {
+		step = strings.TrimSpace(step)
+		if step == "" {
+			continue
+		}
+		if !slices.Contains(BreakpointSteps, step) {
+			return fmt.Errorf("unknown step %q to break after (use %s)", step, strings.Join(BreakpointSteps, ", "))
+		}
+		after[step] = true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.breaks = nil
+	if len(after) > 0 {
+		a.breaks = &breakpoints{after: after, pause: pause}
+	}
+	return nil
+}
+
+// pauseAt pauses at the breakpoint after step, if there is one, showing
+// path. Reports whether the file at path was changed during the pause.
+func (b *breakpoints) pauseAt(ctx context.Context, step, path string) (edited bool, err error) {
+	if b == nil || !b.after[step] {
+		return false, nil
+	}
+
+	before, statErr := os.Stat(path)
+	log.Printf("Breakpoint after %s: %s", step, path)
+	if err := b.pause(ctx, step, path); err != nil {
+		return false, fmt.Errorf("%w after %s: %v", ErrStoppedAtBreakpoint, step, err)
+	}
+	after, err := os.Stat(path)
+	return statErr == nil && err == nil && !after.ModTime().Equal(before.ModTime()), nil
+}
+
+// breakAfterStep pauses at the breakpoint after step, if there is one, on
+// the latest output saved to output. If the file was edited during the
+// pause, its contents replace data.
+func breakAfterStep[T any](ctx context.Context, b *breakpoints, step string, output store.StepName, data T) (T, error) {
+	if b == nil || !b.after[step] {
+		return data, nil
+	}
+
+	path, err := store.LatestStepFile(output)
+	if err != nil {
+		return data, fmt.Errorf("no output to show at breakpoint after %s: %w", step, err)
+	}
+	edited, err := b.pauseAt(ctx, step, path)
+	if err != nil || !edited {
+		return data, err
+	}
+
+	log.Printf("%s was edited - continuing with its contents", path)
+	return store.LoadStepOutput[T](path)
+}
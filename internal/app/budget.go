@@ -0,0 +1,55 @@
+package app
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// runBudget is a run's time budget (pipeline.max_minutes). Once it's spent,
+// optional enrichment steps are skipped and recorded so the digest can say
+// what's missing. A nil budget never runs out.
+type runBudget struct {
+	limit    time.Duration
+	deadline time.Time
+
+	mu      sync.Mutex
+	skipped []string // In the order they were skipped
+}
+
+// newRunBudget starts a budget of maxMinutes, or returns nil if it's 0
+func newRunBudget(maxMinutes int) *runBudget {
+	if maxMinutes <= 0 {
+		return nil
+	}
+	limit := time.Duration(maxMinutes) * time.Minute
+	return &runBudget{limit: limit, deadline: time.Now().Add(limit)}
+}
+
+// allow reports whether the optional step may still run. If the budget is
+// spent, the step is recorded as skipped.
+func (b *runBudget) allow(step string) bool {
+	if b == nil || time.Now().Before(b.deadline) {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.skipped {
+		if s == step {
+			return false
+		}
+	}
+	log.Printf("Run is over its %s budget - skipping %s", b.limit, step)
+	b.skipped = append(b.skipped, step)
+	return false
+}
+
+// skippedSteps returns the steps skipped so far
+func (b *runBudget) skippedSteps() []string {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.skipped...)
+}
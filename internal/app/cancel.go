@@ -0,0 +1,87 @@
+package app
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// pipelineStopTimeout bounds how long CancelPipeline waits for cancelled
+// runs to close their browsers
+const pipelineStopTimeout = 15 * time.Second
+
+// pipelineRuns tracks the runs in progress so they can be cancelled
+// together. The zero value is ready to use.
+type pipelineRuns struct {
+	mu      sync.Mutex
+	cancels map[int]context.CancelFunc
+	nextID  int
+	wg      sync.WaitGroup
+}
+
+// start returns a context for a run, derived from parent, that cancelAll
+// cancels. The run must call done when it returns.
+func (r *pipelineRuns) start(parent context.Context) (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(parent)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancels == nil {
+		r.cancels = make(map[int]context.CancelFunc)
+	}
+	id := r.nextID
+	r.nextID++
+	r.cancels[id] = cancel
+	r.wg.Add(1)
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.cancels, id)
+		r.mu.Unlock()
+		cancel()
+		r.wg.Done()
+	}
+}
+
+// cancelAll cancels every run in progress and waits up to timeout for them
+// to return. Reports the number cancelled and whether they all returned.
+func (r *pipelineRuns) cancelAll(timeout time.Duration) (int, bool) {
+	r.mu.Lock()
+	n := len(r.cancels)
+	for _, cancel := range r.cancels {
+		cancel()
+	}
+	r.mu.Unlock()
+	if n == 0 {
+		return 0, true
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return n, true
+	case <-time.After(timeout):
+		return n, false
+	}
+}
+
+// CancelPipeline stops the digest, headlines, and focus runs in progress:
+// scrapes stop scrolling and close Chrome, and LLM requests are abandoned.
+// It waits for the runs to wind down, up to pipelineStopTimeout, so that
+// quitting right after doesn't leave a headless Chrome behind. It does
+// nothing if no run is in progress.
+func (a *App) CancelPipeline() {
+	n, stopped := a.runs.cancelAll(pipelineStopTimeout)
+	switch {
+	case n == 0:
+	case stopped:
+		log.Printf("Cancelled %d pipeline runs", n)
+	default:
+		log.Printf("Cancelled %d pipeline runs, but not all stopped within %s", n, pipelineStopTimeout)
+	}
+}
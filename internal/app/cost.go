@@ -0,0 +1,102 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/analyzer/providers"
+	"github.com/ibeckermayer/scroll4me/internal/store"
+)
+
+// CostReport sums up the tokens LLM calls used since a given time, and what
+// they cost at list prices
+type CostReport struct {
+	Since    time.Time
+	Runs     []RunCost // Oldest first
+	Other    RunCost   // Calls made outside a run, e.g. by `step analyze`
+	Total    RunCost
+	Unpriced []string // Models without a known price; their tokens count, their cost doesn't
+}
+
+// RunCost is the LLM usage of one run, or of a group of calls
+type RunCost struct {
+	Run          string // Run ID, e.g. "2026-10-15T08-00-01"
+	Kind         string // e.g. "digest"; empty if the run's manifest is gone
+	Calls        int
+	InputTokens  int64 // Including those written to and read from the prompt cache
+	CachedTokens int64 // Input tokens read from the prompt cache
+	OutputTokens int64
+	Cost         float64 // US dollars, of the calls with a known price
+}
+
+// add counts a call in c
+func (c *RunCost) add(u store.TokenUsage, cost float64) {
+	c.Calls++
+	c.InputTokens += u.InputTokens + u.CacheWriteTokens + u.CacheReadTokens
+	c.CachedTokens += u.CacheReadTokens
+	c.OutputTokens += u.OutputTokens
+	c.Cost += cost
+}
+
+// AverageCost returns the average cost of the report's runs of the given
+// kind, and how many there were
+func (r *CostReport) AverageCost(kind string) (float64, int) {
+	var total float64
+	n := 0
+	for _, run := range r.Runs {
+		if run.Kind == kind {
+			total += run.Cost
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, 0
+	}
+	return total / float64(n), n
+}
+
+// CostReport totals the token usage logged since the given time by run,
+// pricing each call at its model's list price.
+func (a *App) CostReport(since time.Time) (*CostReport, error) {
+	usage, err := store.LoadTokenUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load token usage: %w", err)
+	}
+
+	report := &CostReport{Since: since}
+	runs := make(map[string]*RunCost)
+	for _, u := range usage {
+		if u.At.Before(since) {
+			continue
+		}
+		price, ok := providers.PriceOf(u.Model)
+		if !ok && !slices.Contains(report.Unpriced, u.Model) {
+			report.Unpriced = append(report.Unpriced, u.Model)
+		}
+		cost := price.Cost(u.InputTokens, u.OutputTokens) + price.CacheCost(u.CacheWriteTokens, u.CacheReadTokens)
+		report.Total.add(u, cost)
+
+		if u.Run == "" {
+			report.Other.add(u, cost)
+			continue
+		}
+		run, ok := runs[u.Run]
+		if !ok {
+			run = &RunCost{Run: u.Run}
+			if m, err := store.LoadRunManifest(u.Run); err == nil {
+				run.Kind = m.Kind
+			}
+			runs[u.Run] = run
+		}
+		run.add(u, cost)
+	}
+
+	for _, run := range runs {
+		report.Runs = append(report.Runs, *run)
+	}
+	// Run IDs are start times, so they sort chronologically
+	slices.SortFunc(report.Runs, func(a, b RunCost) int { return cmp.Compare(a.Run, b.Run) })
+	return report, nil
+}
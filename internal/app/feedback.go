@@ -0,0 +1,198 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/digest"
+	"github.com/ibeckermayer/scroll4me/internal/ranking"
+	"github.com/ibeckermayer/scroll4me/internal/store"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// feedbackSmoothing is added to the rating count behind a prior, so a
+// single rating moves scores a third of the way a unanimous crowd does
+const feedbackSmoothing = 2
+
+// feedbackURL returns the base URL digest rating links point to, or "" if
+// the feedback server is off
+func feedbackURL(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return fmt.Sprintf("http://127.0.0.1:%d", port)
+}
+
+// setFeedbackLinks gives the builder's posts rating links if the feedback
+// server is on. Without the install's token the links are left out, since
+// the server would refuse them.
+func setFeedbackLinks(builder *digest.Builder, port int) {
+	if port == 0 {
+		return
+	}
+	token, err := store.FeedbackToken()
+	if err != nil {
+		log.Printf("Leaving rating links out of the digest, no feedback token: %v", err)
+		return
+	}
+	builder.SetFeedbackURL(feedbackURL(port), token)
+}
+
+// ServeFeedback serves the rating links in digests on the configured
+// localhost port until ctx is done. Does nothing if digest.feedback_port
+// isn't set; failures are logged.
+func (a *App) ServeFeedback(ctx context.Context) {
+	port := a.getSnapshot().config.Digest.FeedbackPort
+	if port == 0 {
+		return
+	}
+	token, err := store.FeedbackToken()
+	if err != nil {
+		log.Printf("Failed to start feedback server, no feedback token: %v", err)
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /rate", func(w http.ResponseWriter, r *http.Request) {
+		a.handleRate(w, r, token)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		log.Printf("Failed to start feedback server: %v", err)
+		return
+	}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	log.Printf("Serving digest rating links at %s", feedbackURL(port))
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("Feedback server stopped: %v", err)
+	}
+}
+
+// handleRate rates the post in the "post" parameter as wanted or not,
+// per the "vote" parameter ("up" or "down"), and says so in a short page.
+// Requests without the install's token in the "token" parameter are
+// refused, so another page or program can't rate posts through it.
+func (a *App) handleRate(w http.ResponseWriter, r *http.Request, token string) {
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("token")), []byte(token)) != 1 {
+		http.Error(w, "missing or wrong token; use the rating links in a digest", http.StatusForbidden)
+		return
+	}
+	var good bool
+	switch vote := r.URL.Query().Get("vote"); vote {
+	case "up":
+		good = true
+	case "down":
+	default:
+		http.Error(w, fmt.Sprintf("unknown vote %q (use up or down)", vote), http.StatusBadRequest)
+		return
+	}
+
+	rating, err := a.RatePost(r.URL.Query().Get("post"), good, "")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	verdict := "👎 Less like this"
+	if good {
+		verdict = "👍 More like this"
+	}
+	log.Printf("Rated post %s by @%s from digest: %s", rating.PostID, rating.AuthorHandle, verdict)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html><title>scroll4me</title><p>%s: post by @%s. Later digests will take it into account; you can close this tab.</p>",
+		verdict, html.EscapeString(rating.AuthorHandle))
+}
+
+// feedbackPriors are how the user rated posts, aggregated by author and by
+// interest keyword. Each prior runs from -1 (every rating down) to 1
+// (every rating up), shrunk toward 0 while ratings are few.
+type feedbackPriors struct {
+	authors  map[string]float64 // By normalized handle
+	keywords map[string]float64 // By lowercased interest keyword
+}
+
+// loadFeedbackPriors aggregates the stored ratings. Returns nil if there
+// are none or they can't be read.
+func loadFeedbackPriors(keywords []config.Keyword) *feedbackPriors {
+	ratings, err := store.LoadRatings()
+	if err != nil {
+		log.Printf("Failed to load ratings: %v", err)
+		return nil
+	}
+	if len(ratings) == 0 {
+		return nil
+	}
+
+	type tally struct{ up, down int }
+	authors := make(map[string]*tally)
+	matched := make(map[string]*tally)
+	count := func(tallies map[string]*tally, key string, r types.RatedPost) {
+		t, ok := tallies[key]
+		if !ok {
+			t = &tally{}
+			tallies[key] = t
+		}
+		if r.Good {
+			t.up++
+		} else {
+			t.down++
+		}
+	}
+	for _, r := range ratings {
+		count(authors, normalizeHandle(r.AuthorHandle), r)
+		words := ranking.Tokens(r.Content)
+		for _, k := range keywords {
+			if ranking.HasPhrase(r.Content, words, k.Keyword) {
+				count(matched, strings.ToLower(k.Keyword), r)
+			}
+		}
+	}
+
+	prior := func(t *tally) float64 {
+		return float64(t.up-t.down) / float64(t.up+t.down+feedbackSmoothing)
+	}
+	priors := &feedbackPriors{authors: make(map[string]float64), keywords: make(map[string]float64)}
+	for handle, t := range authors {
+		priors.authors[handle] = prior(t)
+	}
+	for keyword, t := range matched {
+		priors.keywords[keyword] = prior(t)
+	}
+	return priors
+}
+
+// adjustment returns how far weight moves the relevance of post: by its
+// author's prior, plus the average prior of the rated keywords it matches
+func (f *feedbackPriors) adjustment(post types.Post, analysis *types.Analysis, weight float64) float64 {
+	if f == nil {
+		return 0
+	}
+	adjust := f.authors[normalizeHandle(post.AuthorHandle)]
+
+	text := matchText(post, analysis)
+	var sum float64
+	n := 0
+	for keyword, prior := range f.keywords {
+		if text.has(keyword) {
+			sum += prior
+			n++
+		}
+	}
+	if n > 0 {
+		adjust += sum / float64(n)
+	}
+	return weight * adjust
+}
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"log"
+	"strings"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/ranking"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// mutes matches posts against the muted accounts and keywords of a set of
+// interests, so they can be dropped without asking the LLM
+type mutes struct {
+	accounts map[string]bool // By normalized handle
+	keywords []string
+}
+
+// newMutes returns the mutes of interests
+func newMutes(interests config.InterestsConfig) mutes {
+	m := mutes{accounts: make(map[string]bool, len(interests.MutedAccounts))}
+	for _, handle := range interests.MutedAccounts {
+		m.accounts[normalizeHandle(handle)] = true
+	}
+	for _, k := range interests.MutedKeywords {
+		if k = strings.TrimSpace(k); k != "" {
+			m.keywords = append(m.keywords, k)
+		}
+	}
+	return m
+}
+
+// match reports whether post is by a muted account, or mentions a muted
+// keyword in its text or the post it quotes. Keywords match whole words, so
+// muting "ai" leaves "said" alone. Mentions are only matched by account,
+// since they get their own digest section regardless of relevance.
+func (m mutes) match(post types.Post) bool {
+	if m.accounts[normalizeHandle(post.AuthorHandle)] {
+		return true
+	}
+	if len(m.keywords) == 0 || post.Source == types.SourceMentions {
+		return false
+	}
+	text := post.Content
+	if post.QuotedPost != nil {
+		text += "\n" + post.QuotedPost.Content
+	}
+	tokens := ranking.Tokens(text)
+	for _, k := range m.keywords {
+		if ranking.HasPhrase(text, tokens, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// dropMuted returns the posts that match none of the mutes of interests,
+// and how many it dropped
+func dropMuted(interests config.InterestsConfig, posts []types.Post) ([]types.Post, int) {
+	m := newMutes(interests)
+	if len(m.accounts) == 0 && len(m.keywords) == 0 {
+		return posts, 0
+	}
+	kept := posts[:0:0]
+	for _, post := range posts {
+		if !m.match(post) {
+			kept = append(kept, post)
+		}
+	}
+	if dropped := len(posts) - len(kept); dropped > 0 {
+		log.Printf("Dropped %d muted posts before analysis", dropped)
+	}
+	return kept, len(posts) - len(kept)
+}
+
+// countMuted returns how many of posts match the mutes of interests
+func countMuted(interests config.InterestsConfig, posts []types.Post) int {
+	m := newMutes(interests)
+	n := 0
+	for _, post := range posts {
+		if m.match(post) {
+			n++
+		}
+	}
+	return n
+}
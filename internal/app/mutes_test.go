@@ -0,0 +1,57 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+func TestMutesMatchWholeWords(t *testing.T) {
+	m := newMutes(config.InterestsConfig{
+		MutedKeywords: []string{"ai", "war", "crypto pump", "🚀"},
+		MutedAccounts: []string{"@SpamBot"},
+	})
+
+	tests := []struct {
+		name string
+		post types.Post
+		want bool
+	}{
+		{"keyword inside said", types.Post{Content: "She said it was fine"}, false},
+		{"keyword inside email", types.Post{Content: "Check your email"}, false},
+		{"keyword inside software", types.Post{Content: "New software release"}, false},
+		{"keyword inside award", types.Post{Content: "They won an award"}, false},
+		{"whole word", types.Post{Content: "AI is eating the world"}, true},
+		{"word before punctuation", types.Post{Content: "Thoughts on the war."}, true},
+		{"phrase", types.Post{Content: "Another Crypto pump incoming"}, true},
+		{"phrase words apart", types.Post{Content: "crypto is not a pump"}, false},
+		{"emoji", types.Post{Content: "To the moon 🚀🚀"}, true},
+		{"quoted post", types.Post{Content: "lol", QuotedPost: &types.Post{Content: "AI will do it"}}, true},
+		{"muted account", types.Post{AuthorHandle: "spambot", Content: "hello"}, true},
+		{"mention by keyword", types.Post{Content: "AI question for you", Source: types.SourceMentions}, false},
+		{"mention by muted account", types.Post{AuthorHandle: "SpamBot", Source: types.SourceMentions}, true},
+	}
+	for _, tt := range tests {
+		if got := m.match(tt.post); got != tt.want {
+			t.Errorf("%s: match(%q) = %v, want %v", tt.name, tt.post.Content, got, tt.want)
+		}
+	}
+}
+
+func TestDropMuted(t *testing.T) {
+	interests := config.InterestsConfig{MutedKeywords: []string{"war"}}
+	posts := []types.Post{
+		{ID: "1", Content: "An award for the team"},
+		{ID: "2", Content: "War news"},
+		{ID: "3", Content: "Software update"},
+	}
+
+	kept, dropped := dropMuted(interests, posts)
+	if dropped != 1 || len(kept) != 2 || kept[0].ID != "1" || kept[1].ID != "3" {
+		t.Errorf("dropMuted kept %v, dropped %d; want posts 1 and 3, dropped 1", kept, dropped)
+	}
+	if n := countMuted(interests, posts); n != 1 {
+		t.Errorf("countMuted = %d, want 1", n)
+	}
+}
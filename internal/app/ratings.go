@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/store"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// maxCalibrationExamples caps the rated posts shown in each analysis
+// prompt, split evenly between wanted and unwanted ones where there are
+// enough of both
+const maxCalibrationExamples = 6
+
+// postIDPattern matches a post ID, alone or in a status URL
+var postIDPattern = regexp.MustCompile(`^(?:\d+|.*/status/(\d+).*)$`)
+
+// postID returns the post ID in ref, a post ID or a status URL
+func postID(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	m := postIDPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", fmt.Errorf("%q is neither a post ID nor a post URL", ref)
+	}
+	if m[1] != "" {
+		return m[1], nil
+	}
+	return ref, nil
+}
+
+// RatePost records whether the user wanted to see a post, given by ID or
+// URL, with an optional note on why. The post must be in the scrape cache.
+// Rating a post again replaces its rating. Later analyses show the LLM the
+// most recent ratings as examples of the user's taste.
+func (a *App) RatePost(ref string, good bool, note string) (types.RatedPost, error) {
+	id, err := postID(ref)
+	if err != nil {
+		return types.RatedPost{}, err
+	}
+	post, err := findCachedPost(id)
+	if err != nil {
+		return types.RatedPost{}, err
+	}
+
+	ratings, err := store.LoadRatings()
+	if err != nil {
+		return types.RatedPost{}, fmt.Errorf("failed to load ratings: %w", err)
+	}
+	rating := types.RatedPost{
+		PostID:       post.ID,
+		AuthorHandle: post.AuthorHandle,
+		Content:      post.Content,
+		Good:         good,
+		Note:         strings.TrimSpace(note),
+		RatedAt:      time.Now(),
+	}
+	ratings = slices.DeleteFunc(ratings, func(r types.RatedPost) bool { return r.PostID == id })
+	ratings = append(ratings, rating)
+	if err := store.SaveRatings(ratings); err != nil {
+		return types.RatedPost{}, fmt.Errorf("failed to save ratings: %w", err)
+	}
+	return rating, nil
+}
+
+// RemoveRating forgets the rating of a post, given by ID or URL. Reports
+// whether it was rated.
+func (a *App) RemoveRating(ref string) (bool, error) {
+	id, err := postID(ref)
+	if err != nil {
+		return false, err
+	}
+	ratings, err := store.LoadRatings()
+	if err != nil {
+		return false, fmt.Errorf("failed to load ratings: %w", err)
+	}
+	kept := slices.DeleteFunc(slices.Clone(ratings), func(r types.RatedPost) bool { return r.PostID == id })
+	if len(kept) == len(ratings) {
+		return false, nil
+	}
+	if err := store.SaveRatings(kept); err != nil {
+		return false, fmt.Errorf("failed to save ratings: %w", err)
+	}
+	return true, nil
+}
+
+// findCachedPost looks a post up in the cached scrapes, newest first
+func findCachedPost(id string) (types.Post, error) {
+	files, err := store.StepFiles(store.Step1Posts)
+	if err != nil {
+		return types.Post{}, fmt.Errorf("failed to list cached posts: %w", err)
+	}
+	for _, file := range slices.Backward(files) {
+		posts, err := store.LoadStepOutput[[]types.Post](file)
+		if err != nil {
+			log.Printf("Skipping %s: %v", file, err)
+			continue
+		}
+		for _, p := range posts {
+			if p.ID == id {
+				return p, nil
+			}
+		}
+	}
+	return types.Post{}, fmt.Errorf("post %s isn't in the scrape cache", id)
+}
+
+// calibrationExamples picks the ratings to show in analysis prompts: the
+// most recent wanted and unwanted posts, half of each where there are
+// enough, wanted ones first
+func calibrationExamples(ratings []types.RatedPost) []types.RatedPost {
+	var good, bad []types.RatedPost
+	for _, r := range slices.Backward(ratings) {
+		if r.Good {
+			good = append(good, r)
+		} else {
+			bad = append(bad, r)
+		}
+	}
+
+	half := maxCalibrationExamples / 2
+	nGood := min(len(good), max(half, maxCalibrationExamples-len(bad)))
+	nBad := min(len(bad), maxCalibrationExamples-nGood)
+	return append(good[:nGood:nGood], bad[:nBad]...)
+}
@@ -0,0 +1,182 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/ibeckermayer/scroll4me/internal/ranking"
+	"github.com/ibeckermayer/scroll4me/internal/store"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// NearMissMargin is how far under the relevance threshold a post may score
+// and still count as a near miss in the tuning report
+const NearMissMargin = 0.15
+
+// TuningReport sums up how the interests config fared over recent runs:
+// which keywords brought posts into digests and why, which only came close,
+// and which keywords and mutes never matched anything
+type TuningReport struct {
+	Runs          int             // Runs covered
+	Posts         int             // Distinct posts analyzed in them
+	Included      int             // Of which made it into a digest
+	Threshold     float64         // Relevance threshold near misses are measured against
+	Keywords      []KeywordTuning // In config order
+	Other         KeywordTuning   // Posts matching no keyword, e.g. through custom instructions
+	MutedKeywords []MuteTuning    // In config order
+	MutedAccounts []MuteTuning    // In config order
+}
+
+// KeywordTuning is how the posts mentioning an interest keyword fared
+type KeywordTuning struct {
+	Keyword    string
+	Matched    int         // Analyzed posts mentioning it in their text or topics
+	Included   []TunedPost // Of those, the posts that made it into a digest, best first
+	NearMisses []TunedPost // The posts that scored just under the threshold, best first
+}
+
+// TunedPost is an analyzed post and the model's reason for its score
+type TunedPost struct {
+	AuthorHandle string
+	Score        float64
+	Reason       string // The analysis summary for posts analyzed before reasons were asked for
+}
+
+// MuteTuning is how often a muted keyword or account came up
+type MuteTuning struct {
+	Mute string
+	Hits int // Scraped posts it applies to
+}
+
+// TuningReport aggregates the analyses of the most recent runs that
+// analyzed posts, at most runs of them, by interest keyword. Posts seen in several runs
+// count once, as of the latest. Mentions are left out, since they make the
+// digest whatever their score.
+func (a *App) TuningReport(runs int) (*TuningReport, error) {
+	s := a.getSnapshot()
+	manifests, err := store.RecentRunManifests(runs, func(m *store.RunManifest) bool {
+		return len(m.StepOutputs[store.Step2Analyses]) > 0
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runs: %w", err)
+	}
+	if len(manifests) == 0 {
+		return nil, fmt.Errorf("no runs with analyses found")
+	}
+
+	interests := s.config.Interests
+	report := &TuningReport{
+		Runs:      len(manifests),
+		Threshold: s.config.Analysis.RelevanceThreshold,
+		Keywords:  make([]KeywordTuning, len(interests.Keywords)),
+	}
+	for i, k := range interests.Keywords {
+		report.Keywords[i].Keyword = k.Keyword
+	}
+	mutedKeywords := make([]int, len(interests.MutedKeywords))
+	mutedAccounts := make([]int, len(interests.MutedAccounts))
+
+	seen := make(map[string]bool)
+	for _, m := range manifests {
+		posts := loadRunOutputs[types.Post](m, store.Step1Posts)
+		analyses := make(map[string]*types.Analysis)
+		for _, analysis := range loadRunOutputs[types.Analysis](m, store.Step2Analyses) {
+			analyses[analysis.PostID] = &analysis
+		}
+		included := make(map[string]bool)
+		for _, p := range loadRunOutputs[types.PostWithAnalysis](m, store.Step3Filtered) {
+			included[p.Post.ID] = true
+		}
+
+		for _, post := range posts {
+			if seen[post.ID] || post.Source == types.SourceMentions {
+				continue
+			}
+			seen[post.ID] = true
+
+			tokens := ranking.Tokens(post.Content)
+			for i, k := range interests.MutedKeywords {
+				if ranking.HasPhrase(post.Content, tokens, k) {
+					mutedKeywords[i]++
+				}
+			}
+			for i, handle := range interests.MutedAccounts {
+				if normalizeHandle(handle) == normalizeHandle(post.AuthorHandle) {
+					mutedAccounts[i]++
+				}
+			}
+
+			analysis, ok := analyses[post.ID]
+			if !ok {
+				continue
+			}
+			report.Posts++
+			if included[post.ID] {
+				report.Included++
+			}
+			report.addPost(post, analysis, included[post.ID])
+		}
+	}
+
+	byScore := func(a, b TunedPost) int { return cmp.Compare(b.Score, a.Score) }
+	for _, k := range append(slices.Clone(report.Keywords), report.Other) {
+		slices.SortFunc(k.Included, byScore) // Sorts the report's slices in place
+		slices.SortFunc(k.NearMisses, byScore)
+	}
+	for i, k := range interests.MutedKeywords {
+		report.MutedKeywords = append(report.MutedKeywords, MuteTuning{Mute: k, Hits: mutedKeywords[i]})
+	}
+	for i, handle := range interests.MutedAccounts {
+		report.MutedAccounts = append(report.MutedAccounts, MuteTuning{Mute: handle, Hits: mutedAccounts[i]})
+	}
+	return report, nil
+}
+
+// addPost counts an analyzed post under each keyword it mentions, or under
+// Other if it mentions none
+func (r *TuningReport) addPost(post types.Post, analysis *types.Analysis, included bool) {
+	nearMiss := !included && analysis.RelevanceScore >= r.Threshold-NearMissMargin
+	tuned := TunedPost{AuthorHandle: post.AuthorHandle, Score: analysis.RelevanceScore, Reason: analysis.Reason}
+	if tuned.Reason == "" {
+		tuned.Reason = analysis.Summary
+	}
+
+	add := func(k *KeywordTuning) {
+		k.Matched++
+		switch {
+		case included:
+			k.Included = append(k.Included, tuned)
+		case nearMiss:
+			k.NearMisses = append(k.NearMisses, tuned)
+		}
+	}
+
+	text := matchText(post, analysis)
+	matched := false
+	for i := range r.Keywords {
+		if k := &r.Keywords[i]; text.has(k.Keyword) {
+			add(k)
+			matched = true
+		}
+	}
+	if !matched {
+		add(&r.Other)
+	}
+}
+
+// loadRunOutputs loads and concatenates every output a run saved for step,
+// skipping files that can no longer be read
+func loadRunOutputs[T any](m store.RunManifest, step store.StepName) []T {
+	var all []T
+	for _, file := range m.StepOutputs[step] {
+		items, err := store.LoadStepOutput[[]T](file)
+		if err != nil {
+			log.Printf("Skipping %s: %v", file, err)
+			continue
+		}
+		all = append(all, items...)
+	}
+	return all
+}
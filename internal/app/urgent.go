@@ -0,0 +1,92 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/notify"
+	"github.com/ibeckermayer/scroll4me/internal/store"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// maxUrgentNotifications caps the notifications one run sends; the rest are
+// counted in the last one
+const maxUrgentNotifications = 3
+
+// notifiedRetention is how long a post that was notified about is
+// remembered, comfortably longer than it stays in the feed
+const notifiedRetention = 7 * 24 * time.Hour
+
+// notifyUrgent sends a desktop notification for each relevant post rated at
+// least analysis.urgency_threshold urgent, most urgent first, skipping posts
+// an earlier digest already showed or that were notified about before, by
+// an earlier run or another profile's digest in this one. Failures are
+// logged.
+func notifyUrgent(ctx context.Context, s snapshot, posts []types.PostWithAnalysis) {
+	threshold := s.config.Analysis.UrgencyThreshold
+	if threshold == 0 {
+		return
+	}
+
+	shown := make(map[string]bool)
+	if memory, err := store.LoadTopicMemory(); err != nil {
+		log.Printf("Failed to load topic memory: %v", err)
+	} else {
+		for _, m := range memory {
+			shown[m.ID] = true
+		}
+	}
+	notified, err := store.LoadNotifiedPosts()
+	if err != nil {
+		log.Printf("Failed to load notified posts: %v", err)
+		notified = make(map[string]time.Time)
+	}
+	var urgent []types.PostWithAnalysis
+	for _, p := range posts {
+		if _, ok := notified[p.Post.ID]; ok {
+			continue
+		}
+		if p.Analysis != nil && p.Analysis.Urgency >= threshold && !shown[p.Post.ID] {
+			urgent = append(urgent, p)
+		}
+	}
+	if len(urgent) == 0 {
+		return
+	}
+	slices.SortStableFunc(urgent, func(a, b types.PostWithAnalysis) int {
+		return cmp.Compare(b.Analysis.Urgency, a.Analysis.Urgency)
+	})
+
+	log.Printf("Found %d urgent posts - notifying", len(urgent))
+	defer func() {
+		cutoff := time.Now().Add(-notifiedRetention)
+		maps.DeleteFunc(notified, func(_ string, at time.Time) bool { return at.Before(cutoff) })
+		if err := store.SaveNotifiedPosts(notified); err != nil {
+			log.Printf("Failed to save notified posts: %v", err)
+		}
+	}()
+	for i, p := range urgent[:min(len(urgent), maxUrgentNotifications)] {
+		title := fmt.Sprintf("🚨 @%s", p.Post.AuthorHandle)
+		message := p.Analysis.Summary
+		if message == "" {
+			message = p.Post.Content
+		}
+		if more := len(urgent) - maxUrgentNotifications; i == maxUrgentNotifications-1 && more > 0 {
+			message += fmt.Sprintf(" (and %d more urgent posts)", more)
+		}
+		if err := notify.Send(ctx, title, message); err != nil {
+			log.Printf("Failed to send notification: %v", err)
+			return
+		}
+		notified[p.Post.ID] = time.Now()
+	}
+	// The last notification counted the rest, so they've been notified about too
+	for _, p := range urgent[min(len(urgent), maxUrgentNotifications):] {
+		notified[p.Post.ID] = time.Now()
+	}
+}
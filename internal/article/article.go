@@ -0,0 +1,125 @@
+// Package article fetches web pages linked from posts and extracts a short
+// plain-text excerpt of their main content, so the analyzer can judge posts
+// that are little more than a link.
+package article
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ibeckermayer/scroll4me/internal/browser"
+)
+
+// maxBodyBytes caps how much of a page is downloaded
+const maxBodyBytes = 2 << 20
+
+// minParagraphChars filters out navigation links, bylines, captions, etc.
+const minParagraphChars = 40
+
+var (
+	stripBlocks = regexp.MustCompile(`(?is)<(script|style|noscript|nav|header|footer|aside|form)\b.*?</(script|style|noscript|nav|header|footer|aside|form)>`)
+	articleTag  = regexp.MustCompile(`(?is)<article\b.*?</article>`)
+	paragraph   = regexp.MustCompile(`(?is)<p\b[^>]*>(.*?)</p>`)
+	anyTag      = regexp.MustCompile(`(?s)<[^>]*>`)
+	whitespace  = regexp.MustCompile(`\s+`)
+)
+
+// Fetcher downloads linked pages and extracts excerpts
+type Fetcher struct {
+	client *http.Client
+}
+
+// NewFetcher creates a fetcher with a per-request timeout
+func NewFetcher(timeout time.Duration) *Fetcher {
+	return &Fetcher{client: &http.Client{Timeout: timeout}}
+}
+
+// Excerpt fetches url (following redirects such as t.co) and returns up to
+// maxChars of its main text content, paragraph-separated.
+func (f *Fetcher) Excerpt(ctx context.Context, url string, maxChars int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", browser.DefaultUserAgent)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetch failed: %s", resp.Status)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.Contains(ct, "html") {
+		return "", fmt.Errorf("not an HTML page: %s", ct)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes))
+	if err != nil {
+		return "", err
+	}
+
+	return extractText(string(body), maxChars), nil
+}
+
+// extractText is a crude readability pass: drop non-content blocks, prefer
+// the <article> element if there is one, and keep substantial paragraphs.
+func extractText(page string, maxChars int) string {
+	page = stripBlocks.ReplaceAllString(page, "")
+	if a := articleTag.FindString(page); a != "" {
+		page = a
+	}
+
+	var paragraphs []string
+	total := 0
+	for _, m := range paragraph.FindAllStringSubmatch(page, -1) {
+		text := anyTag.ReplaceAllString(m[1], "")
+		text = strings.TrimSpace(whitespace.ReplaceAllString(html.UnescapeString(text), " "))
+		if len(text) < minParagraphChars {
+			continue
+		}
+		if total+len(text) > maxChars {
+			if remaining := maxChars - total; remaining > minParagraphChars {
+				paragraphs = append(paragraphs, TruncateWords(text, remaining))
+			}
+			break
+		}
+		paragraphs = append(paragraphs, text)
+		total += len(text)
+	}
+
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// Lead returns the first paragraph of an excerpt, cut to at most maxChars
+// bytes at a word boundary
+func Lead(excerpt string, maxChars int) string {
+	first, _, _ := strings.Cut(excerpt, "\n\n")
+	return TruncateWords(first, maxChars)
+}
+
+// truncateWords cuts s to at most n bytes at a word boundary and adds an ellipsis
+func TruncateWords(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	// Don't split a multi-byte character, should there be no space to cut at
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	s = s[:n]
+	if i := strings.LastIndexByte(s, ' '); i > 0 {
+		s = s[:i]
+	}
+	return s + "…"
+}
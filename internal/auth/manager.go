@@ -15,11 +15,13 @@ import (
 // Manager handles X.com authentication
 type Manager struct {
 	cookieStore *CookieStore
+	profileDir  string // Persistent browser profile shared with the scraper (optional)
 }
 
-// NewManager creates a new auth manager
-func NewManager(cookieStore *CookieStore) *Manager {
-	return &Manager{cookieStore: cookieStore}
+// NewManager creates a new auth manager. If profileDir is non-empty, the login
+// browser uses it so the session persists in the profile for later scrapes.
+func NewManager(cookieStore *CookieStore, profileDir string) *Manager {
+	return &Manager{cookieStore: cookieStore, profileDir: profileDir}
 }
 
 // IsAuthenticated checks if we have valid stored credentials
@@ -31,7 +33,7 @@ func (m *Manager) IsAuthenticated() bool {
 // Returns extracted cookies on successful login
 func (m *Manager) Login(ctx context.Context) error {
 	// Create a visible (headful) browser context with anti-bot-detection
-	opts := browser.Options(false) // headful for login
+	opts := browser.Options(false, m.profileDir) // headful for login
 
 	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
 	defer cancel()
@@ -41,6 +43,7 @@ func (m *Manager) Login(ctx context.Context) error {
 
 	// Navigate to X login page
 	err := chromedp.Run(browserCtx,
+		browser.InjectStealth(),
 		chromedp.Navigate("https://x.com/login"),
 	)
 	if err != nil {
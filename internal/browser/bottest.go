@@ -0,0 +1,54 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/chromedp"
+)
+
+// BotTestURL is the fingerprint audit page `scroll4me bottest` scores
+const BotTestURL = "https://bot.sannysoft.com"
+
+// botTestSettle is how long the audit page gets to finish its async checks
+// (permissions, WebGL) after its tables appear
+const botTestSettle = 3 * time.Second
+
+// BotCheck is one row of the audit page
+type BotCheck struct {
+	Name   string `json:"name"`
+	Result string `json:"result"` // As shown on the page
+	Passed bool   `json:"passed"`
+}
+
+// botChecksJS reads every graded cell of the audit page. Cells are marked
+// "passed", "failed", or "warn"; warnings count as failures.
+const botChecksJS = `Array.from(document.querySelectorAll('td.passed, td.failed, td.warn')).map((cell) => {
+	const row = cell.closest('tr');
+	return {
+		name: ((row && row.cells[0]) || cell).innerText.trim(),
+		result: cell.innerText.trim(),
+		passed: cell.classList.contains('passed'),
+	};
+})`
+
+// ScoreBotTest loads BotTestURL in the tab with the fingerprint patches and
+// returns its graded checks
+func ScoreBotTest(ctx context.Context) ([]BotCheck, error) {
+	var checks []BotCheck
+	err := chromedp.Run(ctx,
+		InjectStealth(),
+		chromedp.Navigate(BotTestURL),
+		chromedp.WaitVisible("table", chromedp.ByQuery),
+		chromedp.Sleep(botTestSettle),
+		chromedp.Evaluate(botChecksJS, &checks),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(checks) == 0 {
+		return nil, fmt.Errorf("no graded checks found on %s (has the page changed?)", BotTestURL)
+	}
+	return checks, nil
+}
@@ -1,14 +1,44 @@
 // Package browser provides shared chromedp configuration with anti-bot-detection measures.
 package browser
 
-import "github.com/chromedp/chromedp"
+import (
+	"os"
+	"strconv"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+)
 
 // DefaultUserAgent is a realistic Chrome user agent
 const DefaultUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
 
+// MobileUserAgent is a realistic Chrome for Android user agent, matching the
+// DefaultUserAgent's Chrome version
+const MobileUserAgent = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36"
+
+// Emulated phone screen, in CSS pixels
+const (
+	mobileWidth      = 412
+	mobileHeight     = 915
+	mobilePixelRatio = 2.625
+)
+
+// EmulateMobile makes the current tab look like Chrome on an Android phone:
+// mobile user agent, phone-sized viewport, and touch. Run it before
+// navigating so X serves its mobile layout.
+func EmulateMobile() chromedp.Tasks {
+	return chromedp.Tasks{
+		emulation.SetUserAgentOverride(MobileUserAgent),
+		chromedp.EmulateViewport(mobileWidth, mobileHeight,
+			chromedp.EmulateScale(mobilePixelRatio), chromedp.EmulateMobile, chromedp.EmulateTouch),
+	}
+}
+
 // Options returns chromedp allocator options with anti-bot-detection measures.
 // All browser instances should use this to ensure consistent stealth configuration.
-func Options(headless bool) []chromedp.ExecAllocatorOption {
+// If profileDir is non-empty, Chrome uses it as a persistent user data directory
+// so cookies, localStorage, and fingerprint state survive between runs.
+func Options(headless bool, profileDir string) []chromedp.ExecAllocatorOption {
 	opts := append(chromedp.DefaultExecAllocatorOptions[:],
 		chromedp.Flag("headless", headless),
 
@@ -28,11 +58,19 @@ func Options(headless bool) []chromedp.ExecAllocatorOption {
 		chromedp.Flag("disable-infobars", true),
 		chromedp.Flag("no-first-run", true),
 		chromedp.Flag("no-default-browser-check", true),
+
+		// Tag the process so SweepOrphans can find it if we exit without
+		// closing it
+		chromedp.Flag(ownerFlag, strconv.Itoa(os.Getpid())),
 	)
 
 	if headless {
 		opts = append(opts, chromedp.Flag("disable-gpu", true))
 	}
 
+	if profileDir != "" {
+		opts = append(opts, chromedp.UserDataDir(profileDir))
+	}
+
 	return opts
 }
@@ -0,0 +1,142 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ownerFlag is the command-line switch every Chrome launched with Options
+// carries, set to the PID of the scroll4me process that launched it. Chrome
+// ignores switches it doesn't know, and the tag lets SweepOrphans tell our
+// Chromes apart from the user's own.
+const ownerFlag = "scroll4me-owner"
+
+// chromedpProfilePrefix starts the names of the temporary profile
+// directories chromedp creates for Chromes launched without profile_dir
+const chromedpProfilePrefix = "chromedp-runner"
+
+// listTimeout bounds how long listing the running processes may take
+const listTimeout = 10 * time.Second
+
+var (
+	ownerPattern       = regexp.MustCompile(`--` + ownerFlag + `=(\d+)`)
+	userDataDirPattern = regexp.MustCompile(`--user-data-dir="?([^"\s]+)`)
+)
+
+// SweepOrphans kills Chrome processes that an earlier scroll4me process
+// launched and left running when it crashed or was killed, and removes
+// their temporary profiles. Chromes whose owner is still running, such as
+// the tray app's while a CLI command runs, are left alone. Returns the
+// number of processes killed.
+func SweepOrphans() (int, error) {
+	procs, err := listProcesses()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list processes: %w", err)
+	}
+
+	killed := 0
+	for _, p := range procs {
+		m := ownerPattern.FindStringSubmatch(p.command)
+		if m == nil {
+			continue
+		}
+		owner, err := strconv.Atoi(m[1])
+		if err != nil || owner == os.Getpid() || processAlive(owner) {
+			continue
+		}
+
+		proc, err := os.FindProcess(p.pid)
+		if err != nil {
+			continue // Exited since the listing
+		}
+		if err := proc.Kill(); err != nil {
+			if !errors.Is(err, os.ErrProcessDone) {
+				log.Printf("Failed to kill orphaned Chrome %d (launched by %d): %v", p.pid, owner, err)
+			}
+			continue
+		}
+		killed++
+		log.Printf("Killed orphaned Chrome %d, launched by scroll4me process %d", p.pid, owner)
+
+		if m := userDataDirPattern.FindStringSubmatch(p.command); m != nil && isChromedpProfile(m[1]) {
+			if err := os.RemoveAll(m[1]); err != nil {
+				log.Printf("Failed to remove orphaned Chrome profile %s: %v", m[1], err)
+			}
+		}
+	}
+	return killed, nil
+}
+
+// process is one entry of the process list
+type process struct {
+	pid     int
+	command string // Full command line
+}
+
+// listProcesses returns the running processes with their command lines,
+// from ps, or on Windows from PowerShell (only chrome.exe processes)
+func listProcesses() ([]process, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), listTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command",
+			`Get-CimInstance Win32_Process -Filter "Name = 'chrome.exe'" | ForEach-Object { "$($_.ProcessId) $($_.CommandLine)" }`)
+	} else {
+		cmd = exec.CommandContext(ctx, "ps", "-A", "-ww", "-o", "pid=,command=")
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []process
+	for _, line := range strings.Split(string(out), "\n") {
+		pid, command, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(pid)
+		if err != nil {
+			continue
+		}
+		procs = append(procs, process{pid: n, command: command})
+	}
+	return procs, nil
+}
+
+// processAlive reports whether a process with the given PID is running
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		// FindProcess only succeeds for running processes there
+		p.Release()
+		return true
+	}
+	// Signal 0 checks for the process without signalling it; a process of
+	// another user still exists
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
+
+// isChromedpProfile reports whether dir is a temporary profile chromedp
+// created, and so safe to delete
+func isChromedpProfile(dir string) bool {
+	return strings.HasPrefix(filepath.Base(dir), chromedpProfilePrefix) &&
+		filepath.Dir(filepath.Clean(dir)) == filepath.Clean(os.TempDir())
+}
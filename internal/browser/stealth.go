@@ -0,0 +1,94 @@
+package browser
+
+import (
+	"context"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+)
+
+// stealthScript patches the JavaScript-visible giveaways of an automated or
+// headless Chrome that the AutomationControlled flag doesn't cover. It runs
+// before any page script. Each patch only applies when the value looks
+// automated, so a real profile (or mobile emulation) keeps its own values.
+const stealthScript = `(() => {
+	const define = (obj, prop, value) => {
+		try {
+			Object.defineProperty(obj, prop, { get: () => value, configurable: true });
+		} catch (e) {}
+	};
+
+	// navigator.webdriver is true under automation, and undefined in a normal browser
+	if (navigator.webdriver) {
+		define(Object.getPrototypeOf(navigator), 'webdriver', undefined);
+	}
+
+	// Headless Chrome has no languages
+	if (!navigator.languages || navigator.languages.length === 0) {
+		define(Object.getPrototypeOf(navigator), 'languages', Object.freeze(['en-US', 'en']));
+	}
+
+	// Headless Chrome has no plugins; desktop Chrome lists its PDF viewers
+	if (navigator.plugins.length === 0 && !/Mobile/.test(navigator.userAgent)) {
+		const mimeType = { type: 'application/pdf', suffixes: 'pdf', description: 'Portable Document Format' };
+		const names = ['PDF Viewer', 'Chrome PDF Viewer', 'Chromium PDF Viewer', 'Microsoft Edge PDF Viewer', 'WebKit built-in PDF'];
+		const plugins = names.map((name) => {
+			const plugin = { name, filename: 'internal-pdf-viewer', description: 'Portable Document Format', length: 1, 0: mimeType };
+			Object.setPrototypeOf(plugin, Plugin.prototype);
+			return plugin;
+		});
+		plugins.item = (i) => plugins[i] || null;
+		plugins.namedItem = (name) => plugins.find((p) => p.name === name) || null;
+		plugins.refresh = () => {};
+		Object.setPrototypeOf(plugins, PluginArray.prototype);
+		define(Object.getPrototypeOf(navigator), 'plugins', plugins);
+	}
+
+	// Pages check for window.chrome.runtime, which headless Chrome lacks
+	if (!window.chrome) {
+		define(window, 'chrome', {});
+	}
+	if (!window.chrome.runtime) {
+		window.chrome.runtime = {
+			connect: () => {},
+			sendMessage: () => {},
+			id: undefined,
+		};
+	}
+
+	// Headless Chrome denies notifications while reporting the permission as
+	// "prompt"; a real browser agrees with itself
+	const query = window.navigator.permissions && window.navigator.permissions.query;
+	if (query) {
+		window.navigator.permissions.query = (params) =>
+			params && params.name === 'notifications'
+				? Promise.resolve({ state: Notification.permission, onchange: null })
+				: query.call(window.navigator.permissions, params);
+	}
+
+	// Without a GPU, WebGL reports Google's SwiftShader software renderer
+	const UNMASKED_VENDOR = 0x9245;
+	const UNMASKED_RENDERER = 0x9246;
+	for (const ctx of [window.WebGLRenderingContext, window.WebGL2RenderingContext]) {
+		if (!ctx) {
+			continue;
+		}
+		const getParameter = ctx.prototype.getParameter;
+		ctx.prototype.getParameter = function (param) {
+			if ((param === UNMASKED_VENDOR || param === UNMASKED_RENDERER) &&
+				/SwiftShader/.test(getParameter.call(this, UNMASKED_RENDERER))) {
+				return param === UNMASKED_VENDOR ? 'Intel Inc.' : 'Intel Iris OpenGL Engine';
+			}
+			return getParameter.call(this, param);
+		};
+	}
+})();`
+
+// InjectStealth adds the fingerprint patches to every document the tab
+// loads from now on. Run it before navigating.
+func InjectStealth() chromedp.Action {
+	return chromedp.ActionFunc(func(ctx context.Context) error {
+		_, err := page.AddScriptToEvaluateOnNewDocument(stealthScript).Do(ctx)
+		return err
+	})
+}
@@ -1,11 +1,17 @@
 package config
 
 import (
+	"bytes"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/anthropics/anthropic-sdk-go"
+
+	"github.com/ibeckermayer/scroll4me/internal/atomicfile"
 )
 
 // Config holds all application configuration
@@ -15,20 +21,249 @@ type Config struct {
 	Scraping  ScrapingConfig  `toml:"scraping"`
 	Analysis  AnalysisConfig  `toml:"analysis"`
 	Digest    DigestConfig    `toml:"digest"`
+	Pipeline  PipelineConfig  `toml:"pipeline"`
+	Sync      SyncConfig      `toml:"sync"`
+	// Named interest profiles, e.g. "work" or "news", by name
+	Profiles map[string]InterestProfile `toml:"profiles"`
 }
 
 type InterestsConfig struct {
-	CustomInstructions string   `toml:"custom_instructions"`
-	Keywords           []string `toml:"keywords"`
-	PriorityAccounts   []string `toml:"priority_accounts"`
-	MutedAccounts      []string `toml:"muted_accounts"`
-	MutedKeywords      []string `toml:"muted_keywords"`
+	CustomInstructions string            `toml:"custom_instructions"`
+	Keywords           []Keyword         `toml:"keywords"`
+	PriorityAccounts   []PriorityAccount `toml:"priority_accounts"`
+	MutedAccounts      []string          `toml:"muted_accounts"`
+	MutedKeywords      []string          `toml:"muted_keywords"`
+}
+
+// DefaultCustomInstructions are the analysis guidelines of a new config,
+// which say nothing about the user's interests
+const DefaultCustomInstructions = "Score posts based on general quality, informativeness, and newsworthiness. DO NOT reject posts for being heretical, critical, or impolite."
+
+// Empty reports whether no interests have been configured: no keywords, no
+// priority accounts, and no custom instructions beyond the default ones
+func (i InterestsConfig) Empty() bool {
+	instructions := strings.TrimSpace(i.CustomInstructions)
+	return len(i.Keywords) == 0 && len(i.PriorityAccounts) == 0 &&
+		(instructions == "" || instructions == DefaultCustomInstructions)
+}
+
+// Keyword is an interest keyword with scoring rules. In TOML it's either a
+// plain string or a table: {keyword = "golang", weight = 2.0, min_score = 0.8}
+// ("term" is accepted in place of "keyword"). Posts matching a keyword have
+// their relevance score multiplied by its weight (default 1), so critical
+// topics outrank casual ones, and raised to its min_score if that's higher.
+type Keyword struct {
+	Keyword  string
+	Weight   float64
+	MinScore float64 // Floor of a matching post's relevance score; 0 for none
+}
+
+// EffectiveWeight returns the keyword's weight, treating unset as 1
+func (k Keyword) EffectiveWeight() float64 {
+	return effectiveWeight(k.Weight)
+}
+
+// UnmarshalTOML accepts either form described on Keyword
+func (k *Keyword) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*k = Keyword{Keyword: v}
+	case map[string]any:
+		keyword, ok := v["keyword"].(string)
+		if !ok {
+			keyword, ok = v["term"].(string)
+		}
+		if !ok {
+			return fmt.Errorf("keyword table needs a keyword string: %v", v)
+		}
+		*k = Keyword{Keyword: keyword}
+		var err error
+		if k.Weight, k.MinScore, err = scoringRules(v); err != nil {
+			return fmt.Errorf("keyword %q: %w", keyword, err)
+		}
+	default:
+		return fmt.Errorf("keyword must be a string or {keyword, weight, min_score} table, got %T", v)
+	}
+	return nil
+}
+
+// MarshalTOML writes keywords without scoring rules as plain strings
+func (k Keyword) MarshalTOML() ([]byte, error) {
+	return marshalRules("keyword", k.Keyword, k.Weight, k.MinScore), nil
+}
+
+// PriorityAccount is an account whose posts matter more, with optional
+// scoring rules. In TOML it's either a plain handle or a table:
+// {account = "@sama", weight = 1.5, min_score = 0.7}. The rules work as on
+// Keyword, applied to the account's own posts.
+type PriorityAccount struct {
+	Handle   string
+	Weight   float64
+	MinScore float64
+}
+
+// EffectiveWeight returns the account's weight, treating unset as 1
+func (a PriorityAccount) EffectiveWeight() float64 {
+	return effectiveWeight(a.Weight)
+}
+
+// UnmarshalTOML accepts either form described on PriorityAccount
+func (a *PriorityAccount) UnmarshalTOML(v any) error {
+	switch v := v.(type) {
+	case string:
+		*a = PriorityAccount{Handle: v}
+	case map[string]any:
+		handle, ok := v["account"].(string)
+		if !ok {
+			return fmt.Errorf("priority account table needs an account string: %v", v)
+		}
+		*a = PriorityAccount{Handle: handle}
+		var err error
+		if a.Weight, a.MinScore, err = scoringRules(v); err != nil {
+			return fmt.Errorf("priority account %q: %w", handle, err)
+		}
+	default:
+		return fmt.Errorf("priority account must be a string or {account, weight, min_score} table, got %T", v)
+	}
+	return nil
+}
+
+// MarshalTOML writes accounts without scoring rules as plain strings
+func (a PriorityAccount) MarshalTOML() ([]byte, error) {
+	return marshalRules("account", a.Handle, a.Weight, a.MinScore), nil
+}
+
+// effectiveWeight treats an unset weight as 1
+func effectiveWeight(weight float64) float64 {
+	if weight == 0 {
+		return 1
+	}
+	return weight
+}
+
+// scoringRules reads the optional weight and min_score of a keyword or
+// account table
+func scoringRules(table map[string]any) (weight, minScore float64, err error) {
+	number := func(key string) (float64, error) {
+		switch n := table[key].(type) {
+		case nil:
+			return 0, nil
+		case float64:
+			return n, nil
+		case int64:
+			return float64(n), nil
+		default:
+			return 0, fmt.Errorf("%s must be a number", key)
+		}
+	}
+	if weight, err = number("weight"); err != nil {
+		return 0, 0, err
+	}
+	if minScore, err = number("min_score"); err != nil {
+		return 0, 0, err
+	}
+	return weight, minScore, nil
+}
+
+// marshalRules writes a keyword or account as a plain string if it has no
+// scoring rules, else as a table naming it under key
+func marshalRules(key, name string, weight, minScore float64) []byte {
+	if effectiveWeight(weight) == 1 && minScore == 0 {
+		return []byte(strconv.Quote(name))
+	}
+	text := fmt.Appendf(nil, "{%s = %s", key, strconv.Quote(name))
+	if effectiveWeight(weight) != 1 {
+		text = fmt.Appendf(text, ", weight = %s", strconv.FormatFloat(weight, 'f', -1, 64))
+	}
+	if minScore != 0 {
+		text = fmt.Appendf(text, ", min_score = %s", strconv.FormatFloat(minScore, 'f', -1, 64))
+	}
+	return append(text, '}')
 }
 
 type ScrapingConfig struct {
-	PostsPerScrape        int  `toml:"posts_per_scrape"`
-	Headless              bool `toml:"headless"`
-	DebugPauseAfterScrape bool `toml:"debug_pause_after_scrape"`
+	PostsPerScrape        int    `toml:"posts_per_scrape"`
+	Headless              bool   `toml:"headless"`
+	DebugPauseAfterScrape bool   `toml:"debug_pause_after_scrape"`
+	Feed                  string `toml:"feed"` // FeedForYou, FeedFollowing, or FeedNone
+	// X List URLs (or bare list IDs) to scrape in addition to the feed
+	Lists []string `toml:"lists"`
+	// X Community URLs (or bare community IDs) you're a member of, scraped
+	// in addition to the feed
+	Communities []string `toml:"communities"`
+	// Saved searches (e.g. "#golang", "from:someone") scraped from the Latest tab
+	Searches []string `toml:"searches"`
+	// If true, mentions and replies to you are scraped for a dedicated digest section
+	IncludeMentions bool `toml:"include_mentions"`
+	// If true, the Explore page's Trending list is scraped too and
+	// summarized into a "trending" section at the top of the digest
+	IncludeTrends bool `toml:"include_trends"`
+	// Persistent Chrome user data directory shared by login and scraping.
+	// Empty means a fresh profile per run with stored cookies injected.
+	// A good choice is a "chrome-profile" directory next to this config file.
+	ProfileDir string `toml:"profile_dir"`
+	// If true, self-threads spotted in the feed (consecutive posts by one
+	// author replying to themselves) are unrolled from their conversation
+	// page and analyzed as a single post.
+	UnrollThreads bool `toml:"unroll_threads"`
+	// If true, replies to other accounts have the post they reply to
+	// fetched from their conversation page, so they're read in context.
+	FetchReplyParents bool `toml:"fetch_reply_parents"`
+	// If true, promoted (ad) posts are kept and analyzed like any other.
+	// By default they're dropped right after scraping.
+	IncludePromoted bool `toml:"include_promoted"`
+	// If true, near-identical posts by different accounts (the same news
+	// worded alike, or links to the same article) are collapsed after
+	// scraping into their most engaged post, which alone is analyzed and
+	// lists the others as also covering it.
+	CollapseNearDuplicates bool `toml:"collapse_near_duplicates"`
+	// How human-like scrolling is: "off" (instant full-page jumps), "low"
+	// (mouse-wheel scrolling of varying distance), or "high" (also mouse
+	// movement, occasional upward scrolls, and longer pauses).
+	StealthLevel string `toml:"stealth_level"`
+	// Accounts whose profile timelines are scraped alongside the feed
+	// (handles, with or without "@")
+	Profiles []string `toml:"profiles"`
+	// If true, a missing or expired X session doesn't stop the run: public
+	// Lists and Profiles are scraped logged out instead, fewer posts at a
+	// time and with pauses in between.
+	GuestFallback bool `toml:"guest_fallback"`
+	// Browser launches against X (feed, lists, threads, ...) are spaced at
+	// least this many seconds apart, and at most DailyLaunchBudget happen
+	// per rolling 24 hours (0 = unlimited).
+	MinScrapeIntervalSeconds int `toml:"min_scrape_interval_seconds"`
+	DailyLaunchBudget        int `toml:"daily_launch_budget"`
+	// Incremental scraping: stop scrolling a timeline once this many posts
+	// in a row were already seen in the last run (0 = always scroll for the
+	// full posts_per_scrape).
+	StopAfterKnownPosts int `toml:"stop_after_known_posts"`
+	// Language codes (e.g. "en", "de") of posts to keep. Posts in other
+	// languages are dropped before analysis; posts whose language X
+	// couldn't tell are kept. Empty keeps every language.
+	Languages []string `toml:"languages"`
+	// DevTools URL of an already-running Chrome to scrape in (e.g.
+	// http://127.0.0.1:9222, from --remote-debugging-port) instead of
+	// launching one. That browser's own X session is used.
+	RemoteDebuggingURL string `toml:"remote_debugging_url"`
+	// If true, Chrome emulates an Android phone so X serves its mobile
+	// layout, which is simpler and changes less often, and posts are read
+	// with the mobile selector chains (see selectors.toml)
+	MobileEmulation bool `toml:"mobile_emulation"`
+	// Debugging: save the markup of the tweets each scrape sees to the
+	// step1_html cache, for re-running extraction with `step reparse`
+	SaveHTMLSnapshots bool `toml:"save_html_snapshots"`
+	// Timeline scrape pacing, for slow connections. A scrape pauses
+	// ScrollDelayMs plus up to ScrollJitterMs after each scroll, gives up
+	// after MaxIdleScrolls scrolls in a row find nothing new, and may take
+	// ScrapeSecondsPerPost per post requested (at least
+	// MinScrapeTimeoutSeconds). Pages get PageLoadTimeoutSeconds to show
+	// tweets. 0 means the default: 500 ms, 300 ms, never, 1 s, 60 s, 30 s.
+	ScrollDelayMs           int     `toml:"scroll_delay_ms"`
+	ScrollJitterMs          int     `toml:"scroll_jitter_ms"`
+	MaxIdleScrolls          int     `toml:"max_idle_scrolls"`
+	ScrapeSecondsPerPost    float64 `toml:"scrape_seconds_per_post"`
+	MinScrapeTimeoutSeconds int     `toml:"min_scrape_timeout_seconds"`
+	PageLoadTimeoutSeconds  int     `toml:"page_load_timeout_seconds"`
 }
 
 type AnalysisConfig struct {
@@ -37,11 +272,142 @@ type AnalysisConfig struct {
 	Model              string  `toml:"model"`
 	RelevanceThreshold float64 `toml:"relevance_threshold"`
 	BatchSize          int     `toml:"batch_size"`
+	// If true, posts flagged as engagement bait are dropped during filtering
+	// regardless of their relevance score.
+	ExcludeEngagementBait bool `toml:"exclude_engagement_bait"`
+	// Posts with a known view count whose likes/views ratio falls below this
+	// are dropped during filtering. 0 disables the check.
+	MinLikeRate float64 `toml:"min_like_rate"`
+	// How far ratings (see `scroll4me rate` and digest feedback links) move
+	// the relevance score of posts by rated authors or matching rated
+	// keywords: at most this much up or down for each. 0 disables.
+	FeedbackWeight float64 `toml:"feedback_weight"`
+	// Relevant posts the LLM rates at least this urgent (outages, security
+	// advisories, breaking news) trigger a desktop notification as soon as
+	// the run has filtered them. 0 disables.
+	UrgencyThreshold float64 `toml:"urgency_threshold"`
+	// If true, pages linked from post preview cards are fetched before
+	// analysis and an excerpt of their text is included in the prompt.
+	FetchLinkedArticles bool `toml:"fetch_linked_articles"`
+	// If true, the profiles (follower count, bio, verification) of authors
+	// whose posts make the digest are fetched, at most weekly per author,
+	// and included in later analysis prompts.
+	EnrichAuthors bool `toml:"enrich_authors"`
+	// If true, the replies to the most discussed digest posts are read from
+	// their conversation pages, and a second LLM pass rewrites those posts'
+	// summaries to take in the discussion.
+	SummarizeDiscussions bool `toml:"summarize_discussions"`
+	// How many digest posts' conversation pages SummarizeDiscussions loads
+	// per run, most replied-to first. 0 means the default, 5.
+	MaxDiscussionFetches int `toml:"max_discussion_fetches"`
+	// What to do when the provider's rate limit is nearly exhausted:
+	// QuotaActionDefer, QuotaActionDowngrade (to FallbackModel), or
+	// QuotaActionFail.
+	QuotaAction   string `toml:"quota_action"`
+	FallbackModel string `toml:"fallback_model"`
+	// Route requests through a gateway (LiteLLM, Portkey, a self-hosted
+	// proxy) instead of the provider's API. BaseURL replaces the API's URL
+	// if set, and ExtraHeaders are sent with every request.
+	BaseURL      string            `toml:"base_url"`
+	ExtraHeaders map[string]string `toml:"extra_headers"`
+	// Language (e.g. "German" or "pt-BR") the LLM writes summaries, topics,
+	// and notes in, whatever language the posts are in. Empty leaves it to
+	// the model.
+	PromptLanguage string `toml:"prompt_language"`
+	// If set, this (cheaper, faster) model first picks out the posts that
+	// could be relevant, and only those get a full analysis by Model. The
+	// rest score 0. Empty sends every post to Model.
+	TriageModel string `toml:"triage_model"`
 }
 
 type DigestConfig struct {
 	OutputDir string `toml:"output_dir"`
 	MaxPosts  int    `toml:"max_posts"`
+	// Only posts newer than this many hours are considered for a
+	// headlines-only (no LLM) digest.
+	HeadlinesWindowHours int `toml:"headlines_window_hours"`
+	// If true, images and video thumbnails of digest posts are downloaded
+	// into the cache directory and embedded from there.
+	DownloadMedia bool `toml:"download_media"`
+	// How much an author's track record shifts their posts' rank: rank is
+	// relevance + weight * (affinity - 0.5), where affinity is the share of
+	// the author's recent posts that made the digest. 0 disables.
+	AuthorAffinityWeight float64 `toml:"author_affinity_weight"`
+	// Each post about the same subject as one in a recent digest multiplies
+	// a post's rank by 1 minus this, so the sixth take on a model release
+	// sinks below fresh subjects. 0 turns the suppression off.
+	RepetitionPenalty float64 `toml:"repetition_penalty"`
+	// How posts are ordered: RankerScore, RankerEngagement, RankerRecency,
+	// or RankerMMR. The tuning values below apply to their ranker; 0 means
+	// the built-in default.
+	Ranker               string  `toml:"ranker"`
+	EngagementWeight     float64 `toml:"engagement_weight"`       // Engagement bonus for the top post (default 0.3)
+	RecencyHalfLifeHours float64 `toml:"recency_half_life_hours"` // Age at which a post's score halves (default 24)
+	MMRLambda            float64 `toml:"mmr_lambda"`              // 1 = pure score, lower = more diverse (default 0.7)
+	// If true, posts scraped from X Communities get a digest section per
+	// community instead of being mixed in with the rest.
+	GroupByCommunity bool `toml:"group_by_community"`
+	// If true, full runs have the LLM group the digest's posts into topic
+	// sections ("AI", "Politics") rendered in order of each section's best
+	// post, instead of one list.
+	GroupByTopic bool `toml:"group_by_topic"`
+	// If set, the tray app serves rating links on this localhost port, and
+	// each digest post gets 👍/👎 links that rate it. 0 means no links.
+	FeedbackPort int `toml:"feedback_port"`
+	// If true, posts whose linked article was fetched (see
+	// AnalysisConfig.FetchLinkedArticles) show the start of its text under
+	// the link card.
+	ArticleExcerpts bool `toml:"article_excerpts"`
+	// FormatMarkdown, or FormatText for plain text wrapped at TextWidth
+	// columns (0 means 72) with links numbered at the bottom
+	Format    string `toml:"format"`
+	TextWidth int    `toml:"text_width"`
+}
+
+// PipelineConfig bounds a whole digest run
+type PipelineConfig struct {
+	// Once a run has taken this many minutes, optional enrichment (thread
+	// unrolling, linked articles, trends, author profiles, discussion
+	// summaries, topic sections, media downloads) is skipped and the digest
+	// is built from what's done. 0 means no limit.
+	MaxMinutes int `toml:"max_minutes"`
+}
+
+// InterestProfile is an alternative set of interests, with its own
+// relevance threshold, that a run can analyze its scrape against in place of
+// [interests] (see WithProfile). Each profile a run uses gets a digest of
+// its own.
+type InterestProfile struct {
+	RelevanceThreshold float64         `toml:"relevance_threshold"` // 0 uses analysis.relevance_threshold
+	Interests          InterestsConfig `toml:"interests"`
+}
+
+// SyncConfig configures pushing each new digest to remote storage.
+// A target is enabled by filling in its section.
+type SyncConfig struct {
+	WebDAV WebDAVConfig  `toml:"webdav"`
+	S3     S3Config      `toml:"s3"`
+	Git    GitSyncConfig `toml:"git"`
+}
+
+type WebDAVConfig struct {
+	URL      string `toml:"url"` // Collection URL, e.g. https://cloud.example.com/remote.php/dav/files/me/digests
+	Username string `toml:"username"`
+	Password string `toml:"password"`
+}
+
+type S3Config struct {
+	Endpoint        string `toml:"endpoint"` // e.g. https://s3.us-east-1.amazonaws.com
+	Region          string `toml:"region"`
+	Bucket          string `toml:"bucket"`
+	Prefix          string `toml:"prefix"`
+	AccessKeyID     string `toml:"access_key_id"`
+	SecretAccessKey string `toml:"secret_access_key"`
+}
+
+type GitSyncConfig struct {
+	RepoDir string `toml:"repo_dir"` // Local clone that digests are committed into
+	Push    bool   `toml:"push"`     // Push after each commit
 }
 
 // LLM Provider constants
@@ -50,39 +416,125 @@ const (
 	// ProviderOpenAI = "openai" // TODO: future support
 )
 
+// Quota action constants
+const (
+	QuotaActionDefer     = "defer"     // Put remaining batches off to the next run
+	QuotaActionDowngrade = "downgrade" // Switch remaining batches to FallbackModel
+	QuotaActionFail      = "fail"      // Let rate limit errors fail the run
+)
+
+// Ranker constants
+const (
+	RankerScore      = "score"      // Relevance, blended with author affinity
+	RankerEngagement = "engagement" // Score plus an engagement bonus
+	RankerRecency    = "recency"    // Score decayed by post age
+	RankerMMR        = "mmr"        // Score traded off against topic overlap with higher-ranked posts
+)
+
+// Digest format constants
+const (
+	FormatMarkdown = "markdown"
+	FormatText     = "text" // For terminals, mail readers, and e-ink devices
+)
+
+// Feed constants
+const (
+	FeedForYou    = "for_you"
+	FeedFollowing = "following"
+	FeedNone      = "none" // Only scrape additional sources (e.g. lists)
+)
+
+// WithProfile returns a copy of c that uses the named interest profile's
+// interests and relevance threshold
+func (c *Config) WithProfile(name string) (*Config, error) {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown interest profile %q (configure it under [profiles.%s])", name, name)
+	}
+	cfg := *c
+	cfg.Interests = profile.Interests
+	if profile.RelevanceThreshold > 0 {
+		cfg.Analysis.RelevanceThreshold = profile.RelevanceThreshold
+	}
+	return &cfg, nil
+}
+
 // Default returns a Config with sensible defaults
 func Default() *Config {
 	outputDir, _ := DefaultDigestDir()
 	return &Config{
 		Version: 1,
 		Interests: InterestsConfig{
-			CustomInstructions: "Score posts based on general quality, informativeness, and newsworthiness. DO NOT reject posts for being heretical, critical, or impolite.",
-			Keywords:           []string{},
-			PriorityAccounts:   []string{},
+			CustomInstructions: DefaultCustomInstructions,
+			Keywords:           []Keyword{},
+			PriorityAccounts:   []PriorityAccount{},
 			MutedAccounts:      []string{},
 			MutedKeywords:      []string{},
 		},
 		Scraping: ScrapingConfig{
-			PostsPerScrape:        50,
-			Headless:              true,
-			DebugPauseAfterScrape: false,
+			PostsPerScrape:           50,
+			Headless:                 true,
+			DebugPauseAfterScrape:    false,
+			Feed:                     FeedForYou,
+			Lists:                    []string{},
+			Communities:              []string{},
+			Searches:                 []string{},
+			Profiles:                 []string{},
+			UnrollThreads:            true,
+			StealthLevel:             "low",
+			MinScrapeIntervalSeconds: 10,
+			DailyLaunchBudget:        100,
+			StopAfterKnownPosts:      5,
+			Languages:                []string{},
 		},
 		Analysis: AnalysisConfig{
-			LLMProvider:        ProviderAnthropic,
-			Model:              string(anthropic.ModelClaudeSonnet4_5_20250929),
-			APIKey:             "<replace with your API key>",
-			RelevanceThreshold: 0.8,
-			BatchSize:          50,
+			LLMProvider:           ProviderAnthropic,
+			Model:                 string(anthropic.ModelClaudeSonnet4_5_20250929),
+			APIKey:                "<replace with your API key>",
+			RelevanceThreshold:    0.8,
+			BatchSize:             50,
+			ExcludeEngagementBait: true,
+			FeedbackWeight:        0.1,
+			QuotaAction:           QuotaActionDefer,
 		},
 		Digest: DigestConfig{
-			OutputDir: outputDir,
-			MaxPosts:  20,
+			OutputDir:            outputDir,
+			MaxPosts:             20,
+			HeadlinesWindowHours: 12,
+			AuthorAffinityWeight: 0.2,
+			RepetitionPenalty:    0.15,
+			Ranker:               RankerScore,
+			Format:               FormatMarkdown,
 		},
 	}
 }
 
+// dataRoot, if set, replaces the platform directories, see UseDataRoot
+var dataRoot string
+
+// UseDataRoot keeps all of the process's config and state under root,
+// in its config and cache subdirectories, instead of the platform
+// directories. Used by `dev e2e` so a test run can't touch the user's files.
+// Call it before anything reads or writes them.
+func UseDataRoot(root string) {
+	dataRoot = root
+}
+
+// cacheRoot, if set, replaces the cache directory alone, see UseCacheDir
+var cacheRoot string
+
+// UseCacheDir keeps the process's cached state in dir from now on, while
+// config is still read from the usual place. Used by replay runs so they
+// leave the user's caches alone.
+func UseCacheDir(dir string) {
+	cacheRoot = dir
+}
+
 // ConfigDir returns the platform-appropriate config directory
 func ConfigDir() (string, error) {
+	if dataRoot != "" {
+		return filepath.Join(dataRoot, "config"), nil
+	}
 	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
@@ -93,6 +545,12 @@ func ConfigDir() (string, error) {
 // CacheDir returns the platform-appropriate cache directory.
 // On macOS this is ~/Library/Caches/scroll4me/
 func CacheDir() (string, error) {
+	if cacheRoot != "" {
+		return cacheRoot, nil
+	}
+	if dataRoot != "" {
+		return filepath.Join(dataRoot, "cache"), nil
+	}
 	cacheDir, err := os.UserCacheDir()
 	if err != nil {
 		return "", err
@@ -133,7 +591,8 @@ func Load() (*Config, error) {
 	return &cfg, nil
 }
 
-// Save writes config to disk
+// Save writes config to disk, replacing the file whole so a crash can't
+// leave it half-written. Comments in the file are lost.
 func (c *Config) Save() error {
 	dir, err := ConfigDir()
 	if err != nil {
@@ -149,13 +608,11 @@ func (c *Config) Save() error {
 		return err
 	}
 
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
-	if err != nil {
+	var buf bytes.Buffer
+	encoder := toml.NewEncoder(&buf)
+	encoder.Indent = ""
+	if err := encoder.Encode(c); err != nil {
 		return err
 	}
-	defer f.Close()
-
-	encoder := toml.NewEncoder(f)
-	encoder.Indent = ""
-	return encoder.Encode(c)
+	return atomicfile.Write(path, buf.Bytes(), 0600)
 }
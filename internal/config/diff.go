@@ -0,0 +1,121 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// secretKeys are settings whose values are never recorded, only a short
+// fingerprint that shows when they change
+var secretKeys = map[string]bool{
+	"analysis.api_key":          true,
+	"sync.webdav.password":      true,
+	"sync.s3.secret_access_key": true,
+}
+
+// secretSections are tables whose values are all treated as secrets, since
+// gateway headers usually carry keys
+var secretSections = []string{"analysis.extra_headers."}
+
+// isSecret reports whether the setting at key is never recorded
+func isSecret(key string) bool {
+	if secretKeys[key] {
+		return true
+	}
+	for _, prefix := range secretSections {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Settings flattens the config into dotted TOML keys (e.g.
+// "analysis.relevance_threshold") mapped to JSON-encoded values, for
+// comparing configs. Empty lists are left out, and secrets are replaced by a
+// fingerprint.
+func (c *Config) Settings() (map[string]string, error) {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(c); err != nil {
+		return nil, err
+	}
+	var tree map[string]any
+	if _, err := toml.Decode(buf.String(), &tree); err != nil {
+		return nil, err
+	}
+
+	settings := make(map[string]string)
+	if err := flatten(settings, "", tree); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// flatten adds the leaves of tree to settings under prefix
+func flatten(settings map[string]string, prefix string, tree map[string]any) error {
+	for k, v := range tree {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]any); ok {
+			if err := flatten(settings, key, sub); err != nil {
+				return err
+			}
+			continue
+		}
+		if list, ok := v.([]any); ok && len(list) == 0 {
+			continue
+		}
+
+		value, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if isSecret(key) {
+			sum := sha256.Sum256(value)
+			value = []byte("(secret " + hex.EncodeToString(sum[:4]) + ")")
+		}
+		settings[key] = string(value)
+	}
+	return nil
+}
+
+// DiffSettings describes how settings changed from old to new, one line per
+// key in key order, e.g. `analysis.relevance_threshold: 0.8 -> 0.7`
+func DiffSettings(old, new map[string]string) []string {
+	keys := make(map[string]bool)
+	for k := range old {
+		keys[k] = true
+	}
+	for k := range new {
+		keys[k] = true
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var changes []string
+	for _, k := range sorted {
+		before, hadBefore := old[k]
+		after, hasAfter := new[k]
+		switch {
+		case !hadBefore:
+			changes = append(changes, fmt.Sprintf("%s: set to %s", k, after))
+		case !hasAfter:
+			changes = append(changes, fmt.Sprintf("%s: removed (was %s)", k, before))
+		case before != after:
+			changes = append(changes, fmt.Sprintf("%s: %s -> %s", k, before, after))
+		}
+	}
+	return changes
+}
@@ -0,0 +1,211 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Option describes one config key, for `scroll4me config explain`
+type Option struct {
+	Key         string // Dotted TOML key, e.g. "scraping.posts_per_scrape"
+	Type        string // e.g. "integer" or "list of strings"
+	Default     string // As written in TOML
+	Description string
+}
+
+// descriptions says what each config key does, by dotted TOML key. Keep it
+// in step with the struct fields; Options lists keys missing here as
+// undocumented.
+var descriptions = map[string]string{
+	"version": "Config file format version, managed by scroll4me.",
+
+	"interests.custom_instructions": "Free-form description of what you care about, given to the LLM as its analysis guidelines.",
+	"interests.keywords":            `Interest keywords, as plain strings or {keyword = "golang", weight = 2.0, min_score = 0.8}. Matching posts have their relevance multiplied by the weight (default 1), then raised to min_score if below it.`,
+	"interests.priority_accounts":   `Handles whose posts matter more, as plain strings or {account = "@sama", weight = 1.5, min_score = 0.7} (rules as for keywords). Told to the LLM, and the only accounts considered for headlines digests if set.`,
+	"interests.muted_accounts":      "Handles whose posts are left out of digests. `config import-muted` adds the accounts muted or blocked on X.",
+	"interests.muted_keywords":      "Keywords whose posts are left out of digests. Posts containing one are dropped before analysis; the LLM is told to score paraphrases 0.",
+
+	"scraping.posts_per_scrape":            "Posts to collect from each source (feed, list, community, profile, search, mentions) per run.",
+	"scraping.headless":                    "Run Chrome without a window.",
+	"scraping.debug_pause_after_scrape":    "When not headless, leave the browser open after each scrape until Enter is pressed, to inspect the page.",
+	"scraping.feed":                        `Home feed to scrape: "for_you", "following", or "none" (only the other sources).`,
+	"scraping.lists":                       "X List URLs or IDs scraped in addition to the feed.",
+	"scraping.communities":                 "X Community URLs or IDs you're a member of, scraped in addition to the feed.",
+	"scraping.searches":                    `Saved searches (e.g. "#golang", "from:someone") scraped from the Latest tab.`,
+	"scraping.include_mentions":            "Scrape mentions and replies to you into a separate digest section.",
+	"scraping.include_trends":              `Scrape the Trending list and open the digest with an LLM-written "trending" note.`,
+	"scraping.profile_dir":                 "Persistent Chrome profile directory shared by login and scraping. Empty means a fresh profile per run with stored cookies injected.",
+	"scraping.unroll_threads":              "Read self-threads spotted in the feed in full and analyze each as one post.",
+	"scraping.fetch_reply_parents":         "Fetch the post each reply in the feed answers, so analysis and the digest see both halves of the exchange. Costs a page load per reply, up to 10 per run.",
+	"scraping.include_promoted":            "Keep promoted (ad) posts instead of dropping them after scraping.",
+	"scraping.collapse_near_duplicates":    "Collapse near-identical posts by different accounts into the most engaged one, analyzed alone and listing the others.",
+	"scraping.stealth_level":               `How human-like scrolling is: "off", "low", or "high".`,
+	"scraping.profiles":                    "Account handles whose profile timelines are scraped alongside the feed.",
+	"scraping.guest_fallback":              "Without a valid session, scrape public lists and profiles logged out instead of failing.",
+	"scraping.min_scrape_interval_seconds": "Minimum seconds between browser launches against X (0 = no spacing).",
+	"scraping.daily_launch_budget":         "Maximum browser launches against X per rolling 24 hours (0 = unlimited).",
+	"scraping.stop_after_known_posts":      "Stop scrolling a timeline after this many posts in a row that the last run already saw (0 = always scroll for posts_per_scrape).",
+	"scraping.languages":                   `Language codes (e.g. ["en", "de"]) of posts to keep; others are dropped before analysis. Posts X can't tell the language of are kept. Empty keeps all.`,
+	"scraping.remote_debugging_url":        "DevTools URL (e.g. http://127.0.0.1:9222) of a running Chrome to scrape in, using its own X session, instead of launching one.",
+	"scraping.mobile_emulation":            "Scrape as Chrome on an Android phone, reading X's simpler mobile layout with the mobile selector chains. Try it when desktop selectors break.",
+	"scraping.scroll_delay_ms":             "Pause after each scroll of a timeline, in milliseconds (0 = 500).",
+	"scraping.scroll_jitter_ms":            "Up to this many milliseconds more are added to each pause at random (0 = 300).",
+	"scraping.max_idle_scrolls":            "Stop a timeline scrape after this many scrolls in a row find no new posts (0 = keep scrolling until the time runs out).",
+	"scraping.scrape_seconds_per_post":     "Time a timeline scrape may take per post requested, in seconds (0 = 1). Raise it on slow connections.",
+	"scraping.min_scrape_timeout_seconds":  "Least time a timeline scrape may take, in seconds (0 = 60).",
+	"scraping.page_load_timeout_seconds":   "Time a page gets to show tweets or a login wall before the scrape fails, in seconds (0 = 30).",
+	"scraping.save_html_snapshots":         "Debugging: save the markup of the tweets each scrape sees, so `step reparse` can re-run extraction on it offline.",
+
+	"analysis.llm_provider":            `LLM provider: "anthropic".`,
+	"analysis.api_key":                 "API key for the LLM provider.",
+	"analysis.model":                   "Model used for analysis.",
+	"analysis.relevance_threshold":     "Minimum relevance score (0-1) for a post to make the digest.",
+	"analysis.batch_size":              "Posts sent to the LLM per request.",
+	"analysis.exclude_engagement_bait": "Drop posts the LLM flags as engagement bait, whatever their relevance.",
+	"analysis.min_like_rate":           "Drop posts with a known view count whose likes per view fall below this (0 = off).",
+	"analysis.feedback_weight":         "How far your ratings of an author's posts, or of posts matching a keyword, move the relevance of their later posts, at most (0 = off).",
+	"analysis.urgency_threshold":       "Relevant posts the LLM rates at least this urgent (0-1; outages, security advisories, breaking news) trigger a desktop notification during the run (0 = off).",
+	"analysis.fetch_linked_articles":   "Fetch pages linked from preview cards and include an excerpt in the prompt.",
+	"analysis.enrich_authors":          "Fetch profiles (followers, bio, verification) of digest authors, at most weekly each, and include them in later prompts.",
+	"analysis.summarize_discussions":   "Read the replies to the most discussed digest posts and have the LLM rewrite their summaries to take in the discussion.",
+	"analysis.max_discussion_fetches":  "Digest posts whose replies summarize_discussions reads per run, most replied-to first (0 = 5).",
+	"analysis.quota_action":            `When the LLM rate limit runs low: "defer" remaining posts to the next run, "downgrade" to fallback_model, or "fail".`,
+	"analysis.fallback_model":          `Cheaper model used when quota_action is "downgrade".`,
+	"analysis.base_url":                "Provider API URL to send requests to instead, e.g. a LiteLLM or Portkey gateway. Empty means the provider's own API.",
+	"analysis.extra_headers":           `Headers sent with every LLM request, e.g. {"x-portkey-api-key" = "..."} for a gateway.`,
+	"analysis.prompt_language":         `Language the LLM writes summaries, topics, and the trending note in, as a name or code (e.g. "German" or "de"), whatever language each post is in. Empty leaves it to the model, which tends to follow each post.`,
+	"analysis.triage_model":            "Cheap model that first picks out possibly relevant posts, so only those get a full analysis (empty = analyze every post).",
+
+	"digest.output_dir":              "Directory digests are saved to.",
+	"digest.max_posts":               "Maximum posts per digest, not counting mentions.",
+	"digest.headlines_window_hours":  "Only posts newer than this are considered for a headlines digest.",
+	"digest.download_media":          "Download images and video thumbnails of digest posts and embed local copies.",
+	"digest.author_affinity_weight":  "How much an author's track record of making the digest shifts their posts' rank (0 = off).",
+	"digest.repetition_penalty":      "How hard posts about subjects already in recent digests are pushed down: each earlier post on the same subject multiplies the rank by 1 minus this (0 = off).",
+	"digest.ranker":                  `How digest posts are ordered: "score", "engagement", "recency", or "mmr" (diverse subjects).`,
+	"digest.engagement_weight":       "Engagement bonus for the most engaging post under the engagement ranker (0 = 0.3).",
+	"digest.recency_half_life_hours": "Post age at which the recency ranker halves a score (0 = 24).",
+	"digest.mmr_lambda":              "Trade-off between score (1) and diversity (lower) under the mmr ranker (0 = 0.7).",
+	"digest.group_by_community":      "Give posts from X Communities a digest section per community.",
+	"digest.group_by_topic":          "Have the LLM group the digest's posts into topic sections, e.g. \"AI\" and \"Politics\", instead of one list.",
+	"digest.feedback_port":           "Localhost port the tray app serves rating links on; digest posts get 👍/👎 links when set (0 = no links).",
+	"digest.article_excerpts":        "Show the opening of each fetched linked article under its link card (needs analysis.fetch_linked_articles).",
+	"digest.format":                  `Digest file format: "markdown" (.md) or "text" (.txt: plain text with no markup, wrapped lines, and links numbered at the bottom).`,
+	"digest.text_width":              `Column width plain-text digests are wrapped to (0 = 72).`,
+
+	"pipeline.max_minutes": "Once a digest run has taken this many minutes, skip the optional enrichment still to come (thread unrolls, linked articles, trends, author profiles, discussion summaries, topic sections, media) and note it in the digest (0 = no limit).",
+
+	"sync.webdav.url":           "WebDAV collection URL each new digest is uploaded to. Empty disables WebDAV sync.",
+	"sync.webdav.username":      "WebDAV username.",
+	"sync.webdav.password":      "WebDAV password.",
+	"sync.s3.endpoint":          "S3-compatible endpoint URL. Empty disables S3 sync.",
+	"sync.s3.region":            "S3 region.",
+	"sync.s3.bucket":            "S3 bucket each new digest is uploaded to.",
+	"sync.s3.prefix":            "Key prefix for uploaded digests.",
+	"sync.s3.access_key_id":     "S3 access key ID.",
+	"sync.s3.secret_access_key": "S3 secret access key.",
+	"sync.git.repo_dir":         "Local git clone each new digest is committed into. Empty disables git sync.",
+	"sync.git.push":             "Push after each digest commit.",
+
+	"profiles": `Named interest profiles, each a [profiles.<name>] table with its own relevance_threshold (0 = analysis.relevance_threshold) and [profiles.<name>.interests] (keys as in [interests]). "scroll4me step all -profile work,news" analyzes one scrape against each and builds a digest per profile.`,
+}
+
+// Options lists every config key in file order, with its type, its default
+// from Default, and what it does
+func Options() []Option {
+	var options []Option
+	collectOptions(&options, "", reflect.ValueOf(*Default()))
+	return options
+}
+
+// collectOptions appends an Option for each leaf field of v under prefix
+func collectOptions(options *[]Option, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := range t.NumField() {
+		key := t.Field(i).Tag.Get("toml")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			collectOptions(options, key, field)
+			continue
+		}
+		*options = append(*options, Option{
+			Key:         key,
+			Type:        typeName(field.Type()),
+			Default:     formatDefault(field),
+			Description: descriptions[key],
+		})
+	}
+}
+
+// typeName names a config field's type the way the TOML file sees it
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int64:
+		return "integer"
+	case reflect.Float64:
+		return "number"
+	case reflect.Map:
+		if t.Elem() == reflect.TypeOf(InterestProfile{}) {
+			return "table of profiles"
+		}
+		return "table of " + typeName(t.Elem()) + "s"
+	case reflect.Slice:
+		switch t.Elem() {
+		case reflect.TypeOf(Keyword{}):
+			return "list of keywords"
+		case reflect.TypeOf(PriorityAccount{}):
+			return "list of accounts"
+		}
+		return "list of " + typeName(t.Elem()) + "s"
+	default:
+		return t.String()
+	}
+}
+
+// formatDefault writes a default value as it would appear in the TOML file
+func formatDefault(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return strconv.Quote(v.String())
+	case reflect.Map:
+		if v.Len() == 0 {
+			return "{}"
+		}
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = formatDefault(v.Index(i))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.Struct:
+		if m, ok := v.Interface().(toml.Marshaler); ok {
+			text, _ := m.MarshalTOML()
+			return string(text)
+		}
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// LookupOptions returns the options whose key is key, or which lie in the
+// section key names (e.g. "scraping" or "sync.s3"). Reports false if there
+// are none.
+func LookupOptions(key string) ([]Option, bool) {
+	var matched []Option
+	for _, o := range Options() {
+		if o.Key == key || strings.HasPrefix(o.Key, key+".") {
+			matched = append(matched, o)
+		}
+	}
+	return matched, len(matched) > 0
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// profileNamePattern matches valid interest profile names, which end up in
+// digest filenames
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Validate checks settings that would otherwise only fail (or silently
+// misbehave) partway through a run. It returns every problem found, joined,
+// or nil if there are none. Empty values that older configs leave unset are
+// accepted.
+func (c *Config) Validate() error {
+	var errs []error
+	problem := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	// [scraping]
+	s := c.Scraping
+	if s.PostsPerScrape <= 0 {
+		problem("scraping.posts_per_scrape must be positive, got %d", s.PostsPerScrape)
+	}
+	switch s.Feed {
+	case FeedForYou, FeedFollowing, FeedNone, "":
+	default:
+		problem("scraping.feed: unknown feed %q (use %q, %q, or %q)", s.Feed, FeedForYou, FeedFollowing, FeedNone)
+	}
+	switch s.StealthLevel {
+	case "off", "low", "high", "":
+	default:
+		problem("scraping.stealth_level: unknown level %q (use \"off\", \"low\", or \"high\")", s.StealthLevel)
+	}
+	if s.MinScrapeIntervalSeconds < 0 {
+		problem("scraping.min_scrape_interval_seconds must not be negative, got %d", s.MinScrapeIntervalSeconds)
+	}
+	if s.DailyLaunchBudget < 0 {
+		problem("scraping.daily_launch_budget must not be negative, got %d", s.DailyLaunchBudget)
+	}
+	if s.StopAfterKnownPosts < 0 {
+		problem("scraping.stop_after_known_posts must not be negative, got %d", s.StopAfterKnownPosts)
+	}
+	for _, lang := range s.Languages {
+		if l := strings.TrimSpace(lang); l == "" || strings.ContainsAny(l, " ,_") {
+			problem("scraping.languages: invalid language code %q (use codes like \"en\" or \"pt-BR\")", lang)
+		}
+	}
+	for key, v := range map[string]float64{
+		"scroll_delay_ms":            float64(s.ScrollDelayMs),
+		"scroll_jitter_ms":           float64(s.ScrollJitterMs),
+		"max_idle_scrolls":           float64(s.MaxIdleScrolls),
+		"scrape_seconds_per_post":    s.ScrapeSecondsPerPost,
+		"min_scrape_timeout_seconds": float64(s.MinScrapeTimeoutSeconds),
+		"page_load_timeout_seconds":  float64(s.PageLoadTimeoutSeconds),
+	} {
+		if v < 0 {
+			problem("scraping.%s must not be negative, got %g", key, v)
+		}
+	}
+	if s.RemoteDebuggingURL != "" {
+		u, err := url.Parse(s.RemoteDebuggingURL)
+		switch {
+		case err != nil:
+			problem("scraping.remote_debugging_url: %v", err)
+		case u.Host == "" || (u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "ws" && u.Scheme != "wss"):
+			problem("scraping.remote_debugging_url must be an http:// or ws:// DevTools URL, got %q", s.RemoteDebuggingURL)
+		}
+	}
+
+	// [analysis]
+	an := c.Analysis
+	switch an.LLMProvider {
+	case ProviderAnthropic:
+	default:
+		problem("analysis.llm_provider: unknown provider %q (use %q)", an.LLMProvider, ProviderAnthropic)
+	}
+	if an.Model == "" {
+		problem("analysis.model is empty")
+	}
+	if an.RelevanceThreshold < 0 || an.RelevanceThreshold > 1 {
+		problem("analysis.relevance_threshold must be between 0 and 1, got %g", an.RelevanceThreshold)
+	}
+	if an.BatchSize <= 0 {
+		problem("analysis.batch_size must be positive, got %d", an.BatchSize)
+	}
+	if an.MinLikeRate < 0 || an.MinLikeRate > 1 {
+		problem("analysis.min_like_rate must be between 0 and 1, got %g", an.MinLikeRate)
+	}
+	if an.FeedbackWeight < 0 || an.FeedbackWeight > 1 {
+		problem("analysis.feedback_weight must be between 0 and 1, got %g", an.FeedbackWeight)
+	}
+	if an.MaxDiscussionFetches < 0 {
+		problem("analysis.max_discussion_fetches must not be negative, got %d", an.MaxDiscussionFetches)
+	}
+	if an.UrgencyThreshold < 0 || an.UrgencyThreshold > 1 {
+		problem("analysis.urgency_threshold must be between 0 and 1, got %g", an.UrgencyThreshold)
+	}
+	if an.BaseURL != "" {
+		u, err := url.Parse(an.BaseURL)
+		switch {
+		case err != nil:
+			problem("analysis.base_url: %v", err)
+		case u.Host == "" || (u.Scheme != "http" && u.Scheme != "https"):
+			problem("analysis.base_url must be an http:// or https:// URL, got %q", an.BaseURL)
+		}
+	}
+	for name := range an.ExtraHeaders {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			problem("analysis.extra_headers: invalid header name %q", name)
+		}
+	}
+	if strings.ContainsAny(an.PromptLanguage, "\r\n") {
+		problem("analysis.prompt_language must be a single line, got %q", an.PromptLanguage)
+	}
+	switch an.QuotaAction {
+	case QuotaActionDefer, QuotaActionFail, "":
+	case QuotaActionDowngrade:
+		if an.FallbackModel == "" {
+			problem("analysis.quota_action %q requires analysis.fallback_model", QuotaActionDowngrade)
+		}
+	default:
+		problem("analysis.quota_action: unknown action %q (use %q, %q, or %q)", an.QuotaAction,
+			QuotaActionDefer, QuotaActionDowngrade, QuotaActionFail)
+	}
+
+	// [interests]
+	checkInterests := func(section string, in InterestsConfig) {
+		for _, k := range in.Keywords {
+			if k.Keyword == "" {
+				problem("%s.keywords: keyword with an empty name", section)
+			}
+			if k.Weight < 0 {
+				problem("%s.keywords: %q has a negative weight (%g)", section, k.Keyword, k.Weight)
+			}
+			if k.MinScore < 0 || k.MinScore > 1 {
+				problem("%s.keywords: %q has a min_score outside 0-1 (%g)", section, k.Keyword, k.MinScore)
+			}
+		}
+		for _, a := range in.PriorityAccounts {
+			if a.Handle == "" {
+				problem("%s.priority_accounts: account with an empty handle", section)
+			}
+			if a.Weight < 0 {
+				problem("%s.priority_accounts: %q has a negative weight (%g)", section, a.Handle, a.Weight)
+			}
+			if a.MinScore < 0 || a.MinScore > 1 {
+				problem("%s.priority_accounts: %q has a min_score outside 0-1 (%g)", section, a.Handle, a.MinScore)
+			}
+		}
+	}
+	checkInterests("interests", c.Interests)
+
+	// [digest]
+	if c.Digest.MaxPosts < 0 {
+		problem("digest.max_posts must not be negative, got %d", c.Digest.MaxPosts)
+	}
+	if c.Digest.AuthorAffinityWeight < 0 {
+		problem("digest.author_affinity_weight must not be negative, got %g", c.Digest.AuthorAffinityWeight)
+	}
+	if c.Digest.FeedbackPort < 0 || c.Digest.FeedbackPort > 65535 {
+		problem("digest.feedback_port must be between 0 and 65535, got %d", c.Digest.FeedbackPort)
+	}
+	if c.Digest.RepetitionPenalty < 0 || c.Digest.RepetitionPenalty >= 1 {
+		problem("digest.repetition_penalty must be at least 0 and below 1, got %g", c.Digest.RepetitionPenalty)
+	}
+	switch c.Digest.Ranker {
+	case RankerScore, RankerEngagement, RankerRecency, RankerMMR, "":
+	default:
+		problem("digest.ranker: unknown ranker %q (use %q, %q, %q, or %q)", c.Digest.Ranker,
+			RankerScore, RankerEngagement, RankerRecency, RankerMMR)
+	}
+	if c.Digest.EngagementWeight < 0 {
+		problem("digest.engagement_weight must not be negative, got %g", c.Digest.EngagementWeight)
+	}
+	if c.Digest.RecencyHalfLifeHours < 0 {
+		problem("digest.recency_half_life_hours must not be negative, got %g", c.Digest.RecencyHalfLifeHours)
+	}
+	if c.Digest.MMRLambda < 0 || c.Digest.MMRLambda > 1 {
+		problem("digest.mmr_lambda must be between 0 and 1, got %g", c.Digest.MMRLambda)
+	}
+	switch c.Digest.Format {
+	case FormatMarkdown, FormatText, "":
+	default:
+		problem("digest.format: unknown format %q (use %q or %q)", c.Digest.Format, FormatMarkdown, FormatText)
+	}
+	if c.Digest.TextWidth < 0 {
+		problem("digest.text_width must not be negative, got %d", c.Digest.TextWidth)
+	}
+
+	// [pipeline]
+	if c.Pipeline.MaxMinutes < 0 {
+		problem("pipeline.max_minutes must not be negative, got %d", c.Pipeline.MaxMinutes)
+	}
+
+	// [profiles]
+	for _, name := range slices.Sorted(maps.Keys(c.Profiles)) {
+		p := c.Profiles[name]
+		if !profileNamePattern.MatchString(name) {
+			problem("profiles.%s: profile names may only contain letters, digits, - and _", name)
+		}
+		if p.RelevanceThreshold < 0 || p.RelevanceThreshold > 1 {
+			problem("profiles.%s.relevance_threshold must be between 0 and 1, got %g", name, p.RelevanceThreshold)
+		}
+		checkInterests("profiles."+name+".interests", p.Interests)
+	}
+
+	return errors.Join(errs...)
+}
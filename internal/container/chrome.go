@@ -0,0 +1,116 @@
+// Package container runs a headless Chrome in Docker for the scraper to
+// drive over the DevTools protocol, isolating Chrome from the host and
+// making server deployments reproducible.
+package container
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// DefaultChromeImage is the headless Chrome image used when none is given.
+// Pass a version tag (e.g. chromedp/headless-shell:<version>) to pin Chrome.
+const DefaultChromeImage = "chromedp/headless-shell:stable"
+
+// devToolsPort is the port headless-shell serves DevTools on inside the container
+const devToolsPort = "9222/tcp"
+
+// startupTimeout bounds how long Chrome may take to accept DevTools connections
+const startupTimeout = 30 * time.Second
+
+// Chrome is a running headless Chrome container
+type Chrome struct {
+	id  string
+	url string
+}
+
+// StartChrome runs image in a detached, auto-removed container with its
+// DevTools port published on localhost, and waits until Chrome is ready.
+// It uses the docker CLI, so Docker must be installed and running.
+func StartChrome(ctx context.Context, image string) (*Chrome, error) {
+	if image == "" {
+		image = DefaultChromeImage
+	}
+
+	// Chrome needs more shared memory than Docker's 64MB default
+	id, err := docker(ctx, "run", "--detach", "--rm", "--shm-size=1g", "--publish", "127.0.0.1::9222", image)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", image, err)
+	}
+	c := &Chrome{id: id}
+
+	addr, err := docker(ctx, "port", id, devToolsPort)
+	if err != nil {
+		c.Stop()
+		return nil, fmt.Errorf("failed to find published DevTools port: %w", err)
+	}
+	// May list several bindings (IPv4 and IPv6); take the first
+	addr, _, _ = strings.Cut(addr, "\n")
+	c.url = "http://" + strings.TrimSpace(addr)
+
+	if err := c.waitReady(ctx); err != nil {
+		c.Stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+// URL returns the DevTools endpoint, for chromedp.NewRemoteAllocator
+func (c *Chrome) URL() string {
+	return c.url
+}
+
+// Stop stops the container (which also removes it)
+func (c *Chrome) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	_, err := docker(ctx, "stop", "--time", "5", c.id)
+	return err
+}
+
+// waitReady polls the DevTools version endpoint until Chrome answers
+func (c *Chrome) waitReady(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, startupTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+"/json/version", nil)
+		if err != nil {
+			return err
+		}
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("chrome container didn't become ready within %v", startupTimeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// docker runs a docker CLI command and returns its trimmed stdout
+func docker(ctx context.Context, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
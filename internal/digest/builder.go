@@ -2,19 +2,67 @@ package digest
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/ibeckermayer/scroll4me/internal/article"
 	"github.com/ibeckermayer/scroll4me/internal/types"
 )
 
+// maxTrendsListed caps the trends listed under the trending context
+const maxTrendsListed = 10
+
+// articleLeadChars caps the article opening shown under a link card
+const articleLeadChars = 300
+
+// Digest filenames are "<timestamp>-digest.md", or "<timestamp>-digest.txt"
+// in plain text. A digest built for an interest profile has the profile's
+// name after the timestamp: "<timestamp>-<profile>-digest.md".
+const (
+	digestTimeFormat = "2006-01-02-150405"
+	digestSuffix     = "-digest.md"
+	textDigestSuffix = "-digest.txt"
+)
+
 // Builder creates markdown digest files from analyzed posts
 type Builder struct {
 	outputDir string
 	maxPosts  int
+	habits    *ReadingHabits // Rendered as a closing section if set
+	reduced   string         // Why this is a reduced run, if it is
+	focus     time.Duration  // Reading time a focus digest was sized for, if it is one
+	// The run's time budget, and what it skipped for running over
+	budget        time.Duration
+	budgetSkipped []string
+	// If true, community posts are rendered in a section per community
+	groupByCommunity bool
+	trending         *Trending // Rendered as an opening section if set
+	// Candidate interests from the feed, offered while none are configured
+	suggestedTopics []types.InterestTopic
+	// If set, digests are saved as plain text wrapped to this many columns
+	textWidth int
+	unread    []Digest // Earlier digests never opened, listed in an opening section
+	// If true, link cards show the opening of their fetched article
+	articleExcerpts bool
+	// If true, posts are rendered in their topic sections
+	groupByTopic bool
+	// Base URL of the local feedback server, if posts get rating links,
+	// and the token the links carry
+	feedbackURL   string
+	feedbackToken string
+	profile       string // Interest profile the digest was built for, if any
+	muted         int    // Posts left out for matching a mute, counted in the header
+}
+
+// Trending is what's trending on X when the digest is built, with an LLM
+// written note putting it in context
+type Trending struct {
+	Summary string // May be empty if summarizing failed
+	Trends  []types.Trend
 }
 
 // New creates a new digest builder
@@ -25,6 +73,106 @@ func New(outputDir string, maxPosts int) *Builder {
 	}
 }
 
+// ReadingHabits summarizes how digests have been read, from the local usage
+// log
+type ReadingHabits struct {
+	Since     time.Time
+	Generated int // Digests generated
+	Reopened  int // Of those, how many were opened again later
+	Opens     int // Later opens in total
+	// Average time from generating a digest to first opening it again,
+	// over the reopened ones
+	AvgTimeToReopen time.Duration
+}
+
+// SetReadingHabits includes a "your reading habits" section in digests
+// rendered from now on.
+func (b *Builder) SetReadingHabits(h *ReadingHabits) {
+	b.habits = h
+}
+
+// SetReducedRun marks digests rendered from now on as coming from a
+// reduced-scope retry of a failed run, explaining why under the header.
+func (b *Builder) SetReducedRun(reason string) {
+	b.reduced = reason
+}
+
+// SetOverBudget notes under the header of digests rendered from now on that
+// the run went over its time budget and skipped the given steps
+func (b *Builder) SetOverBudget(budget time.Duration, skipped []string) {
+	b.budget = budget
+	b.budgetSkipped = skipped
+}
+
+// SetFocus marks digests rendered from now on as focus digests sized for
+// the given reading time, noting it and the estimate under the header
+func (b *Builder) SetFocus(readingTime time.Duration) {
+	b.focus = readingTime
+}
+
+// SetGroupByCommunity sets whether posts from X Communities are rendered in
+// a section per community, after the other posts
+func (b *Builder) SetGroupByCommunity(on bool) {
+	b.groupByCommunity = on
+}
+
+// SetGroupByTopic sets whether posts are rendered in a section per topic
+// (see types.PostWithAnalysis.Section), in order of each section's best post
+func (b *Builder) SetGroupByTopic(on bool) {
+	b.groupByTopic = on
+}
+
+// SetFeedbackURL gives each post in digests rendered from now on 👍/👎
+// links to baseURL's /rate endpoint, carrying token. "" leaves them out.
+func (b *Builder) SetFeedbackURL(baseURL, token string) {
+	b.feedbackURL = baseURL
+	b.feedbackToken = token
+}
+
+// SetProfile names the interest profile digests rendered and saved from now
+// on were built for, in their title and filename. "" is none.
+func (b *Builder) SetProfile(name string) {
+	b.profile = name
+}
+
+// SetMuted notes in the header of digests rendered from now on that n of
+// the scraped posts were left out for matching a muted account or keyword
+func (b *Builder) SetMuted(n int) {
+	b.muted = n
+}
+
+// SetArticleExcerpts makes link cards in digests rendered from now on show
+// the opening of their linked article, where one was fetched
+func (b *Builder) SetArticleExcerpts(on bool) {
+	b.articleExcerpts = on
+}
+
+// SetTrending opens digests rendered from now on with a "trending" section
+func (b *Builder) SetTrending(t *Trending) {
+	b.trending = t
+}
+
+// SetSuggestedTopics opens digests rendered from now on with candidate
+// interests to pick from, for a user who hasn't configured any
+func (b *Builder) SetSuggestedTopics(topics []types.InterestTopic) {
+	b.suggestedTopics = topics
+}
+
+// SetUnread opens digests rendered from now on with a list of earlier
+// digests that were never opened
+func (b *Builder) SetUnread(digests []Digest) {
+	b.unread = digests
+}
+
+// SetPlainText makes Save write digests as plain text wrapped to width
+// columns (0 = DefaultTextWidth) instead of markdown
+func (b *Builder) SetPlainText(width int) {
+	if width <= 0 {
+		width = DefaultTextWidth
+	}
+	b.textWidth = width
+}
+
 // Content holds the rendered digest content (pure data, no side effects).
 type Content struct {
 	Markdown  string
@@ -37,42 +185,51 @@ type Digest struct {
 	FilePath  string
 	PostCount int
 	CreatedAt time.Time
+	Profile   string // Interest profile it was built for, if any
 }
 
 // Render generates markdown content from analyzed posts without writing to disk.
+// Posts are rendered in the order given, so they should already be ranked.
 func (b *Builder) Render(posts []types.PostWithAnalysis, totalScraped int) (*Content, error) {
+	return b.RenderAt(posts, totalScraped, time.Now())
+}
+
+// RenderAt is like Render but stamps the digest with the given creation time,
+// e.g. when re-rendering a digest from the archive.
+func (b *Builder) RenderAt(posts []types.PostWithAnalysis, totalScraped int, createdAt time.Time) (*Content, error) {
 	if len(posts) == 0 {
 		return nil, fmt.Errorf("no posts to include in digest")
 	}
 
-	// Sort by relevance score descending
-	sort.Slice(posts, func(i, j int) bool {
-		if posts[i].Analysis == nil {
-			return false
+	// Mentions are rendered in their own section and don't count toward max posts
+	var mentions []types.PostWithAnalysis
+	var feedPosts []types.PostWithAnalysis
+	for _, p := range posts {
+		if p.Post.Source == types.SourceMentions {
+			mentions = append(mentions, p)
+		} else {
+			feedPosts = append(feedPosts, p)
 		}
-		if posts[j].Analysis == nil {
-			return true
-		}
-		return posts[i].Analysis.RelevanceScore > posts[j].Analysis.RelevanceScore
-	})
+	}
+	posts = feedPosts
 
-	// Limit to max posts
+	// Limit to max posts (they arrive ranked best first, see internal/ranking)
 	if len(posts) > b.maxPosts {
 		posts = posts[:b.maxPosts]
 	}
 
-	now := time.Now()
-	markdown := b.buildMarkdown(posts, now, totalScraped)
+	markdown := b.buildMarkdown(posts, mentions, createdAt, totalScraped)
 
 	return &Content{
 		Markdown:  markdown,
-		PostCount: len(posts),
-		CreatedAt: now,
+		PostCount: len(posts) + len(mentions),
+		CreatedAt: createdAt,
 	}, nil
 }
 
-// Save writes the digest content to the user-configured output directory.
-// Returns the saved Digest with file path.
+// Save writes the digest content to the user-configured output directory,
+// as plain text if SetPlainText was called. Returns the saved Digest with
+// file path.
 func (b *Builder) Save(content *Content) (*Digest, error) {
 	// Ensure output directory exists
 	if err := os.MkdirAll(b.outputDir, 0755); err != nil {
@@ -80,11 +237,20 @@ func (b *Builder) Save(content *Content) (*Digest, error) {
 	}
 
 	// Generate filename
-	filename := fmt.Sprintf("%s-digest.md", content.CreatedAt.Format("2006-01-02-150405"))
+	stamp := content.CreatedAt.Format(digestTimeFormat)
+	if b.profile != "" {
+		stamp += "-" + b.profile
+	}
+	filename := stamp + digestSuffix
+	data := content.Markdown
+	if b.textWidth > 0 {
+		filename = stamp + textDigestSuffix
+		data = PlainText(content.Markdown, b.textWidth)
+	}
 	filePath := filepath.Join(b.outputDir, filename)
 
 	// Write file
-	if err := os.WriteFile(filePath, []byte(content.Markdown), 0644); err != nil {
+	if err := os.WriteFile(filePath, []byte(data), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write digest file: %w", err)
 	}
 
@@ -92,6 +258,7 @@ func (b *Builder) Save(content *Content) (*Digest, error) {
 		FilePath:  filePath,
 		PostCount: content.PostCount,
 		CreatedAt: content.CreatedAt,
+		Profile:   b.profile,
 	}, nil
 }
 
@@ -106,19 +273,105 @@ func (b *Builder) Build(posts []types.PostWithAnalysis, totalScraped int) (*Dige
 }
 
 // buildMarkdown generates the markdown content
-func (b *Builder) buildMarkdown(posts []types.PostWithAnalysis, now time.Time, totalScraped int) string {
+func (b *Builder) buildMarkdown(posts []types.PostWithAnalysis, mentions []types.PostWithAnalysis, now time.Time, totalScraped int) string {
 	var sb strings.Builder
 
 	// Header
-	sb.WriteString("# X Digest\n\n")
+	if b.profile != "" {
+		sb.WriteString(fmt.Sprintf("# X Digest: %s\n\n", b.profile))
+	} else {
+		sb.WriteString("# X Digest\n\n")
+	}
 	sb.WriteString(fmt.Sprintf("**Generated:** %s\n\n", now.Format("Monday, January 2, 2006 at 3:04 PM")))
-	sb.WriteString(fmt.Sprintf("**Posts:** %d selected from %d scraped\n\n", len(posts), totalScraped))
+	if b.muted > 0 {
+		sb.WriteString(fmt.Sprintf("**Posts:** %d selected from %d scraped (%d muted)\n\n", len(posts), totalScraped, b.muted))
+	} else {
+		sb.WriteString(fmt.Sprintf("**Posts:** %d selected from %d scraped\n\n", len(posts), totalScraped))
+	}
+	if b.reduced != "" {
+		sb.WriteString(fmt.Sprintf("> ⚠️ **Reduced run:** %s\n\n", b.reduced))
+	}
+	if len(b.budgetSkipped) > 0 {
+		sb.WriteString(fmt.Sprintf("> ⏳ **Over time budget:** the run took longer than %d minutes, so it skipped %s.\n\n",
+			roundMinutes(b.budget), strings.Join(b.budgetSkipped, ", ")))
+	}
+	if b.focus > 0 {
+		var estimate time.Duration
+		for _, p := range posts {
+			estimate += ReadingTime(p)
+		}
+		sb.WriteString(fmt.Sprintf("> ⏱️ **Focus mode:** sized for a %d-minute read (about %d min here). That's all for now.\n\n",
+			roundMinutes(b.focus), roundMinutes(estimate)))
+	}
 	sb.WriteString("---\n\n")
 
-	// Posts
-	for i, p := range posts {
-		sb.WriteString(b.formatPost(i+1, p))
-		sb.WriteString("\n---\n\n")
+	if len(b.unread) > 0 {
+		sb.WriteString(formatUnread(b.unread, now))
+		sb.WriteString("---\n\n")
+	}
+
+	if len(b.suggestedTopics) > 0 {
+		sb.WriteString(formatSuggestedTopics(b.suggestedTopics))
+		sb.WriteString("---\n\n")
+	}
+
+	if b.trending != nil {
+		sb.WriteString(formatTrending(b.trending))
+		sb.WriteString("---\n\n")
+	}
+
+	// Posts, in a section per topic if grouping, then a section per
+	// community if grouping
+	var communities []postSection
+	if b.groupByCommunity {
+		posts, communities = groupByCommunity(posts)
+	}
+	var topics []postSection
+	if b.groupByTopic {
+		topics = groupByTopic(posts)
+	}
+	num := 0
+	if len(topics) == 0 {
+		for _, p := range posts {
+			num++
+			sb.WriteString(b.formatPost(num, p))
+			sb.WriteString("\n---\n\n")
+		}
+	}
+	for _, t := range topics {
+		sb.WriteString(fmt.Sprintf("# 🗂️ %s\n\n", t.name))
+		sb.WriteString("---\n\n")
+		for _, p := range t.posts {
+			num++
+			sb.WriteString(b.formatPost(num, p))
+			sb.WriteString("\n---\n\n")
+		}
+	}
+	for _, c := range communities {
+		sb.WriteString(fmt.Sprintf("# 👥 %s\n\n", c.name))
+		sb.WriteString("---\n\n")
+		for _, p := range c.posts {
+			num++
+			sb.WriteString(b.formatPost(num, p))
+			sb.WriteString("\n---\n\n")
+		}
+	}
+
+	// Mentions section
+	if len(mentions) > 0 {
+		sb.WriteString("# 💬 Mentions\n\n")
+		sb.WriteString("*People talking to or about you*\n\n")
+		sb.WriteString("---\n\n")
+		for _, p := range mentions {
+			num++
+			sb.WriteString(b.formatPost(num, p))
+			sb.WriteString("\n---\n\n")
+		}
+	}
+
+	if b.habits != nil {
+		sb.WriteString(formatReadingHabits(b.habits))
+		sb.WriteString("---\n\n")
 	}
 
 	// Footer
@@ -127,6 +380,219 @@ func (b *Builder) buildMarkdown(posts []types.PostWithAnalysis, now time.Time, t
 	return sb.String()
 }
 
+// postSection is the posts of one community or topic, in ranked order
+type postSection struct {
+	name  string
+	posts []types.PostWithAnalysis
+}
+
+// groupByCommunity splits community posts from the rest, grouped by
+// community in order of each community's best post
+func groupByCommunity(posts []types.PostWithAnalysis) (rest []types.PostWithAnalysis, communities []postSection) {
+	index := make(map[string]int)
+	for _, p := range posts {
+		if p.Post.Community == "" {
+			rest = append(rest, p)
+			continue
+		}
+		i, ok := index[p.Post.Community]
+		if !ok {
+			i = len(communities)
+			index[p.Post.Community] = i
+			communities = append(communities, postSection{name: p.Post.Community})
+		}
+		communities[i].posts = append(communities[i].posts, p)
+	}
+	return rest, communities
+}
+
+// otherSection is the topic section posts in no other section go in
+const otherSection = "Other"
+
+// groupByTopic groups posts by topic section, in order of each section's
+// best post, with posts in no section in a closing "Other" section.
+// Returns nil if no post has a section.
+func groupByTopic(posts []types.PostWithAnalysis) []postSection {
+	if !slices.ContainsFunc(posts, func(p types.PostWithAnalysis) bool { return p.Section != "" }) {
+		return nil
+	}
+
+	var sections []postSection
+	other := postSection{name: otherSection}
+	index := make(map[string]int)
+	for _, p := range posts {
+		if p.Section == "" || strings.EqualFold(p.Section, otherSection) {
+			other.posts = append(other.posts, p)
+			continue
+		}
+		i, ok := index[p.Section]
+		if !ok {
+			i = len(sections)
+			index[p.Section] = i
+			sections = append(sections, postSection{name: p.Section})
+		}
+		sections[i].posts = append(sections[i].posts, p)
+	}
+	if len(other.posts) > 0 {
+		sections = append(sections, other)
+	}
+	return sections
+}
+
+// formatTrending formats the trending section
+func formatTrending(t *Trending) string {
+	var sb strings.Builder
+	sb.WriteString("# 🔥 Trending\n\n")
+	if t.Summary != "" {
+		sb.WriteString(strings.TrimSpace(t.Summary) + "\n\n")
+	}
+	for _, trend := range t.Trends[:min(len(t.Trends), maxTrendsListed)] {
+		sb.WriteString(fmt.Sprintf("- **%s**", trend.Name))
+		if trend.Category != "" {
+			sb.WriteString(" · " + trend.Category)
+		}
+		if trend.PostCount > 0 {
+			sb.WriteString(fmt.Sprintf(" · %d posts", trend.PostCount))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatSuggestedTopics formats the candidate interests section
+func formatSuggestedTopics(topics []types.InterestTopic) string {
+	var sb strings.Builder
+	sb.WriteString("# 🌱 Pick Your Interests\n\n")
+	sb.WriteString("No interests are configured yet, so these posts were scored on general quality alone. ")
+	sb.WriteString("These subjects came up most in your feed. Run `scroll4me config bootstrap` to pick the ones you care about, ")
+	sb.WriteString("or add them to `interests.keywords` in the config.\n\n")
+	for _, t := range topics {
+		sb.WriteString(fmt.Sprintf("- **%s**", t.Keyword))
+		if t.Description != "" {
+			sb.WriteString(" · " + t.Description)
+		}
+		if t.PostCount > 0 {
+			sb.WriteString(fmt.Sprintf(" · %d posts", t.PostCount))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// maxRetweetersListed caps the handles named on a post's repost line
+const maxRetweetersListed = 5
+
+// formatRetweeters formats the line naming who reposted a post, or returns
+// "" if it wasn't scraped as a repost
+func formatRetweeters(handles []string) string {
+	switch len(handles) {
+	case 0:
+		return ""
+	case 1:
+		return fmt.Sprintf("🔁 Reposted by @%s", handles[0])
+	}
+	named := make([]string, 0, maxRetweetersListed)
+	for _, h := range handles[:min(len(handles), maxRetweetersListed)] {
+		named = append(named, "@"+h)
+	}
+	line := fmt.Sprintf("🔁 Shared by %d accounts: %s", len(handles), strings.Join(named, ", "))
+	if extra := len(handles) - len(named); extra > 0 {
+		line += fmt.Sprintf(" and %d more", extra)
+	}
+	return line
+}
+
+// formatAlsoCoveredBy formats the line linking the near-duplicates collapsed
+// into a post, or returns "" if there are none
+func formatAlsoCoveredBy(refs []types.PostRef) string {
+	if len(refs) == 0 {
+		return ""
+	}
+	named := make([]string, 0, maxRetweetersListed)
+	for _, r := range refs[:min(len(refs), maxRetweetersListed)] {
+		named = append(named, fmt.Sprintf("[@%s](%s)", r.AuthorHandle, r.URL))
+	}
+	line := "📣 Also covered by " + strings.Join(named, ", ")
+	if extra := len(refs) - len(named); extra > 0 {
+		line += fmt.Sprintf(" and %d more", extra)
+	}
+	return line
+}
+
+// formatUnread formats the section listing unread earlier digests, linked
+// by file name since they're saved alongside this one. now is when this
+// digest was made, which the heading counts back from.
+func formatUnread(digests []Digest, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString("# 📬 " + unreadTitle(digests, now) + "\n\n")
+	sb.WriteString("*Earlier digests that were never opened*\n\n")
+	for _, d := range digests {
+		name := filepath.Base(d.FilePath)
+		sb.WriteString(fmt.Sprintf("- [%s](%s)\n", d.CreatedAt.Format("Monday, January 2 at 3:04 PM"), name))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// unreadTitle names the unread section after the day the oldest of
+// digests was made, counted in calendar days back from now
+func unreadTitle(digests []Digest, now time.Time) string {
+	oldest := now
+	for _, d := range digests {
+		if d.CreatedAt.Before(oldest) {
+			oldest = d.CreatedAt
+		}
+	}
+	oldest = oldest.In(now.Location())
+	day := func(t time.Time) time.Time { return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC) }
+	switch days := int(day(now).Sub(day(oldest)).Hours() / 24); {
+	case days == 0:
+		return "Unread from Earlier Today"
+	case days == 1:
+		return "Unread from Yesterday"
+	case days < 7:
+		return "Unread Since " + oldest.Format("Monday")
+	default:
+		return "Unread Since " + oldest.Format("January 2")
+	}
+}
+
+// formatReadingHabits formats the reading habits section
+func formatReadingHabits(h *ReadingHabits) string {
+	var sb strings.Builder
+	sb.WriteString("# 📊 Your Reading Habits\n\n")
+	sb.WriteString(fmt.Sprintf("*Since %s, tracked locally*\n\n", h.Since.Format("January 2")))
+	sb.WriteString(fmt.Sprintf("- **Digests generated:** %d\n", h.Generated))
+	if h.Generated > 0 {
+		sb.WriteString(fmt.Sprintf("- **Came back to:** %d (%.0f%%)\n", h.Reopened, 100*float64(h.Reopened)/float64(h.Generated)))
+	}
+	sb.WriteString(fmt.Sprintf("- **Times reopened:** %d\n", h.Opens))
+	if h.Reopened > 0 {
+		sb.WriteString(fmt.Sprintf("- **Average time before coming back:** %s\n", formatDuration(h.AvgTimeToReopen)))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// formatDuration renders a duration coarsely, e.g. "45m", "3h 20m", "2d 4h"
+func formatDuration(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	default:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	}
+}
+
+// roundMinutes rounds a duration to whole minutes, at least 1
+func roundMinutes(d time.Duration) int {
+	return max(int(d.Round(time.Minute).Minutes()), 1)
+}
+
 // formatPost formats a single post for the digest
 func (b *Builder) formatPost(num int, p types.PostWithAnalysis) string {
 	var sb strings.Builder
@@ -136,8 +602,22 @@ func (b *Builder) formatPost(num int, p types.PostWithAnalysis) string {
 	if p.Post.AuthorName != "" && p.Post.AuthorName != p.Post.AuthorHandle {
 		sb.WriteString(fmt.Sprintf(" (%s)", p.Post.AuthorName))
 	}
+	if p.Post.AuthorVerified {
+		sb.WriteString(" ✓")
+	}
 	sb.WriteString("\n\n")
 
+	// Provenance badge
+	if badge := sourceBadge(p.Post); badge != "" {
+		sb.WriteString(fmt.Sprintf("`%s`\n\n", badge))
+	}
+	if line := formatRetweeters(p.Post.RetweetedBy); line != "" {
+		sb.WriteString(line + "\n\n")
+	}
+	if line := formatAlsoCoveredBy(p.Post.AlsoCoveredBy); line != "" {
+		sb.WriteString(line + "\n\n")
+	}
+
 	// Analysis summary
 	if p.Analysis != nil {
 		sb.WriteString(fmt.Sprintf("**Summary:** %s\n\n", p.Analysis.Summary))
@@ -149,24 +629,129 @@ func (b *Builder) formatPost(num int, p types.PostWithAnalysis) string {
 
 		// Relevance score
 		sb.WriteString(fmt.Sprintf("**Relevance:** %.0f%%\n\n", p.Analysis.RelevanceScore*100))
+
+		switch {
+		case p.Repeats == 1:
+			sb.WriteString("♻️ *You've seen a post about this in a recent digest.*\n\n")
+		case p.Repeats > 1:
+			sb.WriteString(fmt.Sprintf("♻️ *You've seen %d posts about this in recent digests.*\n\n", p.Repeats))
+		}
 	}
 
 	// Original content
 	sb.WriteString("### Post Content\n\n")
+	if r := p.Post.ReplyTo; r != nil {
+		sb.WriteString(fmt.Sprintf("↩️ In reply to **@%s**:\n\n", r.AuthorHandle))
+		sb.WriteString(fmt.Sprintf("> %s\n\n", formatQuote(r.Content)))
+		if r.OriginalURL != "" {
+			sb.WriteString(fmt.Sprintf("[View parent post](%s)\n\n", r.OriginalURL))
+		}
+	}
+	if n := len(p.Post.ThreadParts); n > 1 {
+		sb.WriteString(fmt.Sprintf("🧵 Thread of %d posts\n\n", n))
+	}
 	sb.WriteString(fmt.Sprintf("> %s\n\n", formatQuote(p.Post.Content)))
+	if p.Post.Edited {
+		sb.WriteString("✏️ *Edited since first seen.* Originally:\n\n")
+		sb.WriteString(fmt.Sprintf("> %s\n\n", formatQuote(p.Post.FirstSeenContent)))
+	}
+
+	// Locally cached media
+	for _, path := range p.Post.LocalMedia {
+		sb.WriteString(fmt.Sprintf("![](<%s>)\n\n", filepath.ToSlash(path)))
+	}
+
+	// Link preview card
+	if c := p.Post.Card; c != nil {
+		title := c.Title
+		if title == "" {
+			title = c.URL
+		}
+		sb.WriteString(fmt.Sprintf("📰 [%s](%s)", title, c.URL))
+		if c.Domain != "" {
+			sb.WriteString(fmt.Sprintf(" · %s", c.Domain))
+		}
+		sb.WriteString("\n\n")
+		if c.Description != "" {
+			sb.WriteString(fmt.Sprintf("*%s*\n\n", c.Description))
+		}
+		if b.articleExcerpts && c.Excerpt != "" {
+			sb.WriteString(fmt.Sprintf("📄 %s\n\n", article.Lead(c.Excerpt, articleLeadChars)))
+		}
+	}
+
+	// Poll results
+	if poll := p.Post.Poll; poll != nil {
+		state := "open"
+		if poll.Closed {
+			state = "final results"
+		}
+		sb.WriteString(fmt.Sprintf("🗳️ **Poll** (%d votes, %s)\n\n", poll.TotalVotes, state))
+		for _, c := range poll.Choices {
+			if c.Percent > 0 {
+				sb.WriteString(fmt.Sprintf("- %s — %.0f%%\n", c.Label, c.Percent))
+			} else {
+				sb.WriteString(fmt.Sprintf("- %s\n", c.Label))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// Quoted post, as a nested blockquote
+	if q := p.Post.QuotedPost; q != nil {
+		sb.WriteString(fmt.Sprintf("> > **@%s**", q.AuthorHandle))
+		if q.AuthorName != "" && q.AuthorName != q.AuthorHandle {
+			sb.WriteString(fmt.Sprintf(" (%s)", q.AuthorName))
+		}
+		sb.WriteString(fmt.Sprintf(":\n> > %s\n", strings.ReplaceAll(q.Content, "\n", "\n> > ")))
+		if q.OriginalURL != "" {
+			sb.WriteString(fmt.Sprintf("> >\n> > [View quoted post](%s)\n", q.OriginalURL))
+		}
+		sb.WriteString("\n")
+	}
 
 	// Engagement metrics
-	sb.WriteString(fmt.Sprintf("📊 %d likes · %d retweets · %d replies\n\n",
+	sb.WriteString(fmt.Sprintf("📊 %d likes · %d retweets · %d replies",
 		p.Post.Likes, p.Post.Retweets, p.Post.Replies))
+	if p.Post.QuoteTweets > 0 {
+		sb.WriteString(fmt.Sprintf(" · %d quotes", p.Post.QuoteTweets))
+	}
+	if p.Post.Views > 0 {
+		sb.WriteString(fmt.Sprintf(" · %d views", p.Post.Views))
+	}
+	sb.WriteString("\n\n")
 
-	// Link
+	// Link, and rating links if the feedback server is on
+	var links []string
 	if p.Post.OriginalURL != "" {
-		sb.WriteString(fmt.Sprintf("🔗 [View on X](%s)\n\n", p.Post.OriginalURL))
+		links = append(links, fmt.Sprintf("🔗 [View on X](%s)", p.Post.OriginalURL))
+	}
+	if b.feedbackURL != "" && p.Post.ID != "" {
+		rate := fmt.Sprintf("%s/rate?token=%s&post=%s&vote=", b.feedbackURL, url.QueryEscape(b.feedbackToken), url.QueryEscape(p.Post.ID))
+		links = append(links, fmt.Sprintf("[👍 More like this](%sup) · [👎 Less like this](%sdown)", rate, rate))
+	}
+	if len(links) > 0 {
+		sb.WriteString(strings.Join(links, " · ") + "\n\n")
 	}
 
 	return sb.String()
 }
 
+// sourceBadge describes where a post came from, e.g. "list: 123" or
+// "search: golang"
+func sourceBadge(p types.Post) string {
+	switch {
+	case p.Source == "":
+		return ""
+	case p.Community != "":
+		return p.Source + ": " + p.Community
+	case p.FetchedVia == "":
+		return p.Source
+	default:
+		return p.Source + ": " + p.FetchedVia
+	}
+}
+
 // formatQuote formats text for markdown blockquote (handles newlines)
 func formatQuote(s string) string {
 	// Replace newlines with newline + quote prefix
@@ -188,7 +773,7 @@ func GetLatestDigest(outputDir string) (string, error) {
 	var latestTime time.Time
 
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), "-digest.md") {
+		if _, _, ok := parseDigestName(entry.Name()); entry.IsDir() || !ok {
 			continue
 		}
 
@@ -209,3 +794,53 @@ func GetLatestDigest(outputDir string) (string, error) {
 
 	return latest, nil
 }
+
+// ListDigests returns the digest files in outputDir created at or after since,
+// oldest first. CreatedAt is parsed from each filename; PostCount is not populated.
+func ListDigests(outputDir string, since time.Time) ([]Digest, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// os.ReadDir sorts by name, which is chronological for our timestamps
+	var digests []Digest
+	for _, entry := range entries {
+		name := entry.Name()
+		createdAt, profile, ok := parseDigestName(name)
+		if entry.IsDir() || !ok || createdAt.Before(since) {
+			continue
+		}
+		digests = append(digests, Digest{
+			FilePath:  filepath.Join(outputDir, name),
+			CreatedAt: createdAt,
+			Profile:   profile,
+		})
+	}
+
+	return digests, nil
+}
+
+// parseDigestName parses the creation time and interest profile, if any,
+// from a markdown or plain-text digest filename. Reports false for other
+// files.
+func parseDigestName(name string) (createdAt time.Time, profile string, ok bool) {
+	for _, suffix := range []string{digestSuffix, textDigestSuffix} {
+		stamp, found := strings.CutSuffix(name, suffix)
+		if !found {
+			continue
+		}
+		if len(stamp) > len(digestTimeFormat) {
+			if stamp[len(digestTimeFormat)] != '-' {
+				return time.Time{}, "", false
+			}
+			stamp, profile = stamp[:len(digestTimeFormat)], stamp[len(digestTimeFormat)+1:]
+		}
+		t, err := time.ParseInLocation(digestTimeFormat, stamp, time.Local)
+		return t, profile, err == nil
+	}
+	return time.Time{}, "", false
+}
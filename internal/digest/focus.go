@@ -0,0 +1,40 @@
+package digest
+
+import (
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// Reading speed assumed when sizing focus digests
+const (
+	wordsPerMinute  = 230
+	perPostOverhead = 10 * time.Second // Taking in the author line, metrics, and media
+)
+
+// ReadingTime estimates how long a post takes to read in a digest: its text,
+// any quoted post, and its summary at wordsPerMinute, plus perPostOverhead
+func ReadingTime(p types.PostWithAnalysis) time.Duration {
+	words := len(strings.Fields(p.Post.Content))
+	if q := p.Post.QuotedPost; q != nil {
+		words += len(strings.Fields(q.Content))
+	}
+	if p.Analysis != nil {
+		words += len(strings.Fields(p.Analysis.Summary))
+	}
+	return perPostOverhead + time.Duration(words)*time.Minute/wordsPerMinute
+}
+
+// FitReadingTime returns the leading posts whose total ReadingTime fits in
+// budget, and at least the first post. Posts should already be ranked.
+func FitReadingTime(posts []types.PostWithAnalysis, budget time.Duration) []types.PostWithAnalysis {
+	var total time.Duration
+	for i, p := range posts {
+		total += ReadingTime(p)
+		if total > budget {
+			return posts[:max(i, 1)]
+		}
+	}
+	return posts
+}
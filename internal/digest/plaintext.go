@@ -0,0 +1,136 @@
+package digest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultTextWidth is the column width plain-text digests are wrapped to
+// when none is configured
+const DefaultTextWidth = 72
+
+// minTextColumns is the least room left for text after a quote or list
+// prefix, however narrow the width
+const minTextColumns = 20
+
+// Inline markdown the builder writes, and the text each becomes. Emphasis
+// only counts at word boundaries, so "2*3*4" in a post survives.
+var (
+	mdImage  = regexp.MustCompile(`!\[([^\]]*)\]\(<?([^)>]*)>?\)`)
+	mdLink   = regexp.MustCompile(`\[([^\]]*)\]\(([^)\s]*)\)`)
+	mdBold   = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	mdItalic = regexp.MustCompile(`(^|[\s(])\*([^*\s](?:[^*]*[^*\s])?)\*($|[\s.,;:!?)])`)
+	mdCode   = regexp.MustCompile("`([^`]+)`")
+)
+
+// PlainText converts a rendered digest to plain text for readers without
+// markdown: headings are underlined, emphasis and code marks are dropped,
+// quotes keep their "> " prefix, and lines are wrapped to width columns.
+// Links and images become numbered references listed at the bottom.
+func PlainText(markdown string, width int) string {
+	if width <= 0 {
+		width = DefaultTextWidth
+	}
+
+	var sb strings.Builder
+	var links []string
+	blank := false
+	for _, line := range strings.Split(strings.TrimRight(markdown, "\n"), "\n") {
+		wasBlank := blank
+		blank = line == ""
+		switch {
+		case line == "":
+			if !wasBlank {
+				sb.WriteString("\n") // Runs of blank lines collapse to one
+			}
+		case line == "---":
+			sb.WriteString(strings.Repeat("-", width) + "\n")
+		case strings.HasPrefix(line, "#"):
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			text := plainInline(strings.TrimSpace(line[level:]), &links)
+			underline := "-"
+			if level == 1 {
+				underline = "="
+			}
+			sb.WriteString(wrapText(text, "", "", width))
+			sb.WriteString(strings.Repeat(underline, min(utf8.RuneCountInString(text), width)) + "\n")
+		default:
+			prefix, rest := splitQuote(line)
+			first, next := prefix, prefix
+			if item, ok := strings.CutPrefix(rest, "- "); ok {
+				first, next, rest = prefix+"- ", prefix+"  ", item
+			}
+			sb.WriteString(wrapText(plainInline(rest, &links), first, next, width))
+		}
+	}
+
+	if len(links) > 0 {
+		sb.WriteString("\nLinks\n-----\n")
+		for i, link := range links {
+			sb.WriteString(fmt.Sprintf("[%d] %s\n", i+1, link))
+		}
+	}
+	return sb.String()
+}
+
+// splitQuote splits a line into its blockquote prefix, normalized to one
+// "> " per level, and the rest
+func splitQuote(line string) (prefix, rest string) {
+	rest = line
+	for strings.HasPrefix(rest, ">") {
+		prefix += "> "
+		rest = strings.TrimPrefix(rest[1:], " ")
+	}
+	return prefix, rest
+}
+
+// plainInline strips inline markdown from s, appending link and image
+// targets to links and referring to them by number
+func plainInline(s string, links *[]string) string {
+	ref := func(target string) string {
+		*links = append(*links, target)
+		return fmt.Sprintf("[%d]", len(*links))
+	}
+	s = mdImage.ReplaceAllStringFunc(s, func(m string) string {
+		return "Image " + ref(mdImage.FindStringSubmatch(m)[2])
+	})
+	s = mdLink.ReplaceAllStringFunc(s, func(m string) string {
+		parts := mdLink.FindStringSubmatch(m)
+		if parts[1] == "" || parts[1] == parts[2] {
+			return ref(parts[2])
+		}
+		return parts[1] + " " + ref(parts[2])
+	})
+	s = mdBold.ReplaceAllString(s, "$1")
+	s = mdItalic.ReplaceAllString(s, "$1$2$3")
+	return mdCode.ReplaceAllString(s, "$1")
+}
+
+// wrapText word-wraps text to width columns, starting the first line with
+// first and the others with next. Words longer than a line, such as URLs,
+// get a line to themselves rather than being broken. An empty text still
+// writes its prefix, so blank quote lines stay quoted.
+func wrapText(text, first, next string, width int) string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return strings.TrimRight(first, " ") + "\n"
+	}
+
+	var sb strings.Builder
+	line, prefix := "", first
+	for _, word := range words {
+		room := max(width-utf8.RuneCountInString(prefix), minTextColumns)
+		if line != "" && utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > room {
+			sb.WriteString(prefix + line + "\n")
+			line, prefix = "", next
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	sb.WriteString(prefix + line + "\n")
+	return sb.String()
+}
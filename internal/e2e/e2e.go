@@ -0,0 +1,299 @@
+// Package e2e runs the whole pipeline against a fake X served from local
+// fixtures, with a fake LLM provider, for `scroll4me dev e2e`. It checks
+// that the scraper still reads the timeline DOM the fixtures mimic and that
+// posts make it through analysis and filtering into the digest, without an
+// X account or an API key.
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+
+	"github.com/ibeckermayer/scroll4me/internal/analyzer"
+	"github.com/ibeckermayer/scroll4me/internal/app"
+	"github.com/ibeckermayer/scroll4me/internal/auth"
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/scraper"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// interestKeywords are the interests a run is configured with. The fake
+// provider finds fixture posts relevant by them.
+var interestKeywords = []config.Keyword{{Keyword: "golang"}, {Keyword: "rust"}}
+
+// Scraping settings of a run, brisker than the defaults since the fake
+// timeline answers instantly
+const (
+	postsPerScrape   = 50 // More than the fixtures hold, so the scrape reads to the end
+	scrollDelayMs    = 200
+	scrollJitterMs   = 100
+	maxIdleScrolls   = 3
+	pageLoadTimeoutS = 15
+)
+
+// Options configures a run
+type Options struct {
+	Headless bool // Run Chrome without a window
+	Keep     bool // Keep the run's data directory even if it passes
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Dir      string   // Data directory of the run, if kept
+	Digest   string   // Path of the digest written
+	Scraped  int      // Posts scraped
+	Relevant int      // Posts that passed the relevance filter
+	Problems []string // Where the output differs from the fixtures
+}
+
+// Run serves the fixtures as x.com's home timeline on a local port, then
+// scrapes, analyzes, filters, and builds a digest of it with the fake
+// provider, and compares the scraped posts and the digest with the
+// fixtures. All config and state live in a temporary data directory (see
+// config.UseDataRoot), so it must run in a process of its own and leaves
+// the user's files alone. The directory is removed unless the run fails
+// or opts.Keep is set; Result.Dir names it if not.
+func Run(ctx context.Context, opts Options) (*Result, error) {
+	fx, err := loadFixtures()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "scroll4me-e2e-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+	config.UseDataRoot(dir)
+	result := &Result{Dir: dir}
+	log.Printf("Running against fake X with data in %s", dir)
+
+	now := time.Now()
+	fake, err := startFakeX(fx, now)
+	if err != nil {
+		return result, fmt.Errorf("failed to start fake X: %w", err)
+	}
+	defer fake.close()
+	log.Printf("Fake X serving at %s", fake.url)
+
+	a, err := newApp(fake.url, opts.Headless, now)
+	if err != nil {
+		return result, err
+	}
+
+	posts, err := a.ScrapePosts(ctx)
+	if err != nil {
+		return result, fmt.Errorf("scrape failed: %w", err)
+	}
+	analyses, err := a.AnalyzePosts(ctx, posts)
+	if err != nil {
+		return result, fmt.Errorf("analysis failed: %w", err)
+	}
+	relevant := a.FilterByRelevance(posts, analyses)
+	path, err := a.BuildDigest(relevant, len(posts))
+	if err != nil {
+		return result, fmt.Errorf("digest failed: %w", err)
+	}
+
+	result.Digest = path
+	result.Scraped = len(posts)
+	result.Relevant = len(relevant)
+	result.Problems = checkPosts(fx, posts, now)
+	digestProblems, err := checkDigest(fx, path)
+	if err != nil {
+		return result, err
+	}
+	result.Problems = append(result.Problems, digestProblems...)
+
+	if len(result.Problems) == 0 && !opts.Keep {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Printf("Failed to remove %s: %v", dir, err)
+		} else {
+			result.Dir = ""
+		}
+	}
+	return result, nil
+}
+
+// newApp writes the config, selector overrides, and a fake session for a
+// run against the fake X at baseURL, and returns an app using them
+func newApp(baseURL string, headless bool, now time.Time) (*app.App, error) {
+	cfg := config.Default()
+	cfg.Interests.Keywords = interestKeywords
+	s := &cfg.Scraping
+	s.PostsPerScrape = postsPerScrape
+	s.Headless = headless
+	s.UnrollThreads = false
+	s.MinScrapeIntervalSeconds = 0
+	s.DailyLaunchBudget = 0
+	s.StopAfterKnownPosts = 0
+	s.ScrollDelayMs = scrollDelayMs
+	s.ScrollJitterMs = scrollJitterMs
+	s.MaxIdleScrolls = maxIdleScrolls
+	s.PageLoadTimeoutSeconds = pageLoadTimeoutS
+	cfg.Analysis.APIKey = "" // Never used: the fake provider answers
+	if err := cfg.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save config: %w", err)
+	}
+
+	selectorsPath, err := scraper.SelectorsPath()
+	if err != nil {
+		return nil, err
+	}
+	overrides := fmt.Sprintf("home_url = %q\n", baseURL+"/home")
+	if err := os.WriteFile(selectorsPath, []byte(overrides), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write selectors: %w", err)
+	}
+
+	// The scraper only needs a session that looks valid; fake X doesn't check it
+	cookiePath, err := auth.DefaultCookieStorePath()
+	if err != nil {
+		return nil, err
+	}
+	cookieStore := auth.NewCookieStore(cookiePath)
+	expires := float64(now.Add(time.Hour).Unix())
+	err = cookieStore.Save([]*network.Cookie{
+		{Name: "auth_token", Value: "e2e", Domain: ".x.com", Path: "/", Expires: expires, Secure: true, HTTPOnly: true},
+		{Name: "ct0", Value: "e2e", Domain: ".x.com", Path: "/", Expires: expires, Secure: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save session: %w", err)
+	}
+
+	postScraper := scraper.FromConfig(cfg.Scraping)
+	postAnalyzer := analyzer.NewWithProvider(fakeProvider{}, cfg.Interests, cfg.Analysis.BatchSize)
+	return app.New(cfg, auth.NewManager(cookieStore, ""), postScraper, postAnalyzer), nil
+}
+
+// checkPosts compares the scraped posts with the fixtures the timeline was
+// rendered from at now: each post should be read once with every field
+// intact, reposts merged into the post they repeat, and ads dropped
+func checkPosts(fx *fixtures, posts []types.Post, now time.Time) []string {
+	var problems []string
+	problem := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	scraped := make(map[string][]types.Post)
+	for _, p := range posts {
+		scraped[p.ID] = append(scraped[p.ID], p)
+		if fx.post(p.ID) == nil {
+			problem("post %s by @%s isn't in the fixtures", p.ID, p.AuthorHandle)
+		}
+	}
+
+	for i := range fx.Posts {
+		want := &fx.Posts[i]
+		got := scraped[want.ID]
+		if want.Promoted {
+			if len(got) > 0 {
+				problem("promoted post %s wasn't dropped", want.ID)
+			}
+			continue
+		}
+		if len(got) != 1 {
+			problem("post %s by @%s scraped %d times, want once", want.ID, want.Handle, len(got))
+			if len(got) == 0 {
+				continue
+			}
+		}
+		for _, diff := range comparePost(want, got[0], now) {
+			problem("post %s: %s", want.ID, diff)
+		}
+	}
+
+	for _, r := range fx.Reposts {
+		if got := scraped[r.ID]; len(got) > 0 && !slices.Contains(got[0].RetweetedBy, r.Handle) {
+			problem("post %s: reposted by %v, want @%s among them", r.ID, got[0].RetweetedBy, r.Handle)
+		}
+	}
+	return problems
+}
+
+// comparePost lists the fields of a scraped post that differ from its
+// fixture
+func comparePost(want *fixturePost, got types.Post, now time.Time) []string {
+	var diffs []string
+	field := func(name string, got, want any) {
+		if g, w := fmt.Sprintf("%#v", got), fmt.Sprintf("%#v", want); g != w {
+			diffs = append(diffs, fmt.Sprintf("%s is %s, want %s", name, g, w))
+		}
+	}
+
+	field("author handle", got.AuthorHandle, want.Handle)
+	field("author name", got.AuthorName, want.Name)
+	field("verified", got.AuthorVerified, want.Verified)
+	field("content", got.Content, want.Text)
+	field("language", got.Lang, want.Lang)
+	field("timestamp", got.Timestamp.UTC().Format(time.RFC3339), want.postedAt(now).UTC().Format(time.RFC3339))
+	field("replies", got.Replies, want.Replies)
+	field("reposts", got.Retweets, want.Retweets)
+	field("likes", got.Likes, want.Likes)
+	field("views", got.Views, want.Views)
+	field("is reply", got.IsReply, want.ReplyTo != "")
+	field("is repost", got.IsRetweet, false)
+	if !strings.HasSuffix(got.OriginalURL, "/"+want.Handle+"/status/"+want.ID) {
+		diffs = append(diffs, fmt.Sprintf("URL is %q, want one ending /%s/status/%s", got.OriginalURL, want.Handle, want.ID))
+	}
+
+	wantMedia, wantAlt := 0, []string(nil)
+	if want.Image {
+		wantMedia = 1
+		if want.ImageAlt != "" {
+			wantAlt = []string{want.ImageAlt}
+		}
+	}
+	field("media count", len(got.MediaURLs), wantMedia)
+	field("alt text", got.MediaAltText, wantAlt)
+
+	field("is quote", got.IsQuoteTweet, want.Quoted != nil)
+	if q := want.Quoted; q != nil && got.QuotedPost != nil {
+		field("quoted ID", got.QuotedPost.ID, q.ID)
+		field("quoted author handle", got.QuotedPost.AuthorHandle, q.Handle)
+		field("quoted author name", got.QuotedPost.AuthorName, q.Name)
+		field("quoted content", got.QuotedPost.Content, q.Text)
+	}
+
+	switch {
+	case want.Card == nil:
+		field("card", got.Card, (*types.LinkCard)(nil))
+	case got.Card == nil:
+		diffs = append(diffs, "card is missing")
+	default:
+		field("card", *got.Card, *want.Card)
+	}
+	return diffs
+}
+
+// checkDigest checks that the digest at path links every fixture post
+// mentioning an interest keyword, and no others
+func checkDigest(fx *fixtures, path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read digest: %w", err)
+	}
+	digest := string(data)
+
+	var problems []string
+	for _, p := range fx.Posts {
+		if p.Promoted {
+			continue
+		}
+		relevant := len(matchedKeywords(p.Text, interestKeywords)) > 0
+		linked := strings.Contains(digest, "/status/"+p.ID+")")
+		switch {
+		case relevant && !linked:
+			problems = append(problems, fmt.Sprintf("digest %s is missing relevant post %s by @%s", filepath.Base(path), p.ID, p.Handle))
+		case !relevant && linked:
+			problems = append(problems, fmt.Sprintf("digest %s includes irrelevant post %s by @%s", filepath.Base(path), p.ID, p.Handle))
+		}
+	}
+	return problems, nil
+}
@@ -0,0 +1,142 @@
+package e2e
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+//go:embed fixtures/posts.json fixtures/timeline.html
+var fixtureFiles embed.FS
+
+// Fake timeline behavior
+const (
+	cellsPerLoad = 4                      // Cells the page renders at a time
+	loadDelay    = 150 * time.Millisecond // Before more cells appear, like a network fetch
+)
+
+// fixturePost is a post on the fake timeline, and what the scraper should
+// read from it
+type fixturePost struct {
+	ID         string          `json:"id"`
+	Handle     string          `json:"handle"`
+	Name       string          `json:"name"`
+	Verified   bool            `json:"verified"`
+	Text       string          `json:"text"`
+	Lang       string          `json:"lang"`
+	AgeMinutes int             `json:"age_minutes"`
+	Replies    int             `json:"replies"`
+	Retweets   int             `json:"retweets"`
+	Likes      int             `json:"likes"`
+	Views      int             `json:"views"`
+	ReplyTo    string          `json:"reply_to"` // Handle replied to, if a reply
+	Quoted     *fixtureQuote   `json:"quoted"`
+	Card       *types.LinkCard `json:"card"`
+	Image      bool            `json:"image"`
+	ImageAlt   string          `json:"image_alt"` // Empty shows X's generic "Image"
+	Promoted   bool            `json:"promoted"`
+}
+
+// fixtureQuote is the post a fixturePost quotes
+type fixtureQuote struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+	Name   string `json:"name"`
+	Text   string `json:"text"`
+}
+
+// fixtureRepost shows an earlier fixture post again, reposted by another
+// account, after all the others
+type fixtureRepost struct {
+	ID     string `json:"id"`
+	Handle string `json:"handle"`
+	Name   string `json:"name"`
+}
+
+// fixtures is the content of the fake timeline
+type fixtures struct {
+	Posts   []fixturePost   `json:"posts"`
+	Reposts []fixtureRepost `json:"reposts"`
+}
+
+// loadFixtures reads the embedded fixtures, checking that reposts refer to
+// posts that exist
+func loadFixtures() (*fixtures, error) {
+	data, err := fixtureFiles.ReadFile("fixtures/posts.json")
+	if err != nil {
+		return nil, err
+	}
+	var fx fixtures
+	if err := json.Unmarshal(data, &fx); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures: %w", err)
+	}
+	for _, r := range fx.Reposts {
+		if fx.post(r.ID) == nil {
+			return nil, fmt.Errorf("fixture repost of unknown post %s", r.ID)
+		}
+	}
+	return &fx, nil
+}
+
+// post returns the fixture post with the given ID, or nil
+func (fx *fixtures) post(id string) *fixturePost {
+	for i := range fx.Posts {
+		if fx.Posts[i].ID == id {
+			return &fx.Posts[i]
+		}
+	}
+	return nil
+}
+
+// timelineCell is one cell of the rendered timeline
+type timelineCell struct {
+	Post           *fixturePost
+	Time           string // RFC 3339, in the time element's datetime
+	Age            string // As X shows it, e.g. "35m"
+	RepostedBy     string
+	RepostedByName string
+}
+
+// renderTimeline renders the fake home timeline, dating posts relative to
+// now
+func (fx *fixtures) renderTimeline(now time.Time) ([]byte, error) {
+	page, err := template.ParseFS(fixtureFiles, "fixtures/timeline.html")
+	if err != nil {
+		return nil, err
+	}
+
+	cell := func(p *fixturePost) timelineCell {
+		age := fmt.Sprintf("%dm", p.AgeMinutes)
+		if p.AgeMinutes >= 60 {
+			age = fmt.Sprintf("%dh", p.AgeMinutes/60)
+		}
+		return timelineCell{Post: p, Time: p.postedAt(now).Format(time.RFC3339), Age: age}
+	}
+	var cells []timelineCell
+	for i := range fx.Posts {
+		cells = append(cells, cell(&fx.Posts[i]))
+	}
+	for _, r := range fx.Reposts {
+		c := cell(fx.post(r.ID))
+		c.RepostedBy, c.RepostedByName = r.Handle, r.Name
+		cells = append(cells, c)
+	}
+
+	var buf bytes.Buffer
+	err = page.Execute(&buf, map[string]any{
+		"Cells":       cells,
+		"BatchSize":   cellsPerLoad,
+		"LoadDelayMs": loadDelay.Milliseconds(),
+	})
+	return buf.Bytes(), err
+}
+
+// postedAt is when the post was posted, on a timeline rendered at now
+func (p *fixturePost) postedAt(now time.Time) time.Time {
+	return now.Add(-time.Duration(p.AgeMinutes) * time.Minute).Truncate(time.Second)
+}
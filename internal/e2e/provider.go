@@ -0,0 +1,67 @@
+package e2e
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// Scores the fake provider gives
+const (
+	matchScore   = 0.9 // Posts mentioning an interest keyword
+	noMatchScore = 0.1 // The rest
+)
+
+// summaryChars caps the fake summaries
+const summaryChars = 80
+
+// fakeProvider stands in for the LLM: a post is relevant if it mentions an
+// interest keyword, and its summary is the start of its text. It needs no
+// API key and always answers the same way, so digests can be checked.
+type fakeProvider struct{}
+
+// Analyze scores each post by the interest keywords its text mentions
+func (fakeProvider) Analyze(ctx context.Context, posts []types.Post, interests config.InterestsConfig) ([]types.Analysis, error) {
+	now := time.Now()
+	analyses := make([]types.Analysis, 0, len(posts))
+	for _, post := range posts {
+		topics := matchedKeywords(post.Content, interests.Keywords)
+		score, reason := noMatchScore, "mentions no interest keyword"
+		if len(topics) > 0 {
+			score, reason = matchScore, "mentions "+strings.Join(topics, ", ")
+		}
+		analyses = append(analyses, types.Analysis{
+			PostID:         post.ID,
+			RelevanceScore: score,
+			Topics:         topics,
+			Summary:        summarize(post.Content),
+			Reason:         reason,
+			AnalyzedAt:     now,
+		})
+	}
+	return analyses, nil
+}
+
+// matchedKeywords returns the keywords that text mentions, ignoring case
+func matchedKeywords(text string, keywords []config.Keyword) []string {
+	text = strings.ToLower(text)
+	var matched []string
+	for _, k := range keywords {
+		if k.Keyword != "" && strings.Contains(text, strings.ToLower(k.Keyword)) {
+			matched = append(matched, k.Keyword)
+		}
+	}
+	return matched
+}
+
+// summarize returns text's first line, shortened to summaryChars
+func summarize(text string) string {
+	line, _, _ := strings.Cut(text, "\n")
+	if runes := []rune(line); len(runes) > summaryChars {
+		return string(runes[:summaryChars-1]) + "…"
+	}
+	return line
+}
@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// fakeX serves the fixtures as a stand-in for x.com on a local port
+type fakeX struct {
+	url    string // e.g. http://127.0.0.1:52113
+	server *http.Server
+}
+
+// startFakeX starts serving the fake home timeline at /home, with its posts
+// dated relative to now, until close is called
+func startFakeX(fx *fixtures, now time.Time) (*fakeX, error) {
+	page, err := fx.renderTimeline(now)
+	if err != nil {
+		return nil, err
+	}
+	media, err := placeholderImage()
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /home", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page)
+	})
+	mux.HandleFunc("GET /media/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(media)
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	f := &fakeX{
+		url:    "http://" + listener.Addr().String(),
+		server: &http.Server{Handler: mux},
+	}
+	go func() {
+		if err := f.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("Fake X server stopped: %v", err)
+		}
+	}()
+	return f, nil
+}
+
+// close stops the server
+func (f *fakeX) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	f.server.Shutdown(ctx)
+}
+
+// placeholderImage returns a small grey PNG, served for every post image
+func placeholderImage() ([]byte, error) {
+	img := image.NewGray(image.Rect(0, 0, 16, 9))
+	for i := range img.Pix {
+		img.Pix[i] = 0xdd
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,104 @@
+package insights
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// RenderHTML renders the report as a self-contained page of heatmaps
+func RenderHTML(r *Report) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, pageData{Report: r, topicMax: topicMax(r), sharedMax: sharedMax(r)}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// pageData adds the scales the heatmap shading is relative to
+type pageData struct {
+	*Report
+	topicMax, sharedMax int
+}
+
+// TopicShade and SharedShade return a cell background for a count
+func (d pageData) TopicShade(n int) template.CSS  { return shade(n, d.topicMax) }
+func (d pageData) SharedShade(n int) template.CSS { return shade(n, d.sharedMax) }
+
+func shade(n, max int) template.CSS {
+	if n == 0 || max == 0 {
+		return "background: transparent"
+	}
+	return template.CSS(fmt.Sprintf("background: rgba(29, 155, 240, %.2f)", 0.15+0.85*float64(n)/float64(max)))
+}
+
+func topicMax(r *Report) int {
+	m := 0
+	for _, t := range r.Topics {
+		for _, n := range t.Counts {
+			m = max(m, n)
+		}
+	}
+	return m
+}
+
+// sharedMax ignores the diagonal, which would otherwise wash out the rest
+func sharedMax(r *Report) int {
+	m := 0
+	for i, row := range r.Shared {
+		for j, n := range row {
+			if i != j {
+				m = max(m, n)
+			}
+		}
+	}
+	return m
+}
+
+var pageTemplate = template.Must(template.New("insights").Funcs(template.FuncMap{
+	"shortDay": func(day string) string { return day[len("2006-"):] },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>scroll4me - what your feed has been showing you</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, sans-serif; margin: 2em; color: #0f1419; }
+h1 { font-size: 1.4em; }
+h2 { font-size: 1.1em; margin-top: 2em; }
+.note { color: #536471; font-size: 0.9em; }
+table { border-collapse: collapse; font-size: 0.8em; }
+th, td { padding: 4px 6px; text-align: center; }
+th.row { text-align: right; white-space: nowrap; }
+thead th { writing-mode: vertical-rl; transform: rotate(180deg); white-space: nowrap; }
+td { min-width: 1.6em; border: 1px solid #eff3f4; }
+</style>
+</head>
+<body>
+<h1>What your feed has been showing you</h1>
+<p class="note">{{.Analyzed}} analyzed posts from {{.From.Format "Jan 2, 2006"}} to {{.To.Format "Jan 2, 2006"}}.</p>
+
+<h2>Topics over time</h2>
+{{if .Topics}}
+<p class="note">Posts per day for the {{len .Topics}} most frequent topics.</p>
+<table>
+<thead><tr><th></th>{{range .Days}}<th>{{shortDay .}}</th>{{end}}<th>Total</th></tr></thead>
+<tbody>
+{{range .Topics}}<tr><th class="row">{{.Topic}}</th>{{range .Counts}}<td style="{{$.TopicShade .}}">{{if .}}{{.}}{{end}}</td>{{end}}<td>{{.Total}}</td></tr>
+{{end}}</tbody>
+</table>
+{{else}}<p class="note">No topics yet - generate a digest first.</p>{{end}}
+
+<h2>Author co-occurrence</h2>
+{{if .Authors}}
+<p class="note">How many topics each pair of your {{len .Authors}} most frequent authors both posted about. The diagonal is each author's own topic count.</p>
+<table>
+<thead><tr><th></th>{{range .Authors}}<th>@{{.Handle}}</th>{{end}}</tr></thead>
+<tbody>
+{{range $i, $a := .Authors}}<tr><th class="row">@{{$a.Handle}} ({{$a.Posts}})</th>{{range $j, $n := index $.Shared $i}}<td style="{{if ne $i $j}}{{$.SharedShade $n}}{{end}}">{{if $n}}{{$n}}{{end}}</td>{{end}}</tr>
+{{end}}</tbody>
+</table>
+{{else}}<p class="note">No authors yet - generate a digest first.</p>{{end}}
+</body>
+</html>
+`))
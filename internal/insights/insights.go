@@ -0,0 +1,160 @@
+// Package insights summarizes cached analyses into a picture of what the
+// feed has been serving: which topics came up on which days, and which
+// authors keep posting about the same things.
+package insights
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// Chart sizes
+const (
+	maxTopics  = 15 // Topics shown in the topics-over-time chart
+	maxAuthors = 15 // Authors shown in the co-occurrence matrix
+)
+
+// dayFormat is how days are keyed and labeled
+const dayFormat = "2006-01-02"
+
+// Report is the data behind the graph page
+type Report struct {
+	From, To time.Time
+	Analyzed int // Distinct posts analyzed in the window
+
+	// Topics over time: Days are consecutive dates; each topic's Counts
+	// line up with them
+	Days   []string
+	Topics []TopicSeries
+
+	// Author co-occurrence: Shared[i][j] is how many topics Authors[i] and
+	// Authors[j] both posted about (the diagonal is the author's own count)
+	Authors []AuthorSummary
+	Shared  [][]int
+}
+
+// TopicSeries is one topic's post count per day
+type TopicSeries struct {
+	Topic  string
+	Total  int
+	Counts []int
+}
+
+// AuthorSummary is an author's activity in the window
+type AuthorSummary struct {
+	Handle string
+	Posts  int
+}
+
+// Build joins posts with their analyses and tallies topics and authors for
+// analyses made since the given time. Posts without an analysis, and
+// analyses whose post is unknown, are skipped.
+func Build(posts []types.Post, analyses []types.Analysis, since time.Time) *Report {
+	postsByID := make(map[string]types.Post, len(posts))
+	for _, p := range posts {
+		postsByID[p.ID] = p
+	}
+
+	// A post analyzed more than once (e.g. after being deferred) counts once,
+	// with its latest analysis
+	latest := make(map[string]types.Analysis)
+	for _, an := range analyses {
+		if an.AnalyzedAt.Before(since) {
+			continue
+		}
+		if _, ok := postsByID[an.PostID]; !ok {
+			continue
+		}
+		if prev, ok := latest[an.PostID]; !ok || an.AnalyzedAt.After(prev.AnalyzedAt) {
+			latest[an.PostID] = an
+		}
+	}
+
+	r := &Report{From: since, To: time.Now(), Analyzed: len(latest)}
+
+	topicDays := make(map[string]map[string]int) // topic key -> day -> count
+	topicTotals := make(map[string]int)
+	topicLabels := make(map[string]string) // topic key -> first spelling seen
+	authorPosts := make(map[string]int)
+	authorTopics := make(map[string]map[string]bool)
+	for id, an := range latest {
+		day := an.AnalyzedAt.Local().Format(dayFormat)
+		handle := strings.ToLower(postsByID[id].AuthorHandle)
+		authorPosts[handle]++
+		if authorTopics[handle] == nil {
+			authorTopics[handle] = make(map[string]bool)
+		}
+
+		for _, topic := range an.Topics {
+			key := strings.ToLower(strings.TrimSpace(topic))
+			if key == "" {
+				continue
+			}
+			if _, ok := topicLabels[key]; !ok {
+				topicLabels[key] = strings.TrimSpace(topic)
+				topicDays[key] = make(map[string]int)
+			}
+			topicDays[key][day]++
+			topicTotals[key]++
+			authorTopics[handle][key] = true
+		}
+	}
+
+	r.Days = daysBetween(since, r.To)
+	for _, key := range top(topicTotals, maxTopics) {
+		series := TopicSeries{Topic: topicLabels[key], Total: topicTotals[key], Counts: make([]int, len(r.Days))}
+		for i, day := range r.Days {
+			series.Counts[i] = topicDays[key][day]
+		}
+		r.Topics = append(r.Topics, series)
+	}
+
+	handles := top(authorPosts, maxAuthors)
+	r.Shared = make([][]int, len(handles))
+	for i, a := range handles {
+		r.Authors = append(r.Authors, AuthorSummary{Handle: a, Posts: authorPosts[a]})
+		r.Shared[i] = make([]int, len(handles))
+		for j, b := range handles {
+			for topic := range authorTopics[a] {
+				if authorTopics[b][topic] {
+					r.Shared[i][j]++
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+// top returns up to n keys with the highest counts, ties broken alphabetically
+func top(counts map[string]int, n int) []string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// daysBetween lists the local dates from from to to, inclusive
+func daysBetween(from, to time.Time) []string {
+	from, to = from.Local(), to.Local()
+	day := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	var days []string
+	for !day.After(to) {
+		days = append(days, day.Format(dayFormat))
+		day = day.AddDate(0, 0, 1)
+	}
+	return days
+}
@@ -0,0 +1,110 @@
+// Package media downloads post images (and video thumbnails) into a local
+// cache, so digests keep working after X's CDN URLs expire and can be read
+// offline.
+package media
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/browser"
+)
+
+// maxMediaBytes caps the size of a single downloaded file
+const maxMediaBytes = 20 << 20
+
+// Downloader fetches media URLs into a cache directory
+type Downloader struct {
+	client *http.Client
+	dir    string
+}
+
+// NewDownloader creates a downloader that stores files in dir, with a
+// per-request timeout
+func NewDownloader(dir string, timeout time.Duration) *Downloader {
+	return &Downloader{client: &http.Client{Timeout: timeout}, dir: dir}
+}
+
+// Fetch downloads rawURL into the cache and returns the local path. Files
+// are named by a hash of the URL, so already-cached media isn't fetched again.
+func (d *Downloader) Fetch(ctx context.Context, rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported media URL: %s", rawURL)
+	}
+
+	sum := sha256.Sum256([]byte(rawURL))
+	localPath := filepath.Join(d.dir, hex.EncodeToString(sum[:12])+extension(u))
+	if _, err := os.Stat(localPath); err == nil {
+		return localPath, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", browser.DefaultUserAgent)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download failed: %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(d.dir, 0755); err != nil {
+		return "", err
+	}
+
+	// Write to a temp file first so an interrupted download isn't mistaken for a cached one
+	tmp, err := os.CreateTemp(d.dir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	// One byte over the cap tells a file that's too big from one that fits
+	n, err := io.Copy(tmp, io.LimitReader(resp.Body, maxMediaBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	if n > maxMediaBytes {
+		return "", fmt.Errorf("file is larger than %d MB", maxMediaBytes>>20)
+	}
+
+	if err := os.Rename(tmp.Name(), localPath); err != nil {
+		return "", err
+	}
+	return localPath, nil
+}
+
+// extension picks a file extension for a media URL. X image URLs carry the
+// format as a query parameter (".../media/abc?format=jpg&name=small").
+func extension(u *url.URL) string {
+	if format := u.Query().Get("format"); format != "" {
+		return "." + strings.ToLower(format)
+	}
+	if ext := path.Ext(u.Path); ext != "" {
+		return strings.ToLower(ext)
+	}
+	return ".jpg"
+}
@@ -0,0 +1,52 @@
+// Package notify shows desktop notifications with each platform's own
+// tools, so there's no dependency to install: osascript on macOS,
+// notify-send on Linux and the BSDs, and PowerShell on Windows.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sendTimeout bounds how long a notification command may take
+const sendTimeout = 10 * time.Second
+
+// windowsScript shows a balloon tip from a temporary tray icon. The title
+// and message come in through the environment, so they need no escaping.
+const windowsScript = `Add-Type -AssemblyName System.Windows.Forms, System.Drawing
+$n = New-Object System.Windows.Forms.NotifyIcon
+$n.Icon = [System.Drawing.SystemIcons]::Information
+$n.Visible = $true
+$n.ShowBalloonTip(10000, $env:SCROLL4ME_TITLE, $env:SCROLL4ME_MESSAGE, 'Info')
+Start-Sleep -Seconds 5
+$n.Dispose()`
+
+// Send shows a desktop notification with the given title and message
+func Send(ctx context.Context, title, message string) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		// AppleScript string literals escape like Go's, for the quotes and
+		// backslashes that matter here
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+		cmd = exec.CommandContext(ctx, "osascript", "-e", script)
+	case "windows":
+		cmd = exec.CommandContext(ctx, "powershell", "-NoProfile", "-NonInteractive", "-Command", windowsScript)
+		cmd.Env = append(os.Environ(), "SCROLL4ME_TITLE="+title, "SCROLL4ME_MESSAGE="+message)
+	default:
+		cmd = exec.CommandContext(ctx, "notify-send", "--app-name=scroll4me", "--", title, message)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", cmd.Args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
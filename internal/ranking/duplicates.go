@@ -0,0 +1,88 @@
+package ranking
+
+import (
+	"strings"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// nearDuplicateSimilarity is how similar (cosine of term vectors) two
+// posts' texts must be to count as saying the same thing
+const nearDuplicateSimilarity = 0.8
+
+// Short texts and card titles say too little to tell posts apart: "Great
+// thread on this 👇" or a card titled "YouTube" fit any subject. Texts need
+// this many terms to be compared, and card titles this many words.
+const (
+	minDuplicateTerms      = 5
+	minDuplicateTitleWords = 3
+)
+
+// linkKey identifies the article card links to by its domain and title,
+// since card URLs are per-post t.co links. generic reports whether the
+// title has too few words to tell articles apart.
+func linkKey(card *types.LinkCard) (key string, generic bool) {
+	if card == nil || card.Title == "" {
+		return "", false
+	}
+	return strings.ToLower(card.Domain + "|" + card.Title), len(Tokens(card.Title)) < minDuplicateTitleWords
+}
+
+// LinkKey returns the key of the article card links to, the domain and
+// title lowercased and joined by "|", or "" if there's no card or its title
+// is too generic to match posts on
+func LinkKey(card *types.LinkCard) string {
+	key, generic := linkKey(card)
+	if generic {
+		return ""
+	}
+	return key
+}
+
+// NearDuplicates groups posts that say the same thing: near-identical text
+// of at least minDuplicateTerms terms, or a link to the same article (same
+// card title and domain, since card URLs are per-post t.co links). Posts
+// linking different articles never match on text. Unanalyzed posts are
+// compared, so this can run before analysis. Returns groups of indexes into
+// posts, in order of each group's first post; posts without duplicates form
+// groups of one.
+func NearDuplicates(posts []types.Post) [][]int {
+	type group struct {
+		members []int
+		terms   map[string]float64 // Of the group's first post
+		link    string
+	}
+	var groups []*group
+	for i, p := range posts {
+		terms := termVector(p.Content)
+		if len(terms) < minDuplicateTerms {
+			terms = nil
+		}
+		link, generic := linkKey(p.Card)
+
+		var match *group
+		for _, g := range groups {
+			sameLink := link != "" && link == g.link
+			if sameLink && !generic {
+				match = g
+				break
+			}
+			otherLink := link != "" && g.link != "" && !sameLink
+			if !otherLink && terms != nil && g.terms != nil && cosine(terms, g.terms) >= nearDuplicateSimilarity {
+				match = g
+				break
+			}
+		}
+		if match == nil {
+			groups = append(groups, &group{members: []int{i}, terms: terms, link: link})
+			continue
+		}
+		match.members = append(match.members, i)
+	}
+
+	indexes := make([][]int, len(groups))
+	for i, g := range groups {
+		indexes[i] = g.members
+	}
+	return indexes
+}
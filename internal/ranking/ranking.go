@@ -0,0 +1,209 @@
+// Package ranking orders filtered posts for the digest. Filtering decides
+// which posts are good enough; a Ranker decides which of them lead.
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// Defaults for tuning values left at 0 in the config
+const (
+	defaultEngagementWeight = 0.3
+	defaultRecencyHalfLife  = 24 * time.Hour
+	defaultMMRLambda        = 0.7
+)
+
+// Ranker orders posts best first. Posts without an analysis (e.g.
+// headlines) keep their given order, after the analyzed ones.
+type Ranker interface {
+	Name() string
+	Rank(posts []types.PostWithAnalysis) []types.PostWithAnalysis
+}
+
+// New returns the ranker selected by cfg.Ranker ("" means score)
+func New(cfg config.DigestConfig) (Ranker, error) {
+	switch cfg.Ranker {
+	case config.RankerScore, "":
+		return scoreRanker{}, nil
+	case config.RankerEngagement:
+		return engagementRanker{weight: orDefault(cfg.EngagementWeight, defaultEngagementWeight)}, nil
+	case config.RankerRecency:
+		halfLife := time.Duration(cfg.RecencyHalfLifeHours * float64(time.Hour))
+		if halfLife <= 0 {
+			halfLife = defaultRecencyHalfLife
+		}
+		return recencyRanker{halfLife: halfLife, now: time.Now}, nil
+	case config.RankerMMR:
+		return mmrRanker{lambda: orDefault(cfg.MMRLambda, defaultMMRLambda)}, nil
+	default:
+		return nil, fmt.Errorf("unknown ranker: %s (use %q, %q, %q, or %q)", cfg.Ranker,
+			config.RankerScore, config.RankerEngagement, config.RankerRecency, config.RankerMMR)
+	}
+}
+
+func orDefault(v, def float64) float64 {
+	if v == 0 {
+		return def
+	}
+	return v
+}
+
+// scoreRanker orders by rank score: relevance, blended with author affinity
+// when known
+type scoreRanker struct{}
+
+func (scoreRanker) Name() string { return config.RankerScore }
+
+func (scoreRanker) Rank(posts []types.PostWithAnalysis) []types.PostWithAnalysis {
+	return sortBy(posts, func(p types.PostWithAnalysis) float64 { return p.Rank() })
+}
+
+// engagementRanker adds weight times the post's engagement, on a log scale
+// relative to the most engaging post, to its rank score
+type engagementRanker struct {
+	weight float64
+}
+
+func (engagementRanker) Name() string { return config.RankerEngagement }
+
+func (r engagementRanker) Rank(posts []types.PostWithAnalysis) []types.PostWithAnalysis {
+	top := 0.0
+	for _, p := range posts {
+		top = max(top, math.Log1p(float64(p.Post.Engagement())))
+	}
+	return sortBy(posts, func(p types.PostWithAnalysis) float64 {
+		if top == 0 {
+			return p.Rank()
+		}
+		return p.Rank() + r.weight*math.Log1p(float64(p.Post.Engagement()))/top
+	})
+}
+
+// recencyRanker halves a post's rank score for every halfLife of age, so a
+// fresh good post beats a stale great one
+type recencyRanker struct {
+	halfLife time.Duration
+	now      func() time.Time
+}
+
+func (recencyRanker) Name() string { return config.RankerRecency }
+
+func (r recencyRanker) Rank(posts []types.PostWithAnalysis) []types.PostWithAnalysis {
+	now := r.now()
+	return sortBy(posts, func(p types.PostWithAnalysis) float64 {
+		if p.Post.Timestamp.IsZero() {
+			return p.Rank()
+		}
+		age := max(now.Sub(p.Post.Timestamp), 0)
+		return p.Rank() * math.Exp2(-float64(age)/float64(r.halfLife))
+	})
+}
+
+// mmrRanker is diversity-aware: it picks posts greedily by maximal marginal
+// relevance, lambda*score - (1-lambda)*(similarity to the closest post
+// already picked), so the top of the digest covers distinct subjects rather
+// than several takes on the same announcement. See similarity.
+type mmrRanker struct {
+	lambda float64
+}
+
+func (mmrRanker) Name() string { return config.RankerMMR }
+
+func (r mmrRanker) Rank(posts []types.PostWithAnalysis) []types.PostWithAnalysis {
+	analyzed, rest := splitAnalyzed(posts)
+	subjects := make([]subject, len(analyzed))
+	for i, p := range analyzed {
+		subjects[i] = newSubject(p)
+	}
+
+	ranked := make([]types.PostWithAnalysis, 0, len(posts))
+	picked := make([]bool, len(analyzed))
+	maxSim := make([]float64, len(analyzed)) // To the closest picked post
+	for range analyzed {
+		best, bestScore := -1, math.Inf(-1)
+		for i, p := range analyzed {
+			if picked[i] {
+				continue
+			}
+			score := r.lambda*p.Rank() - (1-r.lambda)*maxSim[i]
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		picked[best] = true
+		ranked = append(ranked, analyzed[best])
+		for i := range analyzed {
+			if !picked[i] {
+				maxSim[i] = max(maxSim[i], similarity(subjects[i], subjects[best]))
+			}
+		}
+	}
+	return append(ranked, rest...)
+}
+
+// sortBy orders analyzed posts by score, descending (stable), followed by
+// the rest in their given order
+func sortBy(posts []types.PostWithAnalysis, score func(types.PostWithAnalysis) float64) []types.PostWithAnalysis {
+	analyzed, rest := splitAnalyzed(posts)
+	type scored struct {
+		post  types.PostWithAnalysis
+		score float64
+	}
+	order := make([]scored, len(analyzed))
+	for i, p := range analyzed {
+		order[i] = scored{p, score(p)}
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return order[i].score > order[j].score
+	})
+
+	ranked := make([]types.PostWithAnalysis, 0, len(posts))
+	for _, s := range order {
+		ranked = append(ranked, s.post)
+	}
+	return append(ranked, rest...)
+}
+
+// splitAnalyzed separates posts with an analysis from those without,
+// keeping order, into new slices
+func splitAnalyzed(posts []types.PostWithAnalysis) (analyzed, rest []types.PostWithAnalysis) {
+	for _, p := range posts {
+		if p.Analysis != nil {
+			analyzed = append(analyzed, p)
+		} else {
+			rest = append(rest, p)
+		}
+	}
+	return analyzed, rest
+}
+
+func topicSet(topics []string) map[string]bool {
+	set := make(map[string]bool, len(topics))
+	for _, t := range topics {
+		if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+			set[t] = true
+		}
+	}
+	return set
+}
+
+// jaccard returns |a ∩ b| / |a ∪ b|, or 0 if both are empty
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
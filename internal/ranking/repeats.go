@@ -0,0 +1,31 @@
+package ranking
+
+import "github.com/ibeckermayer/scroll4me/internal/types"
+
+// repeatSimilarity is how similar (see similarity) a post must be to an
+// earlier one to count as another take on the same subject
+const repeatSimilarity = 0.5
+
+// CountRepeats returns, for each post, how many of the earlier posts were
+// about the same subject. Earlier posts with the same ID don't count, and
+// posts without an analysis get 0.
+func CountRepeats(posts, earlier []types.PostWithAnalysis) []int {
+	past := make([]subject, len(earlier))
+	for i, p := range earlier {
+		past[i] = newSubject(p)
+	}
+
+	counts := make([]int, len(posts))
+	for i, p := range posts {
+		if p.Analysis == nil {
+			continue
+		}
+		s := newSubject(p)
+		for j, e := range earlier {
+			if e.Post.ID != p.Post.ID && similarity(s, past[j]) >= repeatSimilarity {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
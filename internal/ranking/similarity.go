@@ -0,0 +1,124 @@
+package ranking
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// minTermLength drops short tokens (articles, "is", emoji fragments) from
+// content vectors
+const minTermLength = 3
+
+// stopwords are common words that say nothing about a post's subject
+var stopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true,
+	"you": true, "all": true, "can": true, "has": true, "had": true, "was": true,
+	"this": true, "that": true, "with": true, "from": true, "they": true, "will": true,
+	"have": true, "just": true, "what": true, "when": true, "your": true, "about": true,
+	"more": true, "than": true, "them": true, "then": true, "there": true, "their": true,
+	"been": true, "were": true, "which": true, "would": true, "could": true, "into": true,
+	"out": true, "our": true, "its": true, "it's": true, "how": true, "who": true,
+	"https": true, "http": true, "www": true,
+}
+
+// subject is what a post is about, for telling near-duplicate takes apart
+type subject struct {
+	topics map[string]bool
+	terms  map[string]float64 // Unit-length term frequency vector of the content and summary
+	refs   map[string]bool    // Quoted post IDs and linked articles (see LinkKey)
+}
+
+func newSubject(p types.PostWithAnalysis) subject {
+	s := subject{
+		topics: topicSet(p.Analysis.Topics),
+		terms:  termVector(p.Post.Content + " " + p.Analysis.Summary),
+		refs:   make(map[string]bool),
+	}
+	if q := p.Post.QuotedPost; q != nil && q.ID != "" {
+		s.refs["post:"+q.ID] = true
+	}
+	if link := LinkKey(p.Post.Card); link != "" {
+		s.refs["link:"+link] = true
+	}
+	return s
+}
+
+// similarity is 1 for posts quoting the same post or linking the same article,
+// and otherwise the larger of their topic overlap and content similarity
+func similarity(a, b subject) float64 {
+	for ref := range a.refs {
+		if b.refs[ref] {
+			return 1
+		}
+	}
+	return max(jaccard(a.topics, b.topics), cosine(a.terms, b.terms))
+}
+
+// Tokens splits text into lowercased words: runs of letters, digits, and
+// inner apostrophes
+func Tokens(text string) []string {
+	var tokens []string
+	for _, tok := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}) {
+		if tok = strings.Trim(tok, "'"); tok != "" {
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens
+}
+
+// HasPhrase reports whether phrase's words appear in tokens (see Tokens)
+// whole and in order, so "ai" matches "AI safety" but not "said". A phrase
+// without words, such as an emoji, is matched as a plain substring of
+// text, the text tokens came from.
+func HasPhrase(text string, tokens []string, phrase string) bool {
+	words := Tokens(phrase)
+	if len(words) == 0 {
+		phrase = strings.TrimSpace(phrase)
+		return phrase != "" && strings.Contains(text, phrase)
+	}
+	for i := 0; i+len(words) <= len(tokens); i++ {
+		if slices.Equal(tokens[i:i+len(words)], words) {
+			return true
+		}
+	}
+	return false
+}
+
+// termVector tokenizes text into a unit-length term frequency vector
+func termVector(text string) map[string]float64 {
+	terms := make(map[string]float64)
+	for _, tok := range Tokens(text) {
+		if len([]rune(tok)) < minTermLength || stopwords[tok] {
+			continue
+		}
+		terms[tok]++
+	}
+
+	norm := 0.0
+	for _, n := range terms {
+		norm += n * n
+	}
+	norm = math.Sqrt(norm)
+	for t := range terms {
+		terms[t] /= norm
+	}
+	return terms
+}
+
+// cosine returns the cosine similarity of two unit-length vectors
+func cosine(a, b map[string]float64) float64 {
+	if len(b) < len(a) {
+		a, b = b, a
+	}
+	dot := 0.0
+	for t, w := range a {
+		dot += w * b[t]
+	}
+	return dot
+}
@@ -0,0 +1,146 @@
+// Package ratelimit spaces out browser launches against X across every kind
+// of scrape (feed, lists, threads, ...), enforces a daily launch budget, and
+// backs off after failures, to reduce the chance of the account being
+// flagged. State is persisted so limits hold across runs and restarts.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/store"
+)
+
+// Backoff after consecutive failures doubles from backoffBase up to backoffMax
+const (
+	backoffBase = time.Minute
+	backoffMax  = time.Hour
+)
+
+// maxWait is the longest Acquire will sleep; longer waits fail instead so
+// an interactive run doesn't appear hung
+const maxWait = 2 * time.Minute
+
+// ErrBudgetExhausted is returned when the daily launch budget is used up
+var ErrBudgetExhausted = errors.New("daily scrape budget exhausted")
+
+// ErrBackingOff is returned when a required wait exceeds maxWait
+var ErrBackingOff = errors.New("scraping is backing off")
+
+// Limiter gates browser launches. A nil *Limiter allows everything.
+type Limiter struct {
+	mu          sync.Mutex
+	minInterval time.Duration
+	dailyBudget int // 0 = unlimited
+}
+
+// New creates a limiter from the scraping config's minimum interval and
+// daily launch budget
+func New(cfg config.ScrapingConfig) *Limiter {
+	return &Limiter{
+		minInterval: time.Duration(cfg.MinScrapeIntervalSeconds) * time.Second,
+		dailyBudget: cfg.DailyLaunchBudget,
+	}
+}
+
+// Acquire waits until a browser launch is allowed and records it. It fails
+// with ErrBudgetExhausted or ErrBackingOff rather than waiting longer than
+// maxWait, and with ctx's error once ctx is done.
+func (l *Limiter) Acquire(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err // Don't launch a browser for a cancelled run
+	}
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := loadHistory()
+	now := time.Now()
+	state.Launches = pruneBefore(state.Launches, now.Add(-24*time.Hour))
+	if l.dailyBudget > 0 && len(state.Launches) >= l.dailyBudget {
+		return fmt.Errorf("%w: %d launches in the last 24h, next one allowed at %s",
+			ErrBudgetExhausted, len(state.Launches), state.Launches[0].Add(24*time.Hour).Format(time.Kitchen))
+	}
+
+	var ready time.Time
+	if n := len(state.Launches); n > 0 {
+		ready = state.Launches[n-1].Add(l.minInterval)
+	}
+	if state.BackoffUntil.After(ready) {
+		ready = state.BackoffUntil
+	}
+
+	if wait := ready.Sub(now); wait > 0 {
+		if wait > maxWait {
+			return fmt.Errorf("%w after %d failures: next scrape allowed at %s",
+				ErrBackingOff, state.ConsecutiveFailures, ready.Format(time.Kitchen))
+		}
+		log.Printf("Rate limit: waiting %v before scraping %s", wait.Round(time.Second), name)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+
+	state.Launches = append(state.Launches, time.Now())
+	return store.SaveScrapeHistory(state)
+}
+
+// Report records the outcome of a timeline scrape (feed, list, search, ...)
+// started after Acquire. Failures back off exponentially; a success resets
+// the backoff. Cancellation (err wrapping context.Canceled) counts as
+// neither. Best-effort fetches such as thread unrolls and author profiles
+// aren't reported, so their failures don't hold up the scrapes that matter.
+func (l *Limiter) Report(err error) {
+	if l == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := loadHistory()
+
+	if err == nil {
+		state.ConsecutiveFailures = 0
+		state.BackoffUntil = time.Time{}
+	} else {
+		state.ConsecutiveFailures++
+		backoff := min(backoffBase<<(state.ConsecutiveFailures-1), backoffMax)
+		state.BackoffUntil = time.Now().Add(backoff)
+		log.Printf("Rate limit: scrape failed %d times in a row, backing off for %v", state.ConsecutiveFailures, backoff)
+	}
+
+	if err := store.SaveScrapeHistory(state); err != nil {
+		log.Printf("Failed to save scrape history: %v", err)
+	}
+}
+
+// loadHistory reads the scrape history. One that can't be read, e.g. a
+// corrupt file, is logged and replaced by a fresh history rather than
+// locking scraping out until the file is deleted by hand.
+func loadHistory() store.ScrapeHistory {
+	state, err := store.LoadScrapeHistory()
+	if err != nil {
+		log.Printf("Failed to load scrape history, starting afresh: %v", err)
+		return store.ScrapeHistory{}
+	}
+	return state
+}
+
+// pruneBefore drops times before cutoff from a chronologically sorted slice
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	for i, t := range times {
+		if !t.Before(cutoff) {
+			return times[i:]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package remotesync
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// probeTimeout bounds each connectivity probe
+const probeTimeout = 3 * time.Second
+
+// Reachable reports whether target's server can be reached, by opening (and
+// immediately closing) a TCP connection to it. The server itself is probed
+// rather than the public internet, so a target on the local network works
+// behind a firewall, and one on a network that's down is skipped. A target
+// without a server to probe counts as reachable.
+func Reachable(ctx context.Context, target Target) bool {
+	addr := target.Addr(ctx)
+	if addr == "" {
+		return true
+	}
+	dialer := net.Dialer{Timeout: probeTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// urlAddr returns the host:port of an http(s) or ssh URL, or of an scp-style
+// git remote ("git@github.com:user/repo.git"), with the scheme's default
+// port if none is given. Returns "" for anything else, such as a local path.
+func urlAddr(raw string) string {
+	if !strings.Contains(raw, "://") {
+		// scp-style: [user@]host:path, where host has no slash and isn't a
+		// Windows drive letter
+		host, _, ok := strings.Cut(raw, ":")
+		if !ok || len(host) < 2 || strings.ContainsAny(host, `/\`) {
+			return ""
+		}
+		if _, h, found := strings.Cut(host, "@"); found {
+			host = h
+		}
+		return net.JoinHostPort(host, "22")
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		case "ssh", "git+ssh":
+			port = "22"
+		case "git":
+			port = "9418"
+		default:
+			return ""
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
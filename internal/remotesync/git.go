@@ -0,0 +1,100 @@
+package remotesync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// GitTarget copies files into a local git clone, commits them, and pushes
+type GitTarget struct {
+	cfg config.GitSyncConfig
+}
+
+// NewGitTarget creates a new git target
+func NewGitTarget(cfg config.GitSyncConfig) *GitTarget {
+	return &GitTarget{cfg: cfg}
+}
+
+// Name implements Target
+func (t *GitTarget) Name() string {
+	return "git"
+}
+
+// Addr implements Target with the host of the repo's push remote. Without
+// push, commits stay local and there's nothing to probe.
+func (t *GitTarget) Addr(ctx context.Context) string {
+	if !t.cfg.Push {
+		return ""
+	}
+	out, err := exec.CommandContext(ctx, "git", "-C", t.cfg.RepoDir, "remote", "get-url", "--push", "origin").Output()
+	if err != nil {
+		return ""
+	}
+	return urlAddr(strings.TrimSpace(string(out)))
+}
+
+// Upload implements Target
+func (t *GitTarget) Upload(ctx context.Context, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	name := filepath.Base(path)
+	dest := filepath.Join(t.cfg.RepoDir, name)
+	if err := os.WriteFile(dest, data, 0644); err != nil {
+		return fmt.Errorf("failed to copy digest into repo: %w", err)
+	}
+
+	if err := t.git(ctx, "add", name); err != nil {
+		return err
+	}
+	// The same file again (e.g. a retried delivery whose push failed) has
+	// nothing to commit, but may still need pushing
+	changed, err := t.staged(ctx, name)
+	if err != nil {
+		return err
+	}
+	if changed {
+		if err := t.git(ctx, "commit", "-m", "Add "+name); err != nil {
+			return err
+		}
+	}
+	if t.cfg.Push {
+		if err := t.git(ctx, "push"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// staged reports whether the index holds changes to name that aren't
+// committed yet
+func (t *GitTarget) staged(ctx context.Context, name string) (bool, error) {
+	err := exec.CommandContext(ctx, "git", "-C", t.cfg.RepoDir, "diff", "--cached", "--quiet", "--", name).Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return false, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == 1:
+		return true, nil
+	default:
+		return false, fmt.Errorf("git diff failed: %w", err)
+	}
+}
+
+// git runs a git command in the repo directory
+func (t *GitTarget) git(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", t.cfg.RepoDir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
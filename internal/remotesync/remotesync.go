@@ -0,0 +1,36 @@
+// Package remotesync pushes generated digests to remote storage (WebDAV,
+// S3-compatible object storage, or a git repository) for backup and
+// cross-device reading.
+package remotesync
+
+import (
+	"context"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// Target uploads digest files to a remote location
+type Target interface {
+	// Name identifies the target in logs, e.g. "webdav"
+	Name() string
+	// Upload copies the local file at path to the remote location
+	Upload(ctx context.Context, path string) error
+	// Addr returns the host:port of the server uploads go to, for probing
+	// whether it can be reached, or "" if there's none to probe
+	Addr(ctx context.Context) string
+}
+
+// Targets returns the sync targets enabled in config, in a fixed order
+func Targets(cfg config.SyncConfig) []Target {
+	var targets []Target
+	if cfg.WebDAV.URL != "" {
+		targets = append(targets, NewWebDAVTarget(cfg.WebDAV))
+	}
+	if cfg.S3.Bucket != "" {
+		targets = append(targets, NewS3Target(cfg.S3))
+	}
+	if cfg.Git.RepoDir != "" {
+		targets = append(targets, NewGitTarget(cfg.Git))
+	}
+	return targets
+}
@@ -0,0 +1,146 @@
+package remotesync
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// S3Target uploads files to S3-compatible object storage (AWS S3, MinIO,
+// Backblaze B2, Cloudflare R2, ...) using path-style URLs and SigV4 signing.
+type S3Target struct {
+	cfg    config.S3Config
+	client *http.Client
+}
+
+// NewS3Target creates a new S3 target
+func NewS3Target(cfg config.S3Config) *S3Target {
+	return &S3Target{cfg: cfg, client: http.DefaultClient}
+}
+
+// Name implements Target
+func (t *S3Target) Name() string {
+	return "s3"
+}
+
+// Addr implements Target
+func (t *S3Target) Addr(ctx context.Context) string {
+	return urlAddr(t.cfg.Endpoint)
+}
+
+// Upload implements Target by PUTting the file as an object under the configured prefix
+func (t *S3Target) Upload(ctx context.Context, path string) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(t.cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid S3 endpoint: %w", err)
+	}
+
+	key := strings.Trim(t.cfg.Prefix, "/")
+	if key != "" {
+		key += "/"
+	}
+	key += filepath.Base(path)
+
+	// Keep any path the endpoint has, e.g. a gateway's /storage
+	objectURL := *endpoint
+	objectURL.Path = strings.TrimSuffix(endpoint.Path, "/") + "/" + t.cfg.Bucket + "/" + key
+	objectURL.RawPath = ""
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	t.sign(req, body, time.Now().UTC())
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to S3: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("S3 upload failed: %s: %s", resp.Status, msg)
+	}
+	return nil
+}
+
+// sign adds AWS Signature Version 4 headers to req
+func (t *S3Target) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + t.cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+t.cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, t.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		t.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery returns query in SigV4's canonical form: sorted by name,
+// then value, and percent-encoded with %20 for spaces
+func canonicalQuery(query url.Values) string {
+	escape := func(s string) string {
+		return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+	}
+	var params []string
+	for _, name := range slices.Sorted(maps.Keys(query)) {
+		for _, value := range slices.Sorted(slices.Values(query[name])) {
+			params = append(params, escape(name)+"="+escape(value))
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,68 @@
+package remotesync
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// WebDAVTarget uploads files to a WebDAV collection (e.g. Nextcloud)
+type WebDAVTarget struct {
+	cfg    config.WebDAVConfig
+	client *http.Client
+}
+
+// NewWebDAVTarget creates a new WebDAV target
+func NewWebDAVTarget(cfg config.WebDAVConfig) *WebDAVTarget {
+	return &WebDAVTarget{cfg: cfg, client: http.DefaultClient}
+}
+
+// Name implements Target
+func (t *WebDAVTarget) Name() string {
+	return "webdav"
+}
+
+// Addr implements Target
+func (t *WebDAVTarget) Addr(ctx context.Context) string {
+	return urlAddr(t.cfg.URL)
+}
+
+// Upload implements Target by PUTting the file into the configured collection
+func (t *WebDAVTarget) Upload(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	url := strings.TrimSuffix(t.cfg.URL, "/") + "/" + filepath.Base(path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	if t.cfg.Username != "" {
+		req.SetBasicAuth(t.cfg.Username, t.cfg.Password)
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload to WebDAV: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("WebDAV upload failed: %s", resp.Status)
+	}
+	return nil
+}
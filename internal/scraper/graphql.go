@@ -0,0 +1,433 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"html"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// graphqlWaitTimeout is how long to wait for the first GraphQL timeline
+// response after the page has rendered tweets before falling back to the DOM.
+const graphqlWaitTimeout = 3 * time.Second
+
+// graphqlCollector intercepts X's GraphQL API responses (HomeTimeline,
+// ListLatestTweetsTimeline, SearchTimeline, ...) as the page loads them and
+// decodes the tweets they contain. This yields exact metrics and full text
+// without depending on the DOM. The DOM extractor is the fallback when no
+// responses could be decoded.
+type graphqlCollector struct {
+	mu      sync.Mutex
+	pending map[network.RequestID]bool // GraphQL requests awaiting their body
+	posts   []types.Post
+	seen    map[string]int // Index in posts by post ID
+}
+
+// newGraphQLCollector creates an empty collector
+func newGraphQLCollector() *graphqlCollector {
+	return &graphqlCollector{
+		pending: make(map[network.RequestID]bool),
+		seen:    make(map[string]int),
+	}
+}
+
+// listener returns a chromedp.ListenTarget callback. ctx must be the browser
+// tab context, which is used to fetch response bodies.
+func (c *graphqlCollector) listener(ctx context.Context) func(ev any) {
+	return func(ev any) {
+		switch ev := ev.(type) {
+		case *network.EventResponseReceived:
+			if ev.Response != nil && strings.Contains(ev.Response.URL, selectors().GraphQLPathFragment) {
+				c.mu.Lock()
+				c.pending[ev.RequestID] = true
+				c.mu.Unlock()
+			}
+		case *network.EventLoadingFinished:
+			c.mu.Lock()
+			ok := c.pending[ev.RequestID]
+			delete(c.pending, ev.RequestID)
+			c.mu.Unlock()
+			if ok {
+				// Listeners must not block, so fetch the body asynchronously
+				go c.fetch(ctx, ev.RequestID)
+			}
+		}
+	}
+}
+
+// fetch retrieves a response body and decodes any tweets in it
+func (c *graphqlCollector) fetch(ctx context.Context, id network.RequestID) {
+	tab := chromedp.FromContext(ctx)
+	if tab == nil || tab.Target == nil {
+		return
+	}
+	body, err := network.GetResponseBody(id).Do(cdp.WithExecutor(ctx, tab.Target))
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Printf("Failed to read GraphQL response body: %v", err)
+		}
+		return
+	}
+
+	var payload any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return // Not JSON (or truncated) - nothing to decode
+	}
+
+	var found []types.Post
+	now := time.Now()
+	walkTweetResults(payload, func(raw json.RawMessage, promoted bool) {
+		var result gqlTweetResult
+		if err := json.Unmarshal(raw, &result); err != nil {
+			return
+		}
+		if post, ok := result.toPost(now); ok {
+			post.IsPromoted = promoted
+			found = append(found, post)
+		}
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, post := range found {
+		if i, ok := c.seen[post.ID]; ok {
+			c.posts[i].MergeRetweeters(post)
+			continue
+		}
+		c.seen[post.ID] = len(c.posts)
+		c.posts = append(c.posts, post)
+	}
+}
+
+// Posts returns a copy of the posts decoded so far, in timeline order
+func (c *graphqlCollector) Posts() []types.Post {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]types.Post(nil), c.posts...)
+}
+
+// mergeDOMPosts adds the posts extracted from the DOM that aren't among
+// the GraphQL posts, e.g. ones X rendered from a response that couldn't be
+// read, to them. For posts in both, the GraphQL copy is kept, taking any
+// reposters only the DOM showed.
+func mergeDOMPosts(gqlPosts, domPosts []types.Post) []types.Post {
+	byID := make(map[string]int, len(gqlPosts))
+	for i, post := range gqlPosts {
+		byID[post.ID] = i
+	}
+	for _, post := range domPosts {
+		if i, ok := byID[post.ID]; ok {
+			gqlPosts[i].MergeRetweeters(post)
+			continue
+		}
+		byID[post.ID] = len(gqlPosts)
+		gqlPosts = append(gqlPosts, post)
+	}
+	return gqlPosts
+}
+
+// waitForPosts waits up to timeout for the first decoded posts to arrive.
+// Returns false if none arrived.
+func (c *graphqlCollector) waitForPosts(ctx context.Context, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		c.mu.Lock()
+		n := len(c.posts)
+		c.mu.Unlock()
+		if n > 0 {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	return false
+}
+
+// walkTweetResults calls fn for the result object of every "tweet_results"
+// entry found in a decoded GraphQL payload, along with whether the entry is
+// an ad (ads carry promotedMetadata next to their tweet_results). Array
+// order is preserved, so timeline entries are visited in the order X
+// returned them.
+func walkTweetResults(v any, fn func(raw json.RawMessage, promoted bool)) {
+	switch v := v.(type) {
+	case []any:
+		for _, item := range v {
+			walkTweetResults(item, fn)
+		}
+	case map[string]any:
+		if tr, ok := v["tweet_results"].(map[string]any); ok {
+			if result, ok := tr["result"]; ok {
+				if raw, err := json.Marshal(result); err == nil {
+					fn(raw, v["promotedMetadata"] != nil)
+				}
+			}
+		}
+		for key, child := range v {
+			if key == "tweet_results" {
+				continue
+			}
+			walkTweetResults(child, fn)
+		}
+	}
+}
+
+// gqlTweetResult is the subset of X's GraphQL Tweet object that we use
+type gqlTweetResult struct {
+	Typename string          `json:"__typename"`
+	RestID   string          `json:"rest_id"`
+	Tweet    *gqlTweetResult `json:"tweet"` // Set on TweetWithVisibilityResults wrappers
+	Core     struct {
+		UserResults struct {
+			Result gqlUser `json:"result"`
+		} `json:"user_results"`
+	} `json:"core"`
+	Views struct {
+		Count string `json:"count"`
+	} `json:"views"`
+	NoteTweet struct {
+		NoteTweetResults struct {
+			Result struct {
+				Text string `json:"text"`
+			} `json:"result"`
+		} `json:"note_tweet_results"`
+	} `json:"note_tweet"`
+	Legacy             gqlTweetLegacy `json:"legacy"`
+	Card               gqlCard        `json:"card"`
+	QuotedStatusResult struct {
+		Result *gqlTweetResult `json:"result"`
+	} `json:"quoted_status_result"`
+}
+
+// gqlTweetLegacy holds the v1.1-style tweet fields
+type gqlTweetLegacy struct {
+	FullText             string `json:"full_text"`
+	Lang                 string `json:"lang"`
+	CreatedAt            string `json:"created_at"`
+	FavoriteCount        int    `json:"favorite_count"`
+	RetweetCount         int    `json:"retweet_count"`
+	ReplyCount           int    `json:"reply_count"`
+	QuoteCount           int    `json:"quote_count"`
+	IsQuoteStatus        bool   `json:"is_quote_status"`
+	InReplyToStatusIDStr string `json:"in_reply_to_status_id_str"`
+	InReplyToScreenName  string `json:"in_reply_to_screen_name"`
+	ExtendedEntities     struct {
+		Media []struct {
+			MediaURLHTTPS string `json:"media_url_https"`
+			ExtAltText    string `json:"ext_alt_text"`
+		} `json:"media"`
+	} `json:"extended_entities"`
+	RetweetedStatusResult struct {
+		Result *gqlTweetResult `json:"result"`
+	} `json:"retweeted_status_result"`
+}
+
+// gqlCard is an attached card (link preview, poll, ...) as key/value bindings
+type gqlCard struct {
+	Legacy struct {
+		Name          string `json:"name"` // e.g. "poll2choice_text_only"
+		BindingValues []struct {
+			Key   string `json:"key"`
+			Value struct {
+				StringValue  string `json:"string_value"`
+				BooleanValue bool   `json:"boolean_value"`
+			} `json:"value"`
+		} `json:"binding_values"`
+	} `json:"legacy"`
+}
+
+// poll decodes a poll card, returning nil if the card isn't a poll
+func (c gqlCard) poll() *types.Poll {
+	if !strings.HasPrefix(c.Legacy.Name, "poll") {
+		return nil
+	}
+
+	labels := make(map[int]string)
+	counts := make(map[int]int)
+	poll := &types.Poll{}
+	for _, b := range c.Legacy.BindingValues {
+		if b.Key == "counts_are_final" {
+			poll.Closed = b.Value.BooleanValue
+		} else if n, ok := choiceIndex(b.Key, "_label"); ok {
+			labels[n] = b.Value.StringValue
+		} else if n, ok := choiceIndex(b.Key, "_count"); ok {
+			counts[n], _ = strconv.Atoi(b.Value.StringValue)
+		}
+	}
+
+	for n := 1; labels[n] != ""; n++ {
+		poll.TotalVotes += counts[n]
+	}
+	for n := 1; labels[n] != ""; n++ {
+		choice := types.PollChoice{Label: labels[n]}
+		if poll.TotalVotes > 0 {
+			choice.Percent = float64(counts[n]) * 100 / float64(poll.TotalVotes)
+		}
+		poll.Choices = append(poll.Choices, choice)
+	}
+	return poll
+}
+
+// linkCard decodes a link preview card, returning nil for polls and other
+// card types without a title
+func (c gqlCard) linkCard() *types.LinkCard {
+	if strings.HasPrefix(c.Legacy.Name, "poll") {
+		return nil
+	}
+
+	card := &types.LinkCard{}
+	for _, b := range c.Legacy.BindingValues {
+		switch b.Key {
+		case "card_url":
+			card.URL = b.Value.StringValue
+		case "vanity_url", "domain":
+			card.Domain = b.Value.StringValue
+		case "title":
+			card.Title = b.Value.StringValue
+		case "description":
+			card.Description = b.Value.StringValue
+		}
+	}
+	if card.URL == "" || card.Title == "" {
+		return nil
+	}
+	return card
+}
+
+// choiceIndex parses binding keys like "choice2_label" (with suffix "_label"),
+// returning the choice number.
+func choiceIndex(key, suffix string) (int, bool) {
+	rest, ok := strings.CutPrefix(key, "choice")
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, suffix)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(rest)
+	return n, err == nil
+}
+
+// gqlUser is the subset of X's GraphQL User object that we use.
+// Newer payloads carry name/screen_name under core, older ones under legacy.
+type gqlUser struct {
+	IsBlueVerified bool `json:"is_blue_verified"`
+	Core           struct {
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+	} `json:"core"`
+	Legacy struct {
+		Name       string `json:"name"`
+		ScreenName string `json:"screen_name"`
+		Verified   bool   `json:"verified"`
+	} `json:"legacy"`
+}
+
+// handle returns the user's screen name from whichever schema is present
+func (u gqlUser) handle() string {
+	if u.Core.ScreenName != "" {
+		return u.Core.ScreenName
+	}
+	return u.Legacy.ScreenName
+}
+
+// name returns the user's display name from whichever schema is present
+func (u gqlUser) name() string {
+	if u.Core.Name != "" {
+		return u.Core.Name
+	}
+	return u.Legacy.Name
+}
+
+// toPost converts a GraphQL tweet into a Post. Retweets are represented by
+// the original tweet with IsRetweet set, matching what the timeline shows.
+func (r *gqlTweetResult) toPost(now time.Time) (types.Post, bool) {
+	if r.Tweet != nil {
+		return r.Tweet.toPost(now)
+	}
+	if r.Typename != "" && r.Typename != "Tweet" {
+		return types.Post{}, false // e.g. TweetTombstone, TweetUnavailable
+	}
+
+	if original := r.Legacy.RetweetedStatusResult.Result; original != nil {
+		post, ok := original.toPost(now)
+		post.IsRetweet = true
+		if retweeter := r.Core.UserResults.Result.handle(); retweeter != "" {
+			post.RetweetedBy = []string{retweeter}
+		}
+		return post, ok
+	}
+
+	user := r.Core.UserResults.Result
+	handle := user.handle()
+	if r.RestID == "" || handle == "" {
+		return types.Post{}, false
+	}
+
+	// full_text is HTML-escaped (e.g. "&amp;")
+	content := html.UnescapeString(r.Legacy.FullText)
+	if note := r.NoteTweet.NoteTweetResults.Result.Text; note != "" {
+		content = note // Long posts are truncated in full_text
+	}
+
+	var mediaURLs, mediaAltText []string
+	for _, m := range r.Legacy.ExtendedEntities.Media {
+		mediaURLs = append(mediaURLs, m.MediaURLHTTPS)
+		if m.ExtAltText != "" {
+			mediaAltText = append(mediaAltText, m.ExtAltText)
+		}
+	}
+
+	var timestamp time.Time
+	if parsed, err := time.Parse(time.RubyDate, r.Legacy.CreatedAt); err == nil {
+		timestamp = parsed
+	}
+
+	views, _ := strconv.Atoi(r.Views.Count)
+
+	var quoted *types.Post
+	if q := r.QuotedStatusResult.Result; q != nil {
+		if qp, ok := q.toPost(now); ok {
+			quoted = &qp
+		}
+	}
+
+	return types.Post{
+		ID:             r.RestID,
+		AuthorHandle:   handle,
+		AuthorName:     user.name(),
+		AuthorVerified: user.IsBlueVerified || user.Legacy.Verified,
+		Content:        content,
+		Lang:           r.Legacy.Lang,
+		MediaURLs:      mediaURLs,
+		MediaAltText:   mediaAltText,
+		Timestamp:      timestamp,
+		Likes:          r.Legacy.FavoriteCount,
+		Retweets:       r.Legacy.RetweetCount,
+		Replies:        r.Legacy.ReplyCount,
+		QuoteTweets:    r.Legacy.QuoteCount,
+		Views:          views,
+		IsQuoteTweet:   r.Legacy.IsQuoteStatus,
+		QuotedPost:     quoted,
+		Poll:           r.Card.poll(),
+		Card:           r.Card.linkCard(),
+		IsReply:        r.Legacy.InReplyToStatusIDStr != "",
+		ReplyToID:      r.Legacy.InReplyToStatusIDStr,
+		ReplyToHandle:  r.Legacy.InReplyToScreenName,
+		OriginalURL:    "https://x.com/" + handle + "/status/" + r.RestID,
+		ScrapedAt:      now,
+	}, true
+}
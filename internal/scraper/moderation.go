@@ -0,0 +1,112 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Account list scraping limits
+const (
+	moderationTimeout      = 5 * time.Minute  // Both lists together
+	accountListLoadTimeout = 15 * time.Second // An empty list shows no cells, so don't wait longer
+	accountListIdleScrolls = 3                // Scrolls without new accounts before a list is done
+)
+
+// ScrapeModeratedAccounts reads the handles of the accounts muted and
+// blocked by the logged-in user from X's settings pages, in one browser.
+func (s *Scraper) ScrapeModeratedAccounts(ctx context.Context, cookies []*network.Cookie) (muted, blocked []string, err error) {
+	sel := reloadSelectors()
+	if s.replay {
+		return nil, nil, ErrReplaying
+	}
+	if err := s.limiter.Acquire(ctx, "muted and blocked accounts"); err != nil {
+		return nil, nil, fmt.Errorf("not scraping muted and blocked accounts: %w", err)
+	}
+	return s.runModerationScrape(ctx, cookies, sel)
+}
+
+// runModerationScrape implements ScrapeModeratedAccounts once the rate
+// limiter allows it
+func (s *Scraper) runModerationScrape(ctx context.Context, cookies []*network.Cookie, sel *Selectors) (muted, blocked []string, err error) {
+	log.Println("Starting scrape of muted and blocked accounts")
+	pageCtx, cancel, err := s.openBrowser(ctx, cookies, s.timeout(moderationTimeout))
+	if err != nil {
+		return nil, nil, err
+	}
+	defer cancel()
+
+	if muted, err = s.scrapeAccountList(pageCtx, sel.MutedAccountsURL, sel); err != nil {
+		return nil, nil, fmt.Errorf("failed to read muted accounts: %w", err)
+	}
+	if blocked, err = s.scrapeAccountList(pageCtx, sel.BlockedAccountsURL, sel); err != nil {
+		return nil, nil, fmt.Errorf("failed to read blocked accounts: %w", err)
+	}
+	log.Printf("Scraped %d muted and %d blocked accounts", len(muted), len(blocked))
+	return muted, blocked, nil
+}
+
+// scrapeAccountList scrolls the account list at url to its end and returns
+// the handles of its user cells, in order
+func (s *Scraper) scrapeAccountList(ctx context.Context, url string, sel *Selectors) ([]string, error) {
+	log.Printf("Navigating to %s...", url)
+	stateJS := fmt.Sprintf(`
+		(function() {
+			if (document.querySelector(%q)) return 'accounts';
+			if (document.querySelector(%q) || location.pathname.startsWith(%q)) return 'login';
+			return '';
+		})()
+	`, sel.UserCell, sel.LoginForm, sel.LoginFlowPath)
+	if err := chromedp.Run(ctx, chromedp.Navigate(url)); err != nil {
+		return nil, err
+	}
+	var state string
+	loadCtx, cancel := context.WithTimeout(ctx, s.timeout(accountListLoadTimeout))
+	err := chromedp.Run(loadCtx, chromedp.Poll(stateJS, &state, chromedp.WithPollingInterval(500*time.Millisecond)))
+	timedOut := loadCtx.Err() != nil && ctx.Err() == nil
+	cancel()
+	switch {
+	case timedOut:
+		return nil, nil // No accounts in the list
+	case err != nil:
+		return nil, err
+	case state == "login":
+		return nil, ErrSessionInvalid
+	}
+
+	// The handle is the first "@name" in a cell's text; the bio comes after
+	handlesJS := fmt.Sprintf(`
+		Array.from(document.querySelectorAll(%q), cell => {
+			const m = cell.innerText.match(/@(\w{1,15})/);
+			return m ? m[1] : '';
+		}).filter(Boolean)
+	`, sel.UserCell)
+
+	var handles []string
+	seen := make(map[string]bool)
+	for idle := 0; idle < accountListIdleScrolls; {
+		var visible []string
+		if err := chromedp.Run(ctx, chromedp.Evaluate(handlesJS, &visible)); err != nil {
+			return nil, err
+		}
+		idle++
+		for _, h := range visible {
+			if !seen[h] {
+				seen[h] = true
+				handles = append(handles, h)
+				idle = 0
+			}
+		}
+		if err := s.scroll(ctx); err != nil {
+			return nil, err
+		}
+		if err := sleepRandom(ctx, 500*time.Millisecond, time.Second); err != nil {
+			return nil, err
+		}
+	}
+	return handles, nil
+}
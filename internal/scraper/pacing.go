@@ -0,0 +1,74 @@
+package scraper
+
+import (
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// Pacing defaults
+const (
+	defaultScrollDelay   = 500 * time.Millisecond
+	defaultScrollJitter  = 300 * time.Millisecond
+	defaultTimePerPost   = time.Second
+	defaultMinScrapeTime = time.Minute
+	// Bounds how long a page may take to show either tweets or one of the
+	// known session problem pages
+	defaultPageLoadTimeout = 30 * time.Second
+)
+
+// Pacing tunes timeline scrapes: how they scroll and how long they may
+// take. Zero fields mean the default.
+type Pacing struct {
+	ScrollDelay     time.Duration // Pause after each scroll (default 500ms)
+	ScrollJitter    time.Duration // Up to this much more, at random (default 300ms)
+	MaxIdleScrolls  int           // Scrolls in a row without new posts before giving up (default: never, until the time runs out)
+	TimePerPost     time.Duration // Scrape time allowed per post requested (default 1s)
+	MinScrapeTime   time.Duration // But at least this much (default 1m)
+	PageLoadTimeout time.Duration // For a page to show tweets or a login wall (default 30s)
+}
+
+// PacingFromConfig reads the pacing settings of the [scraping] config
+func PacingFromConfig(cfg config.ScrapingConfig) Pacing {
+	return Pacing{
+		ScrollDelay:     time.Duration(cfg.ScrollDelayMs) * time.Millisecond,
+		ScrollJitter:    time.Duration(cfg.ScrollJitterMs) * time.Millisecond,
+		MaxIdleScrolls:  cfg.MaxIdleScrolls,
+		TimePerPost:     time.Duration(cfg.ScrapeSecondsPerPost * float64(time.Second)),
+		MinScrapeTime:   time.Duration(cfg.MinScrapeTimeoutSeconds) * time.Second,
+		PageLoadTimeout: time.Duration(cfg.PageLoadTimeoutSeconds) * time.Second,
+	}
+}
+
+// withDefaults fills in the zero fields
+func (p Pacing) withDefaults() Pacing {
+	if p.ScrollDelay <= 0 {
+		p.ScrollDelay = defaultScrollDelay
+	}
+	if p.ScrollJitter <= 0 {
+		p.ScrollJitter = defaultScrollJitter
+	}
+	if p.TimePerPost <= 0 {
+		p.TimePerPost = defaultTimePerPost
+	}
+	if p.MinScrapeTime <= 0 {
+		p.MinScrapeTime = defaultMinScrapeTime
+	}
+	if p.PageLoadTimeout <= 0 {
+		p.PageLoadTimeout = defaultPageLoadTimeout
+	}
+	return p
+}
+
+// scrapeTime is how long a scrape of count posts may take
+func (p Pacing) scrapeTime(count int) time.Duration {
+	return max(time.Duration(count)*p.TimePerPost, p.MinScrapeTime)
+}
+
+// WithPacing returns a copy of the scraper that scrolls and times out
+// timeline scrapes as p says
+func (s *Scraper) WithPacing(p Pacing) *Scraper {
+	paced := *s
+	paced.pacing = p.withDefaults()
+	return &paced
+}
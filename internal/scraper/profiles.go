@@ -0,0 +1,109 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// profileLoadTimeout bounds how long each profile page may take to show its
+// header
+const profileLoadTimeout = 20 * time.Second
+
+// profileInfo is the profile header as read by the page script
+type profileInfo struct {
+	Found     bool   `json:"found"`
+	Name      string `json:"name"`
+	Bio       string `json:"bio"`
+	Followers string `json:"followers"` // e.g. "12.3K Followers"
+	Verified  bool   `json:"verified"`
+}
+
+// ScrapeAuthorProfiles reads the profile header (name, bio, follower count,
+// and verification) of each handle, visiting their profile pages one after
+// another in a single browser. Handles whose page fails to load are logged
+// and left out of the result, which is keyed by handle as given.
+func (s *Scraper) ScrapeAuthorProfiles(ctx context.Context, cookies []*network.Cookie, handles []string) (map[string]types.AuthorProfile, error) {
+	sel := reloadSelectors()
+	if s.replay {
+		return nil, ErrReplaying
+	}
+	if err := s.limiter.Acquire(ctx, "author profiles"); err != nil {
+		return nil, fmt.Errorf("not scraping author profiles: %w", err)
+	}
+	return s.runProfilesScrape(ctx, cookies, handles, sel)
+}
+
+// runProfilesScrape implements ScrapeAuthorProfiles once the rate limiter
+// allows it
+func (s *Scraper) runProfilesScrape(ctx context.Context, cookies []*network.Cookie, handles []string, sel *Selectors) (map[string]types.AuthorProfile, error) {
+	log.Printf("Starting scrape of %d author profiles", len(handles))
+	timeout := s.timeout(time.Duration(len(handles)) * profileLoadTimeout)
+	browserCtx, cancel, err := s.openBrowser(ctx, cookies, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	verified, err := json.Marshal(sel.extractionChains(s.mobile)["verified"])
+	if err != nil {
+		return nil, err
+	}
+	headerJS := fmt.Sprintf(`
+		(function() {
+			const header = document.querySelector(%q);
+			if (!header) return {found: false};
+			const lines = header.innerText.split('\n').map(l => l.trim()).filter(Boolean);
+			const bio = document.querySelector(%q);
+			const followers = document.querySelector(%q);
+			return {
+				found: true,
+				name: lines[0] || '',
+				bio: bio ? bio.innerText.trim() : '',
+				followers: followers ? followers.innerText.trim() : '',
+				verified: %s.some(s => header.querySelector(s)),
+			};
+		})()
+	`, sel.ProfileUserName, sel.ProfileBio, sel.ProfileFollowers, verified)
+
+	now := time.Now()
+	profiles := make(map[string]types.AuthorProfile, len(handles))
+	for _, handle := range handles {
+		if browserCtx.Err() != nil {
+			log.Printf("Out of time after %d author profiles", len(profiles))
+			break
+		}
+
+		var info profileInfo
+		pageCtx, pageCancel := context.WithTimeout(browserCtx, s.timeout(profileLoadTimeout))
+		err := chromedp.Run(pageCtx,
+			chromedp.Navigate(sel.ProfileURLPrefix+strings.TrimPrefix(handle, "@")),
+			chromedp.Poll(headerJS+`.found || null`, nil, chromedp.WithPollingInterval(500*time.Millisecond)),
+			chromedp.Evaluate(headerJS, &info),
+		)
+		pageCancel()
+		if err != nil {
+			log.Printf("Failed to read profile of @%s: %v", handle, err)
+			continue
+		}
+
+		followers, _, _ := strings.Cut(info.Followers, " ")
+		profiles[handle] = types.AuthorProfile{
+			Name:      info.Name,
+			Bio:       info.Bio,
+			Followers: parseMetric(followers),
+			Verified:  info.Verified,
+			FetchedAt: now,
+		}
+	}
+	log.Printf("Scraped %d author profiles", len(profiles))
+	return profiles, nil
+}
@@ -2,9 +2,13 @@ package scraper
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -13,9 +17,46 @@ import (
 	"github.com/chromedp/chromedp"
 
 	"github.com/ibeckermayer/scroll4me/internal/browser"
+	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/ratelimit"
+	"github.com/ibeckermayer/scroll4me/internal/store"
 	"github.com/ibeckermayer/scroll4me/internal/types"
 )
 
+// ErrSessionInvalid is returned when X shows a login wall instead of the
+// requested page, meaning the stored session has expired or been revoked.
+var ErrSessionInvalid = errors.New("X session is invalid - run 'scroll4me login' to log in again")
+
+// ErrAccountChallenge is returned when X interrupts with a verification
+// challenge ("unusual activity", captcha, or phone/email confirmation).
+// Logging in again won't help until it's completed in a browser.
+var ErrAccountChallenge = errors.New("X wants to verify the account (unusual activity check) - open x.com in a browser and complete it")
+
+// ErrAccountLocked is returned when X shows a locked or suspended account page.
+var ErrAccountLocked = errors.New("X account is locked or suspended - open x.com in a browser to resolve it")
+
+// ErrReplaying is returned by scrapes that can't be replayed from saved
+// snapshots when the scraper is in replay mode, since it mustn't contact X.
+var ErrReplaying = errors.New("scraper is replaying saved snapshots and doesn't contact X")
+
+// ErrLoginRequired is returned by guest-mode scrapes of pages that X only
+// shows to logged-in users.
+var ErrLoginRequired = errors.New("page is not public - X requires a logged-in session to view it")
+
+// ErrScrapeTimeout is returned when a page doesn't load within its timeout.
+// A slow connection may manage with longer timeouts.
+var ErrScrapeTimeout = errors.New("page didn't load in time")
+
+// ErrTooFewPosts is returned, along with the posts collected, when a
+// timeline scrape runs out of time with less than a third of the posts
+// asked for. A scraper with longer timeouts (see WithLongerTimeouts) makes
+// do with what it collected instead.
+var ErrTooFewPosts = errors.New("scrape timed out with too few posts")
+
+// minTimedOutShare is the divisor of the post count below which a timed
+// out scrape fails with ErrTooFewPosts
+const minTimedOutShare = 3
+
 // Scraper handles extracting posts from X.com
 type Scraper struct {
 	headless bool
@@ -23,11 +64,121 @@ type Scraper struct {
 	// wait for the browser to close before continuing. This is useful
 	// for debugging the scrape process.
 	debugPauseAfterScrape bool
+	// If set, Chrome runs with this persistent profile directory and the
+	// session it holds is used instead of injecting stored cookies.
+	profileDir string
+	// How human-like scrolling is
+	stealth StealthLevel
+	// Gates browser launches; nil means no limits
+	limiter *ratelimit.Limiter
+	// If set, scrapes open tabs in this already-running Chrome (DevTools
+	// URL) instead of launching one
+	remoteURL string
+	// If true, the remote browser is logged in to X itself and cookies
+	// aren't injected
+	remoteSession bool
+	// Post IDs seen in an earlier run; timeline scrolling stops after
+	// stopAfterKnown of them in a row (0 = never)
+	knownIDs       map[string]bool
+	stopAfterKnown int
+	// Multiplies the scrape and session check timeouts (0 = 1)
+	timeoutFactor int
+	// Scroll delays, idle scroll budget, and timeouts of timeline scrapes
+	pacing Pacing
+	// If true, pages are loaded as Chrome on an Android phone and parsed
+	// with the mobile selector chains
+	mobile bool
+	// If true, the markup of the tweets each scrape sees is saved to
+	// step1_html for re-parsing
+	saveHTML bool
+	// If true, timeline scrapes are replayed from the saved snapshots in
+	// replayDir and no browser is pointed at X
+	replay    bool
+	replayDir string
+}
+
+// New creates a new scraper. stealthLevel is one of the StealthLevel values
+// ("" means StealthLow). limiter may be nil.
+func New(headless bool, debugPauseAfterScrape bool, profileDir string, stealthLevel string, limiter *ratelimit.Limiter) *Scraper {
+	return &Scraper{
+		headless:              headless,
+		debugPauseAfterScrape: debugPauseAfterScrape,
+		profileDir:            profileDir,
+		stealth:               parseStealthLevel(stealthLevel),
+		limiter:               limiter,
+		pacing:                Pacing{}.withDefaults(),
+	}
 }
 
-// New creates a new scraper
-func New(headless bool, debugPauseAfterScrape bool) *Scraper {
-	return &Scraper{headless: headless, debugPauseAfterScrape: debugPauseAfterScrape}
+// FromConfig creates a scraper as the [scraping] config describes: its
+// browser, stealth level, rate limits, pacing, remote browser, mobile
+// emulation, and HTML snapshots
+func FromConfig(cfg config.ScrapingConfig) *Scraper {
+	s := New(cfg.Headless, cfg.DebugPauseAfterScrape, cfg.ProfileDir, cfg.StealthLevel, ratelimit.New(cfg)).
+		WithPacing(PacingFromConfig(cfg))
+	if url := cfg.RemoteDebuggingURL; url != "" {
+		s = s.WithRemoteBrowser(url, true)
+	}
+	if cfg.MobileEmulation {
+		s = s.WithMobileEmulation()
+	}
+	if cfg.SaveHTMLSnapshots {
+		s = s.WithHTMLSnapshots()
+	}
+	return s
+}
+
+// WithRemoteBrowser returns a copy of the scraper that drives the Chrome at
+// the given DevTools URL (e.g. http://127.0.0.1:9222) instead of launching
+// its own. If ownSession is true, that browser is already logged in to X
+// and keeps its session; otherwise the stored cookies are injected as usual.
+func (s *Scraper) WithRemoteBrowser(url string, ownSession bool) *Scraper {
+	remote := *s
+	remote.remoteURL = url
+	remote.remoteSession = ownSession
+	return &remote
+}
+
+// WithKnownPosts returns a copy of the scraper that stops scrolling once it
+// has collected stopAfter posts in a row whose IDs are in known, since the
+// rest of the timeline was most likely read last time. Thread scrapes
+// ignore this.
+func (s *Scraper) WithKnownPosts(known map[string]bool, stopAfter int) *Scraper {
+	incremental := *s
+	incremental.knownIDs = known
+	incremental.stopAfterKnown = stopAfter
+	return &incremental
+}
+
+// WithLongerTimeouts returns a copy of the scraper whose scrape and session
+// check timeouts are factor times longer, for retrying on a slow connection
+func (s *Scraper) WithLongerTimeouts(factor int) *Scraper {
+	patient := *s
+	patient.timeoutFactor = factor
+	return &patient
+}
+
+// WithMobileEmulation returns a copy of the scraper that emulates a phone
+// (user agent, viewport, touch), so X serves its mobile layout, and extracts
+// posts with the mobile selector chains
+func (s *Scraper) WithMobileEmulation() *Scraper {
+	mobile := *s
+	mobile.mobile = true
+	return &mobile
+}
+
+// WithHTMLSnapshots returns a copy of the scraper that saves the markup of
+// the tweets each timeline scrape sees as a store.HTMLSnapshot, for
+// ReparseSnapshot
+func (s *Scraper) WithHTMLSnapshots() *Scraper {
+	saving := *s
+	saving.saveHTML = true
+	return &saving
+}
+
+// timeout scales d by the scraper's timeout factor
+func (s *Scraper) timeout(d time.Duration) time.Duration {
+	return d * time.Duration(max(s.timeoutFactor, 1))
 }
 
 // extractFunc is a function that extracts posts from the current view
@@ -36,6 +187,10 @@ type extractFunc func(ctx context.Context) ([]types.Post, error)
 // scrollAndCollectParams configures the scroll-and-collect loop
 type scrollAndCollectParams struct {
 	maxCount         int
+	maxIdleScrolls   int // Stop after this many scrolls in a row find nothing new (0 = keep going)
+	knownIDs         map[string]bool
+	stopAfterKnown   int                      // Stop after this many collected posts in a row are in knownIDs (0 = keep going)
+	checkpoint       func(posts []types.Post) // If set, called with everything collected after each scroll that found new posts
 	extractor        extractFunc
 	logPrefix        string
 	baseDelayMs      int
@@ -43,10 +198,15 @@ type scrollAndCollectParams struct {
 }
 
 // scrollAndCollect is the common scroll-collect-dedupe loop used by extractPosts.
-// It scrolls until maxCount posts are collected or the context is cancelled (timeout).
+// It scrolls until maxCount posts are collected, the page runs out of new
+// posts (if maxIdleScrolls is set), it reaches posts already known from an
+// earlier run (if stopAfterKnown is set), or the context is cancelled
+// (timeout).
 func (s *Scraper) scrollAndCollect(ctx context.Context, p scrollAndCollectParams) ([]types.Post, error) {
 	var posts []types.Post
-	seenIDs := make(map[string]bool)
+	seenIDs := make(map[string]int) // Index in posts
+	idleScrolls := 0
+	knownRun := 0
 
 	for scrollNum := 1; ; scrollNum++ {
 		// Check if context is done (timeout or cancellation)
@@ -72,22 +232,49 @@ func (s *Scraper) scrollAndCollect(ctx context.Context, p scrollAndCollectParams
 		// Add unique posts, break if we hit maxCount
 		newUniqueCount := 0
 		for _, post := range newPosts {
-			if !seenIDs[post.ID] {
-				seenIDs[post.ID] = true
+			if i, ok := seenIDs[post.ID]; ok {
+				// Reposts show up as the original post again
+				posts[i].MergeRetweeters(post)
+			} else {
+				seenIDs[post.ID] = len(posts)
 				posts = append(posts, post)
 				newUniqueCount++
+				if p.knownIDs[post.ID] {
+					knownRun++
+				} else {
+					knownRun = 0
+				}
 				if len(posts) >= p.maxCount {
 					break
 				}
+				if p.stopAfterKnown > 0 && knownRun >= p.stopAfterKnown {
+					break
+				}
 			}
 		}
 
 		log.Printf("%s %d: found %d visible, %d new unique (total: %d/%d)",
 			p.logPrefix, scrollNum, len(newPosts), newUniqueCount, len(posts), p.maxCount)
+		if p.checkpoint != nil && newUniqueCount > 0 {
+			p.checkpoint(posts)
+		}
 
 		if len(posts) >= p.maxCount {
 			break
 		}
+		if p.stopAfterKnown > 0 && knownRun >= p.stopAfterKnown {
+			log.Printf("%s: reached %d posts in a row from the last run, stopping", p.logPrefix, knownRun)
+			break
+		}
+		if newUniqueCount == 0 {
+			idleScrolls++
+		} else {
+			idleScrolls = 0
+		}
+		if p.maxIdleScrolls > 0 && idleScrolls >= p.maxIdleScrolls {
+			log.Printf("%s: no new posts after %d scrolls, stopping", p.logPrefix, idleScrolls)
+			break
+		}
 
 		if err := s.scroll(ctx); err != nil {
 			if ctx.Err() != nil {
@@ -99,52 +286,407 @@ func (s *Scraper) scrollAndCollect(ctx context.Context, p scrollAndCollectParams
 		// Randomized wait for human-like timing
 		jitter := rand.Intn(p.delayJitterMaxMs)
 		wait := p.baseDelayMs + jitter
-		time.Sleep(time.Duration(wait) * time.Millisecond)
+		if s.stealth == StealthHigh {
+			// Linger now and then, as if reading a post
+			wait += rand.Intn(highStealthExtraDelayMaxMs)
+		}
+		if err := sleep(ctx, time.Duration(wait)*time.Millisecond); err != nil {
+			continue // Reported by the check at the top of the loop
+		}
 	}
 
 	return posts, nil
 }
 
+// threadMaxPosts caps how many posts are read from a conversation page
+const threadMaxPosts = 50
+
+// scrapeTarget describes a page to scrape posts from
+type scrapeTarget struct {
+	name   string // Human-readable name for logging, e.g. "For You feed"
+	url    string
+	source string // Recorded on each post, e.g. types.SourceFeed
+	via    string // Which feed, list, or query within source (optional)
+	// Stop once this many scrolls in a row find no new posts, for pages
+	// that end (e.g. a thread) rather than scroll forever. 0 = never.
+	maxIdleScrolls int
+	// If true, posts aren't checkpointed while scrolling, for scrapes whose
+	// partial results aren't worth salvaging into a later feed scrape
+	skipCheckpoint bool
+	// If true, the fetch is a best-effort extra (a thread, a reply's parent,
+	// a post's replies) whose failure isn't reported to the rate limiter
+	bestEffort bool
+	// prepare runs after the page has loaded and before extraction (optional),
+	// e.g. to switch to a different tab.
+	prepare func(ctx context.Context) error
+}
+
+// label records where posts were scraped from on each of them
+func (t scrapeTarget) label(posts []types.Post) {
+	for i := range posts {
+		posts[i].Source = t.source
+		posts[i].FetchedVia = t.via
+	}
+}
+
 // ScrapeForYou fetches posts from the For You feed
 func (s *Scraper) ScrapeForYou(ctx context.Context, cookies []*network.Cookie, count int) ([]types.Post, error) {
-	log.Printf("Starting scrape for %d posts (headless=%v, debugPauseAfterScrape=%v)", count, s.headless, s.debugPauseAfterScrape)
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   "For You feed",
+		url:    reloadSelectors().HomeURL,
+		source: types.SourceFeed,
+		via:    "For You",
+	})
+}
 
-	// Create browser context with anti-bot-detection options
-	opts := browser.Options(s.headless)
+// ScrapeFollowing fetches posts from the chronological Following feed
+func (s *Scraper) ScrapeFollowing(ctx context.Context, cookies []*network.Cookie, count int) ([]types.Post, error) {
+	sel := reloadSelectors()
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   "Following feed",
+		url:    sel.HomeURL,
+		source: types.SourceFeed,
+		via:    "Following",
+		prepare: func(ctx context.Context) error {
+			return s.selectTab(ctx, sel.FollowingTabLabel)
+		},
+	})
+}
 
-	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, opts...)
-	defer allocCancel()
+// ScrapeList fetches posts from an X List. listURL may be a full list URL
+// (https://x.com/i/lists/123) or a bare list ID.
+func (s *Scraper) ScrapeList(ctx context.Context, cookies []*network.Cookie, listURL string, count int) ([]types.Post, error) {
+	sel := reloadSelectors()
+	if !strings.HasPrefix(listURL, "http") {
+		listURL = sel.ListURLPrefix + listURL
+	}
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   "list " + listURL,
+		url:    listURL,
+		source: types.SourceList,
+		via:    strings.TrimPrefix(listURL, sel.ListURLPrefix),
+	})
+}
 
+// ScrapeCommunity fetches posts from an X Community the user is a member of.
+// communityURL may be a full community URL (https://x.com/i/communities/123)
+// or a bare community ID. The community's name is recorded on each post, or
+// its ID if the page doesn't show a name.
+func (s *Scraper) ScrapeCommunity(ctx context.Context, cookies []*network.Cookie, communityURL string, count int) ([]types.Post, error) {
+	sel := reloadSelectors()
+	if !strings.HasPrefix(communityURL, "http") {
+		communityURL = sel.CommunityURLPrefix + communityURL
+	}
+	id := strings.TrimPrefix(communityURL, sel.CommunityURLPrefix)
+	community := id
+	posts, err := s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   "community " + communityURL,
+		url:    communityURL,
+		source: types.SourceCommunity,
+		via:    id,
+		prepare: func(ctx context.Context) error {
+			var name string
+			nameJS := fmt.Sprintf(`document.querySelector(%q)?.innerText.trim() || ''`, sel.CommunityName)
+			if err := chromedp.Run(ctx, chromedp.Evaluate(nameJS, &name)); err != nil {
+				log.Printf("Failed to read community name: %v", err)
+			} else if name != "" {
+				community = name
+			}
+			return nil
+		},
+	})
+	for i := range posts {
+		posts[i].Community = community
+	}
+	return posts, err
+}
+
+// ScrapeProfile fetches posts from a user's profile timeline. handle may
+// include a leading "@".
+func (s *Scraper) ScrapeProfile(ctx context.Context, cookies []*network.Cookie, handle string, count int) ([]types.Post, error) {
+	handle = strings.TrimPrefix(handle, "@")
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   "profile @" + handle,
+		url:    reloadSelectors().ProfileURLPrefix + handle,
+		source: types.SourceProfile,
+		via:    "@" + handle,
+	})
+}
+
+// ScrapeSearch fetches posts from the Latest tab of an X search, e.g. a hashtag
+func (s *Scraper) ScrapeSearch(ctx context.Context, cookies []*network.Cookie, query string, count int) ([]types.Post, error) {
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   fmt.Sprintf("search %q", query),
+		url:    reloadSelectors().SearchURLPrefix + url.QueryEscape(query) + "&src=typed_query&f=live",
+		source: types.SourceSearch,
+		via:    query,
+	})
+}
+
+// ScrapeBookmarks fetches the logged-in user's bookmarked posts
+func (s *Scraper) ScrapeBookmarks(ctx context.Context, cookies []*network.Cookie, count int) ([]types.Post, error) {
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:           "bookmarks",
+		url:            reloadSelectors().BookmarksURL,
+		source:         types.SourceBookmarks,
+		skipCheckpoint: true,
+	})
+}
+
+// ScrapeMentions fetches posts mentioning or replying to the logged-in user
+func (s *Scraper) ScrapeMentions(ctx context.Context, cookies []*network.Cookie, count int) ([]types.Post, error) {
+	return s.scrape(ctx, cookies, count, scrapeTarget{
+		name:   "mentions",
+		url:    reloadSelectors().MentionsURL,
+		source: types.SourceMentions,
+	})
+}
+
+// ScrapeThread fetches the conversation page of a post and returns the
+// author's self-thread starting at that post, in order. The first element
+// is the post itself.
+func (s *Scraper) ScrapeThread(ctx context.Context, cookies []*network.Cookie, head types.Post) ([]types.Post, error) {
+	// A thread is read in full even if parts of it were seen before
+	posts, err := s.WithKnownPosts(nil, 0).scrape(ctx, cookies, threadMaxPosts, scrapeTarget{
+		name:           "thread " + head.OriginalURL,
+		url:            head.OriginalURL,
+		source:         head.Source,
+		via:            head.FetchedVia,
+		maxIdleScrolls: 2,
+		skipCheckpoint: true,
+		bestEffort:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// The page lists any ancestors, then the post, then replies - the
+	// author's continuation tweets come first among the replies
+	start := -1
+	for i, p := range posts {
+		if p.ID == head.ID {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("post %s not found on its own conversation page", head.ID)
+	}
+
+	thread := []types.Post{posts[start]}
+	for _, p := range posts[start+1:] {
+		if !strings.EqualFold(p.AuthorHandle, head.AuthorHandle) {
+			break
+		}
+		thread = append(thread, p)
+	}
+	return thread, nil
+}
+
+// replyParentMaxPosts caps how many posts are read from a reply's
+// conversation page when looking for its parent. The parent sits right
+// above the reply, so only the top of the page matters.
+const replyParentMaxPosts = 10
+
+// ScrapeReplyParent fetches the conversation page of a reply and returns
+// the post it replies to, which the page lists right above it.
+func (s *Scraper) ScrapeReplyParent(ctx context.Context, cookies []*network.Cookie, reply types.Post) (types.Post, error) {
+	posts, err := s.WithKnownPosts(nil, 0).scrape(ctx, cookies, replyParentMaxPosts, scrapeTarget{
+		name:           "parent of " + reply.OriginalURL,
+		url:            reply.OriginalURL,
+		source:         reply.Source,
+		via:            reply.FetchedVia,
+		maxIdleScrolls: 1,
+		skipCheckpoint: true,
+		bestEffort:     true,
+	})
+	if err != nil {
+		return types.Post{}, err
+	}
+
+	for i, p := range posts {
+		if p.ID == reply.ID {
+			if i == 0 {
+				return types.Post{}, fmt.Errorf("no post above reply %s on its conversation page", reply.ID)
+			}
+			return posts[i-1], nil
+		}
+	}
+	return types.Post{}, fmt.Errorf("post %s not found on its own conversation page", reply.ID)
+}
+
+// repliesMaxPosts caps how many posts are read from a conversation page
+// when gathering the replies to a post
+const repliesMaxPosts = 30
+
+// ScrapeReplies fetches the conversation page of a post and returns the
+// replies to it by other accounts, in the order X ranks them. The author's
+// own continuation posts are left out.
+func (s *Scraper) ScrapeReplies(ctx context.Context, cookies []*network.Cookie, post types.Post) ([]types.Post, error) {
+	posts, err := s.WithKnownPosts(nil, 0).scrape(ctx, cookies, repliesMaxPosts, scrapeTarget{
+		name:           "replies to " + post.OriginalURL,
+		url:            post.OriginalURL,
+		source:         post.Source,
+		via:            post.FetchedVia,
+		maxIdleScrolls: 2,
+		skipCheckpoint: true,
+		bestEffort:     true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	start := -1
+	for i, p := range posts {
+		if p.ID == post.ID {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return nil, fmt.Errorf("post %s not found on its own conversation page", post.ID)
+	}
+	var replies []types.Post
+	for _, p := range posts[start+1:] {
+		if !strings.EqualFold(p.AuthorHandle, post.AuthorHandle) {
+			replies = append(replies, p)
+		}
+	}
+	return replies, nil
+}
+
+// scrape launches a browser, loads the target page, and collects up to count
+// posts. With nil cookies it runs logged out (guest mode) in a fresh
+// profile, which only works for public pages such as lists and profiles.
+// Every launch goes through the rate limiter.
+func (s *Scraper) scrape(ctx context.Context, cookies []*network.Cookie, count int, target scrapeTarget) ([]types.Post, error) {
+	reloadSelectors()
+	if s.replay {
+		return s.replayScrape(ctx, count, target)
+	}
+	if err := s.limiter.Acquire(ctx, target.name); err != nil {
+		return nil, fmt.Errorf("not scraping %s: %w", target.name, err)
+	}
+	posts, err := s.runScrape(ctx, cookies, count, target)
+	if !target.bestEffort {
+		s.limiter.Report(err)
+	}
+	return posts, err
+}
+
+// openBrowser launches Chrome (or attaches to the remote browser) and
+// returns a tab context that expires after timeout, with the session's
+// cookies in place unless cookies is nil (guest mode). cancel closes the
+// browser.
+func (s *Scraper) openBrowser(ctx context.Context, cookies []*network.Cookie, timeout time.Duration) (context.Context, context.CancelFunc, error) {
+	guest := cookies == nil
+	profileDir := s.profileDir
+	if guest {
+		profileDir = "" // Don't let the saved session leak into a guest scrape
+	}
+
+	// Create browser context with anti-bot-detection options, or attach to
+	// the remote browser
+	var allocCtx context.Context
+	var allocCancel context.CancelFunc
+	if s.remoteURL != "" {
+		log.Printf("Connecting to remote browser at %s", s.remoteURL)
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(ctx, s.remoteURL)
+	} else {
+		allocCtx, allocCancel = chromedp.NewExecAllocator(ctx, browser.Options(s.headless, profileDir)...)
+	}
 	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
-	defer browserCancel()
+	timedBrowserCtx, timeoutCancel := context.WithTimeout(browserCtx, timeout)
+	cancel := func() {
+		timeoutCancel()
+		browserCancel()
+		allocCancel()
+	}
+
+	// Inject cookies before navigation, unless the persistent profile already
+	// carries the session (X rotates cookies server-side, so the profile's
+	// copies are fresher than ours)
+	if guest {
+		log.Println("Guest mode: scraping logged out")
+	} else if s.remoteURL != "" && s.remoteSession {
+		log.Println("Using the remote browser's own session")
+	} else if profileDir != "" {
+		log.Printf("Using persistent browser profile: %s", profileDir)
+	} else {
+		log.Printf("Injecting %d cookies...", len(cookies))
+		if err := s.injectCookies(timedBrowserCtx, cookies); err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("failed to inject cookies: %w", err)
+		}
+	}
 
-	// Set timeout for the entire scrape operation: 1 second per post, minimum 1 minute
-	timeout := time.Duration(count) * time.Second
-	if timeout < time.Minute {
-		timeout = time.Minute
+	if err := chromedp.Run(timedBrowserCtx, browser.InjectStealth()); err != nil {
+		cancel()
+		return nil, nil, fmt.Errorf("failed to inject stealth script: %w", err)
+	}
+	if s.mobile {
+		log.Println("Emulating a mobile browser")
+		if err := chromedp.Run(timedBrowserCtx, browser.EmulateMobile()); err != nil {
+			cancel()
+			return nil, nil, fmt.Errorf("failed to emulate mobile browser: %w", err)
+		}
 	}
+	return timedBrowserCtx, cancel, nil
+}
+
+// runScrape implements scrape once the rate limiter allows it
+func (s *Scraper) runScrape(ctx context.Context, cookies []*network.Cookie, count int, target scrapeTarget) ([]types.Post, error) {
+	log.Printf("Starting scrape of %s for %d posts (headless=%v, debugPauseAfterScrape=%v)", target.name, count, s.headless, s.debugPauseAfterScrape)
+
+	guest := cookies == nil
+
+	// Set timeout for the entire scrape operation: by default 1 second per
+	// post, minimum 1 minute
+	timeout := s.timeout(s.pacing.scrapeTime(count))
 	log.Printf("Scrape timeout: %v", timeout)
-	timedBrowserCtx, timeoutCancel := context.WithTimeout(browserCtx, timeout)
-	defer timeoutCancel()
+	timedBrowserCtx, cancel, err := s.openBrowser(ctx, cookies, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	// Decode GraphQL timeline responses as they arrive
+	gql := newGraphQLCollector()
+	chromedp.ListenTarget(timedBrowserCtx, gql.listener(timedBrowserCtx))
 
-	// Inject cookies before navigation
-	log.Printf("Injecting %d cookies...", len(cookies))
-	if err := s.injectCookies(timedBrowserCtx, cookies); err != nil {
-		return nil, fmt.Errorf("failed to inject cookies: %w", err)
+	// Navigate to the target page
+	log.Printf("Navigating to %s...", target.url)
+	if err := chromedp.Run(timedBrowserCtx, chromedp.Navigate(target.url)); err != nil {
+		if errors.Is(timedBrowserCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			err = fmt.Errorf("%w: %v", ErrScrapeTimeout, err)
+		}
+		return nil, fmt.Errorf("failed to load %s: %w", target.name, err)
+	}
+	if err := s.waitForTimeline(timedBrowserCtx, guest); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", target.name, err)
 	}
 
-	// Navigate to home feed
-	log.Printf("Navigating to x.com/home...")
-	if err := chromedp.Run(timedBrowserCtx,
-		chromedp.Navigate("https://x.com/home"),
-		chromedp.WaitVisible(WaitForTweets, chromedp.ByQuery),
-	); err != nil {
-		return nil, fmt.Errorf("failed to load feed: %w", err)
+	if target.prepare != nil {
+		if err := target.prepare(timedBrowserCtx); err != nil {
+			return nil, fmt.Errorf("failed to prepare %s: %w", target.name, err)
+		}
+	}
+	log.Printf("%s loaded, beginning extraction...", target.name)
+
+	// Save progress while scrolling so that a crash or kill doesn't lose
+	// it; the next ScrapePosts salvages whatever is left behind
+	var checkpoint func([]types.Post)
+	if !target.skipCheckpoint {
+		checkpoint = func(posts []types.Post) {
+			target.label(posts)
+			if err := store.SaveScrapeCheckpoint(target.name, posts); err != nil {
+				log.Printf("Failed to checkpoint %s: %v", target.name, err)
+			}
+		}
 	}
-	log.Printf("Feed loaded, beginning extraction...")
 
 	// Scrape posts with scrolling
-	posts, err := s.extractPosts(timedBrowserCtx, count)
+	posts, err := s.extractPosts(timedBrowserCtx, count, target, gql, checkpoint)
 	if s.debugPauseAfterScrape {
 		if s.headless {
 			log.Println("Skipping debug pause after scrape in headless mode")
@@ -158,10 +700,117 @@ func (s *Scraper) ScrapeForYou(ctx context.Context, cookies []*network.Cookie, c
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract posts: %w", err)
 	}
+	// Scrolling stops quietly when the scrape times out, but a cancelled run
+	// shouldn't carry on as if the scrape finished. The checkpoint is kept
+	// for the next run to salvage.
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("scrape of %s stopped: %w", target.name, err)
+	}
 
+	target.label(posts)
+	if !target.skipCheckpoint {
+		if err := store.ClearScrapeCheckpoint(target.name); err != nil {
+			log.Printf("Failed to clear checkpoint of %s: %v", target.name, err)
+		}
+	}
+
+	if errors.Is(timedBrowserCtx.Err(), context.DeadlineExceeded) && !target.bestEffort &&
+		s.timeoutFactor <= 1 && len(posts)*minTimedOutShare < count {
+		return posts, fmt.Errorf("%w: %s gave %d of %d posts", ErrTooFewPosts, target.name, len(posts), count)
+	}
 	return posts, nil
 }
 
+// waitForTimeline is the session health check run after navigation: it
+// waits until tweets render and returns a typed error if X shows a login
+// wall (ErrSessionInvalid), a verification challenge (ErrAccountChallenge),
+// or a locked account page (ErrAccountLocked) instead, or if nothing loads
+// within the pacing's PageLoadTimeout (scaled by the timeout factor).
+// Logged-out pages always show a login button, so in guest mode only a
+// redirect to the login flow counts.
+func (s *Scraper) waitForTimeline(ctx context.Context, guest bool) error {
+	sel := selectors()
+	loginForm := sel.LoginForm
+	if guest {
+		loginForm = ":not(*)" // Matches nothing
+	}
+	stateJS := fmt.Sprintf(`
+		(function() {
+			const path = location.pathname;
+			if (path.startsWith(%q)) return 'locked';
+			if (path.startsWith(%q)) return 'challenge';
+			if (document.querySelector(%q)) return 'tweets';
+			if (document.querySelector(%q) || path.startsWith(%q)) return 'login';
+			const text = document.body?.innerText || '';
+			if (%s.test(text)) return 'locked';
+			if (%s.test(text)) return 'challenge';
+			return '';
+		})()
+	`, sel.AccountAccessPath, sel.LoginChallengePath, sel.TweetArticle, loginForm, sel.LoginFlowPath,
+		sel.AccountLockedTextPattern, sel.ChallengeTextPattern)
+
+	checkTimeout := s.timeout(s.pacing.PageLoadTimeout)
+	checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	var state string
+	if err := chromedp.Run(checkCtx,
+		chromedp.Poll(stateJS, &state, chromedp.WithPollingInterval(500*time.Millisecond)),
+	); err != nil {
+		if checkCtx.Err() != nil && ctx.Err() == nil {
+			return fmt.Errorf("%w: timeline didn't load within %v", ErrScrapeTimeout, checkTimeout)
+		}
+		return err
+	}
+	switch state {
+	case "locked":
+		return ErrAccountLocked
+	case "challenge":
+		return ErrAccountChallenge
+	case "login":
+		if guest {
+			return ErrLoginRequired
+		}
+		return ErrSessionInvalid
+	}
+	return chromedp.Run(ctx, chromedp.WaitVisible(sel.TweetArticle, chromedp.ByQuery))
+}
+
+// selectTab clicks the tab with the given label and waits for it to become
+// selected and for tweets to render in the new timeline.
+func (s *Scraper) selectTab(ctx context.Context, label string) error {
+	log.Printf("Switching to %q tab...", label)
+	sel := selectors()
+
+	clickJS := fmt.Sprintf(`
+		(function() {
+			const tab = Array.from(document.querySelectorAll('%s'))
+				.find(t => t.textContent.trim() === %q);
+			if (!tab) return false;
+			tab.click();
+			return true;
+		})()
+	`, sel.TimelineTab, label)
+
+	var clicked bool
+	if err := chromedp.Run(ctx, chromedp.Evaluate(clickJS, &clicked)); err != nil {
+		return fmt.Errorf("failed to click %q tab: %w", label, err)
+	}
+	if !clicked {
+		return fmt.Errorf("%q tab not found", label)
+	}
+
+	selectedJS := fmt.Sprintf(`
+		Array.from(document.querySelectorAll('%s'))
+			.some(t => t.textContent.trim() === %q && t.getAttribute('aria-selected') === 'true')
+	`, sel.TimelineTab, label)
+
+	return chromedp.Run(ctx,
+		chromedp.Poll(selectedJS, nil, chromedp.WithPollingTimeout(10*time.Second)),
+		chromedp.WaitVisible(sel.TweetArticle, chromedp.ByQuery),
+	)
+}
+
 // injectCookies sets cookies in the browser context
 func (s *Scraper) injectCookies(ctx context.Context, cookies []*network.Cookie) error {
 	return chromedp.Run(ctx,
@@ -184,14 +833,56 @@ func (s *Scraper) injectCookies(ctx context.Context, cookies []*network.Cookie)
 	)
 }
 
-// extractPosts scrolls and extracts posts from the feed
-func (s *Scraper) extractPosts(ctx context.Context, count int) ([]types.Post, error) {
+// extractPosts scrolls and extracts posts from the feed. Posts decoded from
+// intercepted GraphQL responses are preferred, and the DOM fills in any
+// they miss; if none arrive, it falls back to parsing the DOM alone.
+func (s *Scraper) extractPosts(ctx context.Context, count int, target scrapeTarget, gql *graphqlCollector, checkpoint func([]types.Post)) ([]types.Post, error) {
+	stats := newSelectorStats(target.name, selectors().extractionChains(s.mobile))
+	defer stats.logSummary()
+
+	useGraphQL := gql.waitForPosts(ctx, graphqlWaitTimeout)
+	if useGraphQL {
+		log.Println("Extracting posts from intercepted GraphQL responses, and from the DOM where they miss any")
+	} else {
+		log.Println("No GraphQL timeline responses intercepted - falling back to DOM extraction")
+	}
+
+	var snapshot *snapshotCollector
+	if s.saveHTML {
+		snapshot = newSnapshotCollector(target, s.mobile)
+		defer snapshot.save()
+	}
+
+	maxIdleScrolls := target.maxIdleScrolls
+	if maxIdleScrolls == 0 {
+		maxIdleScrolls = s.pacing.MaxIdleScrolls
+	}
+
 	posts, err := s.scrollAndCollect(ctx, scrollAndCollectParams{
-		maxCount:         count,
-		extractor:        s.extractVisiblePosts,
+		maxCount:       count,
+		maxIdleScrolls: maxIdleScrolls,
+		knownIDs:       s.knownIDs,
+		stopAfterKnown: s.stopAfterKnown,
+		checkpoint:     checkpoint,
+		extractor: func(ctx context.Context) ([]types.Post, error) {
+			posts, err := s.extractVisiblePosts(ctx, stats)
+			if useGraphQL {
+				if err != nil && ctx.Err() == nil {
+					log.Printf("DOM extraction failed, using GraphQL posts alone: %v", err)
+					err = nil
+				}
+				posts = mergeDOMPosts(gql.Posts(), posts)
+			}
+			if snapshot != nil && err == nil {
+				if err := snapshot.capture(ctx, stats.chains["tweet"]); err != nil {
+					log.Printf("Failed to capture HTML snapshot: %v", err)
+				}
+			}
+			return posts, err
+		},
 		logPrefix:        "Scroll",
-		baseDelayMs:      500,
-		delayJitterMaxMs: 300,
+		baseDelayMs:      int(s.pacing.ScrollDelay.Milliseconds()),
+		delayJitterMaxMs: int(s.pacing.ScrollJitter.Milliseconds()),
 	})
 	if err != nil {
 		return nil, err
@@ -203,19 +894,43 @@ func (s *Scraper) extractPosts(ctx context.Context, count int) ([]types.Post, er
 
 // rawPost represents the raw data extracted from the DOM via JavaScript
 type rawPost struct {
-	ID           string   `json:"id"`
-	AuthorHandle string   `json:"authorHandle"`
-	AuthorName   string   `json:"authorName"`
-	Content      string   `json:"content"`
-	MediaURLs    []string `json:"mediaUrls"`
-	Timestamp    string   `json:"timestamp"`
-	Likes        string   `json:"likes"`
-	Retweets     string   `json:"retweets"`
-	Replies      string   `json:"replies"`
-	IsRetweet    bool     `json:"isRetweet"`
-	IsQuoteTweet bool     `json:"isQuoteTweet"`
-	IsReply      bool     `json:"isReply"`
-	OriginalURL  string   `json:"originalUrl"`
+	ID             string          `json:"id"`
+	AuthorHandle   string          `json:"authorHandle"`
+	AuthorName     string          `json:"authorName"`
+	AuthorVerified bool            `json:"authorVerified"`
+	Content        string          `json:"content"`
+	Lang           string          `json:"lang"`
+	MediaURLs      []string        `json:"mediaUrls"`
+	MediaAltText   []string        `json:"mediaAltText"`
+	Timestamp      string          `json:"timestamp"`
+	Likes          string          `json:"likes"`
+	Retweets       string          `json:"retweets"`
+	Replies        string          `json:"replies"`
+	Views          string          `json:"views"`
+	IsRetweet      bool            `json:"isRetweet"`
+	RetweetedBy    string          `json:"retweetedBy"`
+	IsPromoted     bool            `json:"isPromoted"`
+	IsQuoteTweet   bool            `json:"isQuoteTweet"`
+	Quoted         *rawQuoted      `json:"quoted"`
+	Poll           *rawPoll        `json:"poll"`
+	Card           *types.LinkCard `json:"card"`
+	IsReply        bool            `json:"isReply"`
+	OriginalURL    string          `json:"originalUrl"`
+}
+
+// rawQuoted represents the quoted post embedded in a quote tweet
+type rawQuoted struct {
+	AuthorHandle string `json:"authorHandle"`
+	AuthorName   string `json:"authorName"`
+	Content      string `json:"content"`
+	URL          string `json:"url"`
+}
+
+// rawPoll represents a poll card extracted from the DOM
+type rawPoll struct {
+	Choices    []types.PollChoice `json:"choices"`
+	TotalVotes string             `json:"totalVotes"`
+	Closed     bool               `json:"closed"`
 }
 
 // expandTruncatedTweets clicks "Show more" buttons on visible tweets to reveal full content.
@@ -223,7 +938,8 @@ type rawPost struct {
 func (s *Scraper) expandTruncatedTweets(ctx context.Context) error {
 	// Find all "Show more" buttons currently visible
 	var buttonCount int
-	countJS := fmt.Sprintf(`document.querySelectorAll('%s').length`, TweetShowMore)
+	showMore := selectors().TweetShowMore
+	countJS := fmt.Sprintf(`document.querySelectorAll('%s').length`, showMore)
 
 	if err := chromedp.Run(ctx, chromedp.Evaluate(countJS, &buttonCount)); err != nil {
 		return fmt.Errorf("failed to count show more buttons: %w", err)
@@ -247,7 +963,7 @@ func (s *Scraper) expandTruncatedTweets(ctx context.Context) error {
 				}
 				return false;
 			})()
-		`, TweetShowMore)
+		`, showMore)
 
 		var clicked bool
 		if err := chromedp.Run(ctx, chromedp.Evaluate(clickJS, &clicked)); err != nil {
@@ -261,39 +977,70 @@ func (s *Scraper) expandTruncatedTweets(ctx context.Context) error {
 
 		// Variable delay: 250ms to 500ms
 		delay := time.Duration(250+rand.Intn(250)) * time.Millisecond
-		time.Sleep(delay)
+		if err := sleep(ctx, delay); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// extractVisiblePosts parses currently visible tweets
-func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error) {
+// extractVisiblePosts parses currently visible tweets, recording selector
+// chain usage in stats
+func (s *Scraper) extractVisiblePosts(ctx context.Context, stats *selectorStats) ([]types.Post, error) {
 	// First, expand any truncated tweets to get full content
 	if err := s.expandTruncatedTweets(ctx); err != nil {
 		log.Printf("Warning: failed to expand truncated tweets: %v", err)
 		// Continue anyway - we'll get partial content
 	}
 
-	var rawPosts []rawPost
+	chains, err := json.Marshal(stats.chains)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode selectors: %w", err)
+	}
+
+	var result struct {
+		Posts []rawPost        `json:"posts"`
+		Stats map[string][]int `json:"stats"`
+	}
 
 	// JavaScript to extract tweet data from the DOM
-	extractJS := `
+	extractJS := fmt.Sprintf(`
 		(function() {
-			const tweets = document.querySelectorAll('article[data-testid="tweet"]');
+			const chains = %s;
+			const stats = {};
+
+			// q tries each selector in the named chain and returns the first match
+			// (or all matches of the first selector that matches anything, if all=true).
+			const q = (root, key, all) => {
+				const chain = chains[key];
+				const counts = stats[key] || (stats[key] = new Array(chain.length + 1).fill(0));
+				for (let i = 0; i < chain.length; i++) {
+					const found = all ? root.querySelectorAll(chain[i]) : root.querySelector(chain[i]);
+					if (all ? found.length > 0 : found) {
+						counts[i]++;
+						return found;
+					}
+				}
+				counts[chain.length]++;
+				return all ? [] : null;
+			};
+
+			const tweets = q(document, 'tweet', true);
 			const results = [];
 
 			tweets.forEach(el => {
 				try {
 					// Extract tweet ID from status link
-					const statusLink = el.querySelector('a[href*="/status/"]');
+					const statusLink = q(el, 'statusLink');
 					const id = statusLink?.href?.match(/status\/(\d+)/)?.[1];
 					if (!id) return; // Skip if no ID found
 
 					// Extract author info from User-Name element
-					const userNameEl = el.querySelector('[data-testid="User-Name"]');
+					const userNameEl = q(el, 'author');
 					let authorHandle = '';
 					let authorName = '';
+					let authorVerified = false;
 					if (userNameEl) {
 						// The handle is in a link, display name is usually the first text
 						const handleLink = userNameEl.querySelector('a[href^="/"]');
@@ -303,27 +1050,96 @@ func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error)
 						// Get display name from the first span with text
 						const nameSpan = userNameEl.querySelector('span');
 						authorName = nameSpan?.textContent || '';
+						// Verification badge (blue, gold, or grey check)
+						authorVerified = q(userNameEl, 'verified') !== null;
+					}
+
+					// Check if it's a quote tweet, and extract the quoted post
+					const quoteEl = q(el, 'quoteTweet');
+					const isQuoteTweet = quoteEl !== null;
+					let quoted = null;
+					if (quoteEl) {
+						const quoteAuthorEl = q(quoteEl, 'author');
+						const spans = Array.from(quoteAuthorEl?.querySelectorAll('span') || []);
+						const handleSpan = spans.find(sp => sp.textContent.trim().startsWith('@'));
+						quoted = {
+							authorHandle: handleSpan?.textContent.trim().replace('@', '') || '',
+							authorName: spans[0]?.textContent || '',
+							content: q(quoteEl, 'text')?.textContent || '',
+							url: quoteEl.querySelector('a[href*="/status/"]')?.href || ''
+						};
 					}
 
-					// Extract tweet text
-					const tweetTextEl = el.querySelector('[data-testid="tweetText"]');
+					// Extract poll, if any. Parsed from the card's visible text: option labels,
+					// each followed by its percentage once results are shown, then a
+					// footer like "1,234 votes · Final results" or "... · 2 days left".
+					let poll = null;
+					const pollEl = q(el, 'poll');
+					if (pollEl) {
+						const lines = pollEl.innerText.split('\n').map(l => l.trim()).filter(Boolean);
+						const footerIdx = lines.findIndex(l => /\bvotes?\b/i.test(l));
+						const optionLines = footerIdx >= 0 ? lines.slice(0, footerIdx) : lines;
+						const footer = footerIdx >= 0 ? lines[footerIdx] : '';
+						const choices = [];
+						for (let i = 0; i < optionLines.length; i++) {
+							const pct = optionLines[i + 1]?.match(/^([\d.]+)%%$/);
+							choices.push({label: optionLines[i], percent: pct ? parseFloat(pct[1]) : 0});
+							if (pct) i++;
+						}
+						poll = {
+							choices,
+							totalVotes: footer.match(/^([\d,.]+[KkMm]?)/)?.[1] || '0',
+							closed: /final results/i.test(footer)
+						};
+					}
+
+					// Extract link preview card, if any. Small cards list domain, title,
+					// and description as separate spans; large cards carry
+					// "domain title" in the link's aria-label.
+					let card = null;
+					const cardEl = q(el, 'card');
+					if (cardEl && !pollEl) {
+						const link = cardEl.querySelector('a[href]');
+						const texts = [...new Set(Array.from(cardEl.querySelectorAll('[data-testid$=".detail"] span'))
+							.map(sp => sp.textContent.trim()).filter(Boolean))];
+						card = {
+							url: link?.href || '',
+							domain: texts[0] || '',
+							title: texts[1] || link?.getAttribute('aria-label') || '',
+							description: texts[2] || ''
+						};
+					}
+
+					// Extract tweet text (ignoring the quoted post's text if the post itself has none)
+					let tweetTextEl = q(el, 'text');
+					if (tweetTextEl && quoteEl && quoteEl.contains(tweetTextEl)) tweetTextEl = null;
 					const content = tweetTextEl?.textContent || '';
+					const lang = tweetTextEl?.getAttribute('lang') || '';
 
 					// Extract media URLs
 					const mediaUrls = [];
-					const mediaEls = el.querySelectorAll('[data-testid="tweetPhoto"] img, [data-testid="videoPlayer"] video');
-					mediaEls.forEach(m => {
+					q(el, 'media', true).forEach(m => {
 						const src = m.src || m.poster;
 						if (src) mediaUrls.push(src);
 					});
 
+					// Extract image alt text, leaving out the quoted post's images.
+					// X fills in a generic "Image" when the author didn't write a
+					// description, so skip those.
+					const mediaAltText = [];
+					q(el, 'altText', true).forEach(img => {
+						if (quoteEl && quoteEl.contains(img)) return;
+						const alt = (img.getAttribute('alt') || '').trim();
+						if (alt && alt !== 'Image') mediaAltText.push(alt);
+					});
+
 					// Extract timestamp
-					const timeEl = el.querySelector('time');
+					const timeEl = q(el, 'time');
 					const timestamp = timeEl?.getAttribute('datetime') || '';
 
 					// Extract engagement metrics (these are displayed as aria-label or text)
-					const getMetric = (testId) => {
-						const metricEl = el.querySelector('[data-testid="' + testId + '"]');
+					const getMetric = (key) => {
+						const metricEl = q(el, key);
 						if (!metricEl) return '0';
 						// Try aria-label first (e.g., "123 Replies")
 						const ariaLabel = metricEl.getAttribute('aria-label');
@@ -339,14 +1155,23 @@ func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error)
 					const replies = getMetric('reply');
 					const retweets = getMetric('retweet');
 					const likes = getMetric('like');
+					const views = getMetric('views');
+
+					// Check if it's an ad: inside a placement container, or without a
+					// timestamp and labeled "Ad"/"Promoted"
+					const isPromoted = chains.promoted.some(sel => el.closest(sel)) ||
+						(!timeEl && Array.from(el.querySelectorAll('span'))
+							.some(sp => /^(Ad|Promoted)$/.test(sp.textContent.trim())));
 
 					// Check if it's a retweet (has social context indicating repost)
-					const socialContext = el.querySelector('[data-testid="socialContext"]');
+					const socialContext = q(el, 'socialContext');
 					const isRetweet = socialContext?.textContent?.toLowerCase().includes('repost') ||
 					                  socialContext?.textContent?.toLowerCase().includes('retweeted') || false;
-
-					// Check if it's a quote tweet
-					const isQuoteTweet = el.querySelector('[data-testid="quoteTweet"]') !== null;
+					// The social context links to the reposting account's profile
+					const retweetedBy = isRetweet
+						? (socialContext.closest('a[href]') || socialContext.querySelector('a[href]'))
+							?.getAttribute('href')?.match(/^\/(\w+)$/)?.[1] || ''
+						: '';
 
 					// Check if it's a reply (has "Replying to" text)
 					const isReply = el.textContent?.includes('Replying to') || false;
@@ -358,14 +1183,23 @@ func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error)
 						id,
 						authorHandle,
 						authorName,
+						authorVerified,
 						content,
+						lang,
 						mediaUrls,
+						mediaAltText,
 						timestamp,
 						likes,
 						retweets,
 						replies,
+						views,
 						isRetweet,
+						retweetedBy,
+						isPromoted,
 						isQuoteTweet,
+						quoted,
+						poll,
+						card,
 						isReply,
 						originalUrl
 					});
@@ -374,16 +1208,18 @@ func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error)
 				}
 			});
 
-			return results;
+			return {posts: results, stats};
 		})()
-	`
+	`, chains)
 
-	err := chromedp.Run(ctx,
-		chromedp.Evaluate(extractJS, &rawPosts),
+	err = chromedp.Run(ctx,
+		chromedp.Evaluate(extractJS, &result),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to extract posts from DOM: %w", err)
 	}
+	stats.record(result.Stats)
+	rawPosts := result.Posts
 
 	// Convert raw posts to types.Post
 	posts := make([]types.Post, 0, len(rawPosts))
@@ -403,21 +1239,49 @@ func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error)
 		}
 
 		post := types.Post{
-			ID:           rp.ID,
-			AuthorHandle: rp.AuthorHandle,
-			AuthorName:   rp.AuthorName,
-			Content:      rp.Content,
-			MediaURLs:    rp.MediaURLs,
-			Timestamp:    timestamp,
-			Likes:        parseMetric(rp.Likes),
-			Retweets:     parseMetric(rp.Retweets),
-			Replies:      parseMetric(rp.Replies),
-			QuoteTweets:  0, // Not easily available from the DOM
-			IsRetweet:    rp.IsRetweet,
-			IsQuoteTweet: rp.IsQuoteTweet,
-			IsReply:      rp.IsReply,
-			OriginalURL:  rp.OriginalURL,
-			ScrapedAt:    now,
+			ID:             rp.ID,
+			AuthorHandle:   rp.AuthorHandle,
+			AuthorName:     rp.AuthorName,
+			AuthorVerified: rp.AuthorVerified,
+			Content:        rp.Content,
+			Lang:           rp.Lang,
+			MediaURLs:      rp.MediaURLs,
+			MediaAltText:   rp.MediaAltText,
+			Timestamp:      timestamp,
+			Likes:          parseMetric(rp.Likes),
+			Retweets:       parseMetric(rp.Retweets),
+			Replies:        parseMetric(rp.Replies),
+			QuoteTweets:    0, // Timeline cards don't show quote counts; only GraphQL responses have them
+			Views:          parseMetric(rp.Views),
+			IsRetweet:      rp.IsRetweet,
+			IsPromoted:     rp.IsPromoted,
+			IsQuoteTweet:   rp.IsQuoteTweet,
+			IsReply:        rp.IsReply,
+			OriginalURL:    rp.OriginalURL,
+			ScrapedAt:      now,
+		}
+		if rp.Poll != nil {
+			post.Poll = &types.Poll{
+				Choices:    rp.Poll.Choices,
+				TotalVotes: parseMetric(rp.Poll.TotalVotes),
+				Closed:     rp.Poll.Closed,
+			}
+		}
+		if rp.Card != nil && rp.Card.URL != "" {
+			post.Card = rp.Card
+		}
+		if rp.RetweetedBy != "" {
+			post.RetweetedBy = []string{rp.RetweetedBy}
+		}
+		if rp.Quoted != nil {
+			post.QuotedPost = &types.Post{
+				ID:           statusID(rp.Quoted.URL),
+				AuthorHandle: rp.Quoted.AuthorHandle,
+				AuthorName:   rp.Quoted.AuthorName,
+				Content:      rp.Quoted.Content,
+				OriginalURL:  rp.Quoted.URL,
+				ScrapedAt:    now,
+			}
 		}
 		posts = append(posts, post)
 	}
@@ -425,11 +1289,15 @@ func (s *Scraper) extractVisiblePosts(ctx context.Context) ([]types.Post, error)
 	return posts, nil
 }
 
-// scroll scrolls the page down
-func (s *Scraper) scroll(ctx context.Context) error {
-	return chromedp.Run(ctx,
-		chromedp.Evaluate(`window.scrollBy(0, window.innerHeight * 2)`, nil),
-	)
+// statusIDPattern matches the post ID in a status URL
+var statusIDPattern = regexp.MustCompile(`/status/(\d+)`)
+
+// statusID extracts the post ID from a status URL, or returns "" if there is none
+func statusID(url string) string {
+	if m := statusIDPattern.FindStringSubmatch(url); m != nil {
+		return m[1]
+	}
+	return ""
 }
 
 // parseMetric converts abbreviated metric strings like "1.2K", "5.7M", or "423" to integers
@@ -0,0 +1,180 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/chromedp/cdproto/input"
+	"github.com/chromedp/chromedp"
+)
+
+// StealthLevel controls how human-like scrolling is
+type StealthLevel string
+
+const (
+	// StealthOff jumps two viewports per scroll with window.scrollBy
+	StealthOff StealthLevel = "off"
+	// StealthLow scrolls with mouse-wheel events of varying size
+	StealthLow StealthLevel = "low"
+	// StealthHigh also moves the mouse around, occasionally scrolls back
+	// up, and pauses longer
+	StealthHigh StealthLevel = "high"
+)
+
+// Wheel scrolling parameters
+const (
+	wheelTickMin    = 80  // Smallest single wheel delta, in pixels
+	wheelTickMax    = 220 // Largest single wheel delta
+	wheelTickGapMin = 15 * time.Millisecond
+	wheelTickGapMax = 70 * time.Millisecond
+
+	// Chance per scroll (StealthHigh) of glancing back up before continuing
+	scrollBackChance = 0.15
+	// Extra random pause between scrolls at StealthHigh
+	highStealthExtraDelayMaxMs = 2000
+)
+
+// scroll advances the timeline to load more posts, in the style set by the
+// stealth level
+func (s *Scraper) scroll(ctx context.Context) error {
+	switch s.stealth {
+	case StealthOff:
+		return chromedp.Run(ctx,
+			chromedp.Evaluate(`window.scrollBy(0, window.innerHeight * 2)`, nil),
+		)
+	case StealthHigh:
+		return s.wheelScroll(ctx, true)
+	default:
+		return s.wheelScroll(ctx, false)
+	}
+}
+
+// wheelScroll scrolls roughly 1.2-2.2 viewports using a burst of wheel
+// events from a randomized pointer position, like a person flicking a
+// mouse wheel or trackpad. If fidget is true, the pointer also wanders,
+// and some scrolls start with a short scroll back up.
+func (s *Scraper) wheelScroll(ctx context.Context, fidget bool) error {
+	var viewport struct {
+		Width  float64 `json:"w"`
+		Height float64 `json:"h"`
+	}
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`({w: window.innerWidth, h: window.innerHeight})`, &viewport)); err != nil {
+		return fmt.Errorf("failed to read viewport size: %w", err)
+	}
+
+	// Keep the pointer over the middle of the page, where the timeline is
+	x := viewport.Width * (0.3 + 0.4*rand.Float64())
+	y := viewport.Height * (0.3 + 0.4*rand.Float64())
+
+	if fidget {
+		var err error
+		if x, y, err = moveMouse(ctx, x, y, viewport.Width, viewport.Height); err != nil {
+			return err
+		}
+		if rand.Float64() < scrollBackChance {
+			if err := wheel(ctx, x, y, -viewport.Height*(0.2+0.4*rand.Float64())); err != nil {
+				return err
+			}
+			if err := sleepRandom(ctx, 400*time.Millisecond, 1200*time.Millisecond); err != nil {
+				return err
+			}
+		}
+	}
+
+	return wheel(ctx, x, y, viewport.Height*(1.2+rand.Float64()))
+}
+
+// wheel dispatches wheel ticks at (x, y) until distance pixels have been
+// scrolled (negative scrolls up)
+func wheel(ctx context.Context, x, y, distance float64) error {
+	direction := 1.0
+	if distance < 0 {
+		direction, distance = -1, -distance
+	}
+
+	for distance > 0 {
+		tick := float64(wheelTickMin + rand.Intn(wheelTickMax-wheelTickMin))
+		tick = min(tick, distance)
+		distance -= tick
+
+		err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return input.DispatchMouseEvent(input.MouseWheel, x, y).
+				WithDeltaX(0).
+				WithDeltaY(direction * tick).
+				Do(ctx)
+		}))
+		if err != nil {
+			return fmt.Errorf("failed to dispatch wheel event: %w", err)
+		}
+		if err := sleepRandom(ctx, wheelTickGapMin, wheelTickGapMax); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// moveMouse drifts the pointer from (x, y) to a random nearby point in a
+// few steps and returns where it ended up
+func moveMouse(ctx context.Context, x, y, width, height float64) (float64, float64, error) {
+	toX := clamp(x+(rand.Float64()-0.5)*width*0.3, width*0.1, width*0.9)
+	toY := clamp(y+(rand.Float64()-0.5)*height*0.3, height*0.1, height*0.9)
+
+	steps := 5 + rand.Intn(10)
+	for i := 1; i <= steps; i++ {
+		frac := float64(i) / float64(steps)
+		px := x + (toX-x)*frac + (rand.Float64()-0.5)*4
+		py := y + (toY-y)*frac + (rand.Float64()-0.5)*4
+
+		err := chromedp.Run(ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+			return input.DispatchMouseEvent(input.MouseMoved, px, py).Do(ctx)
+		}))
+		if err != nil {
+			return x, y, fmt.Errorf("failed to dispatch mouse move: %w", err)
+		}
+		if err := sleepRandom(ctx, 10*time.Millisecond, 40*time.Millisecond); err != nil {
+			return x, y, err
+		}
+	}
+	return toX, toY, nil
+}
+
+// parseStealthLevel validates a configured stealth level, defaulting to
+// StealthLow
+func parseStealthLevel(level string) StealthLevel {
+	switch l := StealthLevel(level); l {
+	case StealthOff, StealthLow, StealthHigh:
+		return l
+	case "":
+		return StealthLow
+	default:
+		log.Printf("Unknown stealth level %q (use %q, %q, or %q) - using %q",
+			level, StealthOff, StealthLow, StealthHigh, StealthLow)
+		return StealthLow
+	}
+}
+
+// sleep pauses for d, or returns ctx's error as soon as ctx is done, so a
+// cancelled scrape closes its browser without finishing the pause
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// sleepRandom sleeps for a random duration in [lo, hi), like sleep
+func sleepRandom(ctx context.Context, lo, hi time.Duration) error {
+	return sleep(ctx, lo+time.Duration(rand.Int63n(int64(hi-lo))))
+}
+
+// clamp limits v to [lo, hi]
+func clamp(v, lo, hi float64) float64 {
+	return max(lo, min(v, hi))
+}
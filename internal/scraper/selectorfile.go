@@ -0,0 +1,237 @@
+package scraper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// selectorsFileName is the optional selector overrides file, kept next to
+// config.toml
+const selectorsFileName = "selectors.toml"
+
+// Selectors holds the URLs, DOM selectors, and page indicators the scraper
+// uses at runtime. The compiled-in defaults are the constants in
+// selectors.go; any key set in selectors.toml replaces its default, so a DOM
+// change on X can be fixed by editing that file.
+type Selectors struct {
+	HomeURL             string `toml:"home_url"`
+	ListURLPrefix       string `toml:"list_url_prefix"`
+	BookmarksURL        string `toml:"bookmarks_url"`
+	MentionsURL         string `toml:"mentions_url"`
+	ProfileURLPrefix    string `toml:"profile_url_prefix"`
+	SearchURLPrefix     string `toml:"search_url_prefix"`
+	CommunityURLPrefix  string `toml:"community_url_prefix"`
+	TrendingURL         string `toml:"trending_url"`
+	MutedAccountsURL    string `toml:"muted_accounts_url"`
+	BlockedAccountsURL  string `toml:"blocked_accounts_url"`
+	GraphQLPathFragment string `toml:"graphql_path_fragment"`
+
+	TweetArticle      string `toml:"tweet_article"` // Waited for to know the timeline has rendered
+	TimelineTab       string `toml:"timeline_tab"`
+	FollowingTabLabel string `toml:"following_tab_label"`
+	TweetShowMore     string `toml:"tweet_show_more"`
+	CommunityName     string `toml:"community_name"`
+	TrendCell         string `toml:"trend_cell"`
+	UserCell          string `toml:"user_cell"`
+	ProfileUserName   string `toml:"profile_user_name"`
+	ProfileBio        string `toml:"profile_bio"`
+	ProfileFollowers  string `toml:"profile_followers"`
+
+	LoginForm                string `toml:"login_form"`
+	LoginFlowPath            string `toml:"login_flow_path"`
+	AccountAccessPath        string `toml:"account_access_path"`
+	LoginChallengePath       string `toml:"login_challenge_path"`
+	AccountLockedTextPattern string `toml:"account_locked_text_pattern"` // JS regex literal
+	ChallengeTextPattern     string `toml:"challenge_text_pattern"`      // JS regex literal
+
+	// Selector chains for DOM extraction, see ExtractionSelectors. A chain
+	// set here replaces the default chain for that key.
+	Extraction map[string]SelectorChain `toml:"extraction"`
+	// Chains that replace their Extraction chain when scraping with mobile
+	// emulation, see MobileExtractionSelectors
+	MobileExtraction map[string]SelectorChain `toml:"mobile_extraction"`
+}
+
+// DefaultSelectors returns the compiled-in selectors
+func DefaultSelectors() *Selectors {
+	return &Selectors{
+		HomeURL:             HomeURL,
+		ListURLPrefix:       ListURLPrefix,
+		BookmarksURL:        BookmarksURL,
+		MentionsURL:         MentionsURL,
+		ProfileURLPrefix:    ProfileURLPrefix,
+		SearchURLPrefix:     SearchURLPrefix,
+		CommunityURLPrefix:  CommunityURLPrefix,
+		TrendingURL:         TrendingURL,
+		MutedAccountsURL:    MutedAccountsURL,
+		BlockedAccountsURL:  BlockedAccountsURL,
+		GraphQLPathFragment: GraphQLPathFragment,
+
+		TweetArticle:      WaitForTweets,
+		TimelineTab:       TimelineTab,
+		FollowingTabLabel: FollowingTabLabel,
+		TweetShowMore:     TweetShowMore,
+		CommunityName:     CommunityName,
+		TrendCell:         TrendCell,
+		UserCell:          UserCell,
+		ProfileUserName:   ProfileUserName,
+		ProfileBio:        ProfileBio,
+		ProfileFollowers:  ProfileFollowers,
+
+		LoginForm:                LoginForm,
+		LoginFlowPath:            LoginFlowPath,
+		AccountAccessPath:        AccountAccessPath,
+		LoginChallengePath:       LoginChallengePath,
+		AccountLockedTextPattern: AccountLockedTextPattern,
+		ChallengeTextPattern:     ChallengeTextPattern,
+
+		Extraction:       maps.Clone(ExtractionSelectors),
+		MobileExtraction: maps.Clone(MobileExtractionSelectors),
+	}
+}
+
+// SelectorsPath returns the path of the selector overrides file
+func SelectorsPath() (string, error) {
+	dir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, selectorsFileName), nil
+}
+
+// LoadSelectors reads selector overrides from path on top of the defaults.
+// A missing file yields the defaults.
+func LoadSelectors(path string) (*Selectors, error) {
+	sel := DefaultSelectors()
+	md, err := toml.DecodeFile(path, sel)
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultSelectors(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return nil, fmt.Errorf("unknown keys: %v", undecoded)
+	}
+	for key, chain := range sel.Extraction {
+		if len(chain) == 0 {
+			return nil, fmt.Errorf("extraction.%s: empty selector chain", key)
+		}
+	}
+	for key, chain := range sel.MobileExtraction {
+		if len(chain) == 0 {
+			return nil, fmt.Errorf("mobile_extraction.%s: empty selector chain", key)
+		}
+	}
+	return sel, nil
+}
+
+// extractionChains returns the extraction selector chains to use: the
+// mobile chains in place of their defaults if mobile is set
+func (sel *Selectors) extractionChains(mobile bool) map[string]SelectorChain {
+	if !mobile {
+		return sel.Extraction
+	}
+	chains := maps.Clone(sel.Extraction)
+	maps.Copy(chains, sel.MobileExtraction)
+	return chains
+}
+
+// WriteDefaultSelectors writes the compiled-in selectors to path as a
+// template for overrides, every line commented out. Only the keys a user
+// uncomments override anything, so the rest keep up with later releases.
+func WriteDefaultSelectors(path string) error {
+	var defaults bytes.Buffer
+	if err := toml.NewEncoder(&defaults).Encode(DefaultSelectors()); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# scroll4me scraper selector overrides. Everything below is commented\n")
+	buf.WriteString("# out and shows the built-in defaults, which newer releases update as X\n")
+	buf.WriteString("# changes its pages. When a selector breaks, uncomment just its key (and\n")
+	buf.WriteString("# its [table] header, for extraction chains) and edit it; changes apply\n")
+	buf.WriteString("# from the next scrape. Keys left commented out follow the built-in\n")
+	buf.WriteString("# defaults.\n\n")
+	for line := range strings.Lines(defaults.String()) {
+		if strings.TrimSpace(line) == "" {
+			buf.WriteString(line)
+		} else {
+			buf.WriteString("# " + line)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// active holds the selectors in use; reloadSelectors swaps in new ones
+var active atomic.Pointer[Selectors]
+
+// selectorsFile tracks the overrides file so it's only re-read when it changes
+var selectorsFile struct {
+	mu      sync.Mutex
+	modTime time.Time
+	exists  bool
+}
+
+func init() {
+	active.Store(DefaultSelectors())
+}
+
+// selectors returns the selectors in use
+func selectors() *Selectors {
+	return active.Load()
+}
+
+// reloadSelectors re-reads selectors.toml if it changed since the last
+// call, and returns the selectors in use. A file that fails to load is
+// logged and the selectors in use are kept.
+func reloadSelectors() *Selectors {
+	path, err := SelectorsPath()
+	if err != nil {
+		log.Printf("Failed to locate %s: %v", selectorsFileName, err)
+		return selectors()
+	}
+
+	selectorsFile.mu.Lock()
+	defer selectorsFile.mu.Unlock()
+
+	info, err := os.Stat(path)
+	exists := err == nil
+	if !exists && !selectorsFile.exists {
+		return selectors()
+	}
+	if exists && selectorsFile.exists && info.ModTime().Equal(selectorsFile.modTime) {
+		return selectors()
+	}
+
+	sel, err := LoadSelectors(path)
+	if err != nil {
+		log.Printf("Ignoring %s, keeping the selectors in use: %v", path, err)
+		return selectors()
+	}
+	selectorsFile.exists = exists
+	if exists {
+		selectorsFile.modTime = info.ModTime()
+		log.Printf("Loaded selectors from %s", path)
+	} else {
+		log.Printf("%s removed, using built-in selectors", path)
+	}
+	active.Store(sel)
+	return sel
+}
@@ -2,17 +2,60 @@ package scraper
 
 // X.com DOM selectors
 // These are isolated here because X changes their DOM frequently
-// Update these when scraping breaks
+// They're the built-in defaults: when scraping breaks, override them in
+// selectors.toml (see Selectors) and update them here for the next release
 
 const (
+	// Page URLs
+	HomeURL       = "https://x.com/home"
+	ListURLPrefix = "https://x.com/i/lists/"
+	BookmarksURL  = "https://x.com/i/bookmarks"
+	MentionsURL   = "https://x.com/notifications/mentions"
+	// A handle is appended to get the profile timeline
+	ProfileURLPrefix = "https://x.com/"
+	// Query parameters are appended; f=live selects the Latest tab
+	SearchURLPrefix = "https://x.com/search?q="
+	// A community ID is appended to get its timeline
+	CommunityURLPrefix = "https://x.com/i/communities/"
+	TrendingURL        = "https://x.com/explore/tabs/trending"
+	// Account lists in the settings, of the logged-in user
+	MutedAccountsURL   = "https://x.com/settings/muted/all"
+	BlockedAccountsURL = "https://x.com/settings/blocked/all"
+
+	// API responses containing timeline data are served from this path
+	GraphQLPathFragment = "/i/api/graphql/"
+
 	// Feed selectors
 	FeedContainer = `[data-testid="primaryColumn"]`
 	TweetArticle  = `article[data-testid="tweet"]`
+	TimelineTab   = `[role="tablist"] [role="tab"]`
+	// Wraps each entry of a virtualized timeline, e.g. a tweet and its ad
+	// placement container
+	TimelineCell = `[data-testid="cellInnerDiv"]`
+
+	// Timeline tab labels
+	FollowingTabLabel = "Following"
+
+	// Community page header, holding the community's name
+	CommunityName = `[data-testid="primaryColumn"] h2`
+
+	// Profile page header
+	ProfileUserName  = `[data-testid="UserName"]`
+	ProfileBio       = `[data-testid="UserDescription"]`
+	ProfileFollowers = `a[href$="/verified_followers"], a[href$="/followers"]`
+
+	// One account in a list of accounts, e.g. the muted accounts
+	UserCell = `[data-testid="UserCell"]`
+
+	// One trend on the Explore page: its category, name, and post count as
+	// separate lines of text
+	TrendCell = `[data-testid="trend"]`
 
 	// Tweet content selectors
 	TweetText      = `[data-testid="tweetText"]`
 	TweetShowMore  = `button[data-testid="tweet-text-show-more-link"]`
 	TweetAuthor    = `[data-testid="User-Name"]`
+	VerifiedBadge  = `[data-testid="icon-verified"]`
 	TweetTimestamp = `time`
 	TweetLink      = `a[href*="/status/"]`
 	TweetMedia     = `[data-testid="tweetPhoto"], [data-testid="videoPlayer"]`
@@ -21,18 +64,89 @@ const (
 	ReplyCount   = `[data-testid="reply"]`
 	RetweetCount = `[data-testid="retweet"]`
 	LikeCount    = `[data-testid="like"]`
+	ViewCount    = `a[href$="/analytics"]`
 
 	// Tweet type indicators
 	RetweetIndicator = `[data-testid="socialContext"]`
 	QuoteIndicator   = `[data-testid="quoteTweet"]`
+	PollCard         = `[data-testid="cardPoll"]`
+	LinkCard         = `[data-testid="card.wrapper"]`
 	ReplyIndicator   = `[data-testid="tweet"] a[href*="/status/"][dir="ltr"]`
+	// Ads are wrapped in this container (it's an ancestor of the tweet)
+	PromotedContainer = `[data-testid="placementTracking"]`
 
 	// Login page indicators (for detecting auth state)
 	HomeIndicator = `[data-testid="SideNav_NewTweet_Button"]`
 	LoginForm     = `[data-testid="loginButton"]`
+	LoginFlowPath = "/i/flow/login"
+
+	// Account problem indicators (for the session health check). The text
+	// patterns are JS regex literals matched against the page text.
+	AccountAccessPath        = "/account/access" // Locked account / "unusual activity" lock
+	LoginChallengePath       = "/account/login_challenge"
+	AccountLockedTextPattern = `/your account (is|has been) (locked|suspended)/i`
+	ChallengeTextPattern     = `/unusual (login )?activity|verify (it's|that it's) you|confirm your identity/i`
 )
 
 // Common wait conditions
 const (
 	WaitForTweets = TweetArticle
 )
+
+// SelectorChain is an ordered list of alternative CSS selectors for the same
+// element. The extractor tries each in turn and uses the first that matches,
+// so a partial DOM change degrades to a fallback instead of breaking the field.
+type SelectorChain []string
+
+// ExtractionSelectors maps each element the extractor reads to its selector
+// chain. The first entry is the primary selector; the rest are fallbacks,
+// tried in order. "tweet" is queried against the document, "verified"
+// against the author element, and all others against each tweet article.
+var ExtractionSelectors = map[string]SelectorChain{
+	"tweet":      {TweetArticle, TimelineCell + ` article[role="article"]`},
+	"statusLink": {TweetLink},
+	"author":     {TweetAuthor, `[data-testid="UserName"]`},
+	"verified":   {VerifiedBadge, `svg[aria-label="Verified account"]`},
+	"text":       {TweetText, `div[lang][dir="auto"]`},
+	"media": {
+		`[data-testid="tweetPhoto"] img, [data-testid="videoPlayer"] video`,
+		`img[src*="pbs.twimg.com/media"], video`,
+	},
+	"altText":       {`[data-testid="tweetPhoto"] img[alt]`, `img[alt][src*="pbs.twimg.com/media"]`},
+	"time":          {TweetTimestamp},
+	"reply":         {ReplyCount, `button[aria-label*="Repl"]`},
+	"retweet":       {RetweetCount, `[data-testid="unretweet"]`},
+	"like":          {LikeCount, `[data-testid="unlike"]`},
+	"views":         {ViewCount, `[aria-label*=" views"]`},
+	"socialContext": {RetweetIndicator},
+	"poll":          {PollCard},
+	"card":          {LinkCard},
+	"quoteTweet":    {QuoteIndicator, `div[role="link"]:has([data-testid="User-Name"])`},
+	// Matched against the tweet's ancestors rather than its descendants
+	"promoted": {PromotedContainer},
+}
+
+// MobileExtractionSelectors replace their ExtractionSelectors chains when
+// scraping with mobile emulation. X's phone layout drops some desktop test
+// IDs from the action bar and moves the view count into it, so these lead
+// with the role and aria-label structure that layout shares across
+// redesigns.
+var MobileExtractionSelectors = map[string]SelectorChain{
+	"tweet":   {`article[role="article"]`, TweetArticle},
+	"reply":   {`[role="group"] [aria-label*="repl" i]`, ReplyCount},
+	"retweet": {`[role="group"] [aria-label*="repost" i]`, RetweetCount, `[data-testid="unretweet"]`},
+	"like":    {`[role="group"] [aria-label*="like" i]`, LikeCount, `[data-testid="unlike"]`},
+	"views":   {`[role="group"] [aria-label*="view" i]`, ViewCount},
+}
+
+// requiredChains are the chains every tweet should match. When most
+// lookups of one miss, the selector is broken rather than the field absent.
+var requiredChains = map[string]bool{
+	"tweet":      true,
+	"statusLink": true,
+	"author":     true,
+	"time":       true,
+	"reply":      true,
+	"retweet":    true,
+	"like":       true,
+}
@@ -0,0 +1,200 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/ibeckermayer/scroll4me/internal/browser"
+	"github.com/ibeckermayer/scroll4me/internal/store"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// reparseTimeout bounds re-parsing one snapshot
+const reparseTimeout = time.Minute
+
+// snapshotCollector gathers the tweet cells seen while scrolling into an
+// HTMLSnapshot
+type snapshotCollector struct {
+	snapshot store.HTMLSnapshot
+	seen     map[string]bool // Status IDs (or markup, for cells without one) already captured
+}
+
+func newSnapshotCollector(target scrapeTarget, mobile bool) *snapshotCollector {
+	return &snapshotCollector{
+		snapshot: store.HTMLSnapshot{Scrape: target.name, URL: target.url, Mobile: mobile},
+		seen:     make(map[string]bool),
+	}
+}
+
+// capture adds the timeline cells of the tweets currently in the DOM that
+// weren't captured yet. The cell rather than the tweet is kept so that
+// ancestor checks (e.g. "promoted") still work on the copy.
+func (c *snapshotCollector) capture(ctx context.Context, tweet SelectorChain) error {
+	captureJS := fmt.Sprintf(`
+		Array.from(document.querySelectorAll(%q), el => {
+			const cell = el.closest(%q) || el;
+			const link = cell.querySelector(%q);
+			return {id: link?.href?.match(/status\/(\d+)/)?.[1] || '', html: cell.outerHTML};
+		})
+	`, strings.Join(tweet, ", "), TimelineCell, TweetLink)
+
+	var cells []struct {
+		ID   string `json:"id"`
+		HTML string `json:"html"`
+	}
+	if err := chromedp.Run(ctx, chromedp.Evaluate(captureJS, &cells)); err != nil {
+		return err
+	}
+	for _, cell := range cells {
+		key := cell.ID
+		if key == "" {
+			key = cell.HTML
+		}
+		if !c.seen[key] {
+			c.seen[key] = true
+			c.snapshot.Cells = append(c.snapshot.Cells, cell.HTML)
+		}
+	}
+	return nil
+}
+
+// save writes the snapshot to step1_html, logging the result
+func (c *snapshotCollector) save() {
+	if len(c.snapshot.Cells) == 0 {
+		return
+	}
+	c.snapshot.CapturedAt = time.Now()
+	if path, err := store.SaveStepOutput(store.Step1HTML, c.snapshot); err != nil {
+		log.Printf("Failed to save HTML snapshot: %v", err)
+	} else {
+		log.Printf("Saved HTML snapshot of %d tweets to: %s", len(c.snapshot.Cells), path)
+	}
+}
+
+// ReparseSnapshot runs DOM extraction, with the selectors now in effect,
+// over a saved HTMLSnapshot. It loads the snapshot into a local headless
+// Chrome that never contacts X, so extraction changes can be checked
+// against past scrapes. The selector diagnosis is logged but not saved.
+func (s *Scraper) ReparseSnapshot(ctx context.Context, snap store.HTMLSnapshot) ([]types.Post, error) {
+	sel := reloadSelectors()
+	allocCtx, allocCancel := chromedp.NewExecAllocator(ctx, browser.Options(true, "")...)
+	defer allocCancel()
+	browserCtx, browserCancel := chromedp.NewContext(allocCtx)
+	defer browserCancel()
+	pageCtx, cancel := context.WithTimeout(browserCtx, reparseTimeout)
+	defer cancel()
+
+	log.Printf("Re-parsing %d tweets of %s from %s", len(snap.Cells), snap.Scrape, snap.CapturedAt.Format(time.DateTime))
+	if err := loadSnapshotPage(pageCtx, snap); err != nil {
+		return nil, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+
+	stats := newSelectorStats("re-parse of "+snap.Scrape, sel.extractionChains(snap.Mobile))
+	stats.offline = true
+	defer stats.logSummary()
+	return s.extractVisiblePosts(pageCtx, stats)
+}
+
+// offlineBlockedURLs are the URL patterns a snapshot page may not load.
+// The saved cells still point images, videos, and avatars at X's servers,
+// and the page's base URL would resolve relative ones there too.
+var offlineBlockedURLs = []string{"http://*", "https://*", "ws://*", "wss://*"}
+
+// loadSnapshotPage writes the snapshot's cells out as a local HTML page and
+// opens it from a file:// URL in the tab, with every network request
+// blocked so only the file itself loads
+func loadSnapshotPage(ctx context.Context, snap store.HTMLSnapshot) error {
+	f, err := os.CreateTemp("", "scroll4me-snapshot-*.html")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	// Relative links resolve against the scraped page, as they did on X
+	_, err = fmt.Fprintf(f, `<!DOCTYPE html><html><head><meta charset="utf-8"><base href="%s"></head><body>%s</body></html>`,
+		html.EscapeString(snap.URL), strings.Join(snap.Cells, "\n"))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	pageURL := url.URL{Scheme: "file", Path: "/" + strings.TrimPrefix(filepath.ToSlash(f.Name()), "/")}
+	return chromedp.Run(ctx,
+		network.Enable(),
+		network.SetBlockedURLs(offlineBlockedURLs),
+		chromedp.Navigate(pageURL.String()),
+	)
+}
+
+// WithReplay returns a copy of the scraper that never contacts X: timeline
+// scrapes re-parse the newest HTML snapshot of the same page in dir, a
+// step1_html directory (see WithHTMLSnapshots and store.StepDir), instead,
+// and everything else fails with ErrReplaying. This lets extraction be run
+// and developed deterministically without an X account. dir is fixed here
+// so the run's own output can go to another cache.
+func (s *Scraper) WithReplay(dir string) *Scraper {
+	replaying := *s
+	replaying.replay = true
+	replaying.replayDir = dir
+	return &replaying
+}
+
+// Replaying reports whether the scraper replays snapshots instead of
+// contacting X
+func (s *Scraper) Replaying() bool {
+	return s.replay
+}
+
+// replayScrape implements scrape in replay mode
+func (s *Scraper) replayScrape(ctx context.Context, count int, target scrapeTarget) ([]types.Post, error) {
+	snap, path, err := latestSnapshot(s.replayDir, target.name)
+	if err != nil {
+		return nil, err
+	}
+	if snap == nil {
+		return nil, fmt.Errorf("no HTML snapshot of %s to replay", target.name)
+	}
+	log.Printf("Replaying %s from %s", target.name, path)
+	posts, err := s.ReparseSnapshot(ctx, *snap)
+	if err != nil {
+		return nil, err
+	}
+	if len(posts) > count {
+		posts = posts[:count]
+	}
+	target.label(posts)
+	return posts, nil
+}
+
+// latestSnapshot returns the newest snapshot of the named scrape in dir and
+// its path, or nil if there is none. Unreadable files are logged and skipped.
+func latestSnapshot(dir, scrape string) (*store.HTMLSnapshot, string, error) {
+	files, err := store.FilesIn(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, path := range slices.Backward(files) {
+		snap, err := store.LoadStepOutput[store.HTMLSnapshot](path)
+		if err != nil {
+			log.Printf("Skipping %s: %v", path, err)
+			continue
+		}
+		if snap.Scrape == scrape {
+			return &snap, path, nil
+		}
+	}
+	return nil, "", nil
+}
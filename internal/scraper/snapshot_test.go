@@ -0,0 +1,136 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/store"
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// writeSnapshot saves snap to dir under a step file name for the given time
+func writeSnapshot(t *testing.T, dir string, at time.Time, snap store.HTMLSnapshot) string {
+	t.Helper()
+	data, err := json.Marshal(snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, at.Format("2006-01-02T15-04-05")+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// tweetCell returns the markup of a minimal timeline cell as X renders it
+func tweetCell(handle, id, text string) string {
+	return fmt.Sprintf(`<div data-testid="cellInnerDiv"><article data-testid="tweet" role="article">
+<div data-testid="User-Name"><a href="/%[1]s" role="link"><span>%[1]s</span></a><span>@%[1]s</span>
+<a href="/%[1]s/status/%[2]s" role="link"><time datetime="2026-10-01T12:00:00.000Z">Oct 1</time></a></div>
+<div data-testid="tweetText" lang="en"><span>%[3]s</span></div>
+</article></div>`, handle, id, text)
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.Local)
+	writeSnapshot(t, dir, base, store.HTMLSnapshot{Scrape: "For You feed", URL: "https://x.com/home"})
+	want := writeSnapshot(t, dir, base.Add(time.Hour), store.HTMLSnapshot{Scrape: "For You feed", URL: "https://x.com/home?newer"})
+	writeSnapshot(t, dir, base.Add(2*time.Hour), store.HTMLSnapshot{Scrape: "Following feed"})
+	if err := os.WriteFile(filepath.Join(dir, base.Add(3*time.Hour).Format("2006-01-02T15-04-05")+".json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, path, err := latestSnapshot(dir, "For You feed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap == nil || path != want || snap.URL != "https://x.com/home?newer" {
+		t.Errorf("latestSnapshot = %v, %q; want the newest For You snapshot %q", snap, path, want)
+	}
+
+	snap, _, err = latestSnapshot(dir, "Bookmarks")
+	if err != nil || snap != nil {
+		t.Errorf("latestSnapshot of a page never saved = %v, %v; want nil, nil", snap, err)
+	}
+	snap, _, err = latestSnapshot(filepath.Join(dir, "missing"), "For You feed")
+	if err != nil || snap != nil {
+		t.Errorf("latestSnapshot of a missing directory = %v, %v; want nil, nil", snap, err)
+	}
+}
+
+func TestReplayNeverContactsX(t *testing.T) {
+	s := New(true, false, "", "", nil)
+	replaying := s.WithReplay(t.TempDir())
+	if s.Replaying() || !replaying.Replaying() {
+		t.Fatal("WithReplay must return a replaying copy and leave the original alone")
+	}
+
+	ctx := context.Background()
+	if _, err := replaying.ScrapeTrends(ctx, nil); !errors.Is(err, ErrReplaying) {
+		t.Errorf("ScrapeTrends error = %v, want ErrReplaying", err)
+	}
+	if _, err := replaying.ScrapeAuthorProfiles(ctx, nil, []string{"someone"}); !errors.Is(err, ErrReplaying) {
+		t.Errorf("ScrapeAuthorProfiles error = %v, want ErrReplaying", err)
+	}
+	if _, _, err := replaying.ScrapeModeratedAccounts(ctx, nil); !errors.Is(err, ErrReplaying) {
+		t.Errorf("ScrapeModeratedAccounts error = %v, want ErrReplaying", err)
+	}
+	if _, err := replaying.ScrapeForYou(ctx, nil, 10); err == nil || !strings.Contains(err.Error(), "no HTML snapshot") {
+		t.Errorf("ScrapeForYou without a snapshot error = %v, want no HTML snapshot", err)
+	}
+}
+
+// requireChrome skips the test unless a Chrome that chromedp can launch is
+// installed
+func requireChrome(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome"} {
+		if _, err := exec.LookPath(name); err == nil {
+			return
+		}
+	}
+	if _, err := os.Stat("/Applications/Google Chrome.app/Contents/MacOS/Google Chrome"); err == nil {
+		return
+	}
+	t.Skip("Chrome not installed")
+}
+
+func TestReplayScrape(t *testing.T) {
+	requireChrome(t)
+	dir := t.TempDir()
+	writeSnapshot(t, dir, time.Now(), store.HTMLSnapshot{
+		Scrape: "For You feed",
+		URL:    "https://x.com/home",
+		Cells: []string{
+			tweetCell("first", "1001", "The first replayed post"),
+			tweetCell("second", "1002", "The second replayed post"),
+		},
+	})
+
+	s := New(true, false, "", "", nil).WithReplay(dir)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	posts, err := s.ScrapeForYou(ctx, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 1 {
+		t.Fatalf("got %d posts, want 1 (capped at count)", len(posts))
+	}
+	got := posts[0]
+	if got.ID != "1001" || got.AuthorHandle != "first" || !strings.Contains(got.Content, "first replayed post") {
+		t.Errorf("replayed post = %+v, want the snapshot's first tweet", got)
+	}
+	if got.Source != types.SourceFeed || got.FetchedVia != "For You" {
+		t.Errorf("replayed post labeled %q via %q, want the For You feed", got.Source, got.FetchedVia)
+	}
+}
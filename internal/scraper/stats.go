@@ -0,0 +1,129 @@
+package scraper
+
+import (
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/store"
+)
+
+// selectorStats records, for each selector chain, how many lookups were
+// answered by each selector in the chain. Index len(chain) counts misses.
+type selectorStats struct {
+	scrape string                   // What's being scraped, for the report
+	chains map[string]SelectorChain // The chains in use for this scrape
+	hits   map[string][]int
+	warned map[string]bool // chains already warned about falling back
+	// If true, this is a re-parse of a saved snapshot and the report isn't
+	// saved over the latest live one
+	offline bool
+}
+
+// newSelectorStats creates an empty stats tracker for the given chains
+func newSelectorStats(scrape string, chains map[string]SelectorChain) *selectorStats {
+	return &selectorStats{
+		scrape: scrape,
+		chains: chains,
+		hits:   make(map[string][]int),
+		warned: make(map[string]bool),
+	}
+}
+
+// record merges per-extraction counts reported by the extraction JS and
+// warns the first time a chain falls back past its primary selector.
+func (st *selectorStats) record(counts map[string][]int) {
+	for key, c := range counts {
+		chain := st.chains[key]
+		total := st.hits[key]
+		if total == nil {
+			total = make([]int, len(chain)+1)
+			st.hits[key] = total
+		}
+		for i := 0; i < len(c) && i < len(total); i++ {
+			total[i] += c[i]
+			if i > 0 && i < len(chain) && c[i] > 0 && !st.warned[key] {
+				st.warned[key] = true
+				log.Printf("Warning: primary selector for %q matched nothing, using fallback #%d: %s",
+					key, i, chain[i])
+			}
+		}
+	}
+}
+
+// logSummary logs per-chain success metrics for the scrape, followed by a
+// diagnosis of broken chains, and saves the diagnosis as the latest
+// selector report. Scrapes that never read the DOM log nothing.
+func (st *selectorStats) logSummary() {
+	if len(st.hits) == 0 {
+		return
+	}
+	report := st.report()
+	for _, c := range report.Chains {
+		fallback := 0
+		for _, s := range c.Selectors[1:] {
+			fallback += s.Hits
+		}
+		log.Printf("Selector %q: %d primary, %d fallback, %d missing (%s)",
+			c.Key, c.Selectors[0].Hits, fallback, c.Missing, c.Status)
+	}
+
+	broken, fallback := report.Problems()
+	switch {
+	case len(broken) > 0:
+		log.Printf("Selector diagnosis: BROKEN %s; on fallbacks: %s - update them in %s",
+			strings.Join(broken, ", "), strings.Join(fallback, ", "), selectorsFileName)
+	case len(fallback) > 0:
+		log.Printf("Selector diagnosis: primary selectors failing for %s (fallbacks working)", strings.Join(fallback, ", "))
+	default:
+		log.Println("Selector diagnosis: all selectors healthy")
+	}
+
+	if st.offline {
+		return
+	}
+	if path, err := store.SaveSelectorReport(report); err != nil {
+		log.Printf("Failed to save selector report: %v", err)
+	} else {
+		log.Printf("Selector report saved to: %s", path)
+	}
+}
+
+// report turns the recorded counts into a per-chain diagnosis, in key order
+func (st *selectorStats) report() store.SelectorReport {
+	keys := make([]string, 0, len(st.hits))
+	for key := range st.hits {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	report := store.SelectorReport{At: time.Now(), Scrape: st.scrape}
+	for _, key := range keys {
+		counts := st.hits[key]
+		chain := st.chains[key]
+		c := store.SelectorChainReport{
+			Key:      key,
+			Required: requiredChains[key],
+			Missing:  counts[len(counts)-1],
+		}
+		found := 0
+		for i, sel := range chain {
+			c.Selectors = append(c.Selectors, store.SelectorHits{Selector: sel, Hits: counts[i]})
+			found += counts[i]
+		}
+
+		switch {
+		case c.Required && c.Missing > found:
+			c.Status = store.SelectorBroken
+		case found == 0:
+			c.Status = store.SelectorUnmatched
+		case counts[0] == 0:
+			c.Status = store.SelectorFallback
+		default:
+			c.Status = store.SelectorOK
+		}
+		report.Chains = append(report.Chains, c)
+	}
+	return report
+}
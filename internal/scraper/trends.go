@@ -0,0 +1,124 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// trendsTimeout bounds the whole Trending page scrape
+const trendsTimeout = time.Minute
+
+// trendPostCountPattern matches a trend's post count line, e.g. "12.3K posts"
+var trendPostCountPattern = regexp.MustCompile(`^([\d.,]+[KkMm]?) posts?$`)
+
+// ScrapeTrends reads the trends listed on the Explore page's Trending tab,
+// in order. It only reads what the page first shows; there's no scrolling.
+func (s *Scraper) ScrapeTrends(ctx context.Context, cookies []*network.Cookie) ([]types.Trend, error) {
+	sel := reloadSelectors()
+	if s.replay {
+		return nil, ErrReplaying
+	}
+	if err := s.limiter.Acquire(ctx, "trends"); err != nil {
+		return nil, fmt.Errorf("not scraping trends: %w", err)
+	}
+	return s.runTrendsScrape(ctx, cookies, sel)
+}
+
+// runTrendsScrape implements ScrapeTrends once the rate limiter allows it
+func (s *Scraper) runTrendsScrape(ctx context.Context, cookies []*network.Cookie, sel *Selectors) ([]types.Trend, error) {
+	log.Println("Starting scrape of trends")
+	pageCtx, cancel, err := s.openBrowser(ctx, cookies, s.timeout(trendsTimeout))
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	log.Printf("Navigating to %s...", sel.TrendingURL)
+	stateJS := fmt.Sprintf(`
+		(function() {
+			if (document.querySelector(%q)) return 'trends';
+			if (document.querySelector(%q) || location.pathname.startsWith(%q)) return 'login';
+			return '';
+		})()
+	`, sel.TrendCell, sel.LoginForm, sel.LoginFlowPath)
+	var state string
+	if err := chromedp.Run(pageCtx,
+		chromedp.Navigate(sel.TrendingURL),
+		chromedp.Poll(stateJS, &state, chromedp.WithPollingInterval(500*time.Millisecond)),
+	); err != nil {
+		return nil, fmt.Errorf("failed to load trends: %w", err)
+	}
+	if state == "login" {
+		return nil, ErrSessionInvalid
+	}
+
+	var cells [][]string
+	cellsJS := fmt.Sprintf(`Array.from(document.querySelectorAll(%q), el => el.innerText.split('\n'))`, sel.TrendCell)
+	if err := chromedp.Run(pageCtx, chromedp.Evaluate(cellsJS, &cells)); err != nil {
+		return nil, fmt.Errorf("failed to extract trends: %w", err)
+	}
+
+	now := time.Now()
+	var trends []types.Trend
+	for _, lines := range cells {
+		trend, ok := parseTrend(lines)
+		if !ok {
+			continue
+		}
+		trend.Rank = len(trends) + 1
+		trend.ScrapedAt = now
+		trends = append(trends, trend)
+	}
+	log.Printf("Scraped %d trends", len(trends))
+	return trends, nil
+}
+
+// parseTrend reads a trend cell's lines of text: an optional rank, a
+// category line such as "Technology · Trending" or "Trending in Germany",
+// the trend's name, and an optional post count. Reports false if there's
+// no name.
+func parseTrend(lines []string) (types.Trend, bool) {
+	var trend types.Trend
+	var rest []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "" || line == "·" || isDigits(line):
+			// Separator or rank
+		case trendPostCountPattern.MatchString(line):
+			trend.PostCount = parseMetric(trendPostCountPattern.FindStringSubmatch(line)[1])
+		default:
+			rest = append(rest, line)
+		}
+	}
+	if len(rest) < 2 {
+		return trend, false
+	}
+
+	category := rest[0]
+	category = strings.TrimSpace(strings.TrimSuffix(category, "· Trending"))
+	if category == "Trending" {
+		category = ""
+	}
+	trend.Category = category
+	trend.Name = rest[1]
+	return trend, true
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
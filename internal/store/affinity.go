@@ -0,0 +1,40 @@
+package store
+
+import "time"
+
+// authorAffinityFile holds per-author affinity learned from digest history
+const authorAffinityFile = "author_affinity.json"
+
+// AuthorAffinity tracks how often an author's analyzed posts make it into
+// digests. Score is an exponential moving average of inclusion (1) versus
+// exclusion (0), so recent runs count most.
+type AuthorAffinity struct {
+	Score     float64   `json:"score"`
+	Seen      int       `json:"seen"`     // Analyzed posts observed
+	Included  int       `json:"included"` // Of those, how many made the digest
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LoadAuthorAffinity reads per-author affinity keyed by lowercase handle.
+// Returns an empty map if nothing has been recorded yet.
+func LoadAuthorAffinity() (map[string]AuthorAffinity, error) {
+	path, err := cachePath(authorAffinityFile)
+	if err != nil {
+		return nil, err
+	}
+
+	affinity := make(map[string]AuthorAffinity)
+	if err := loadJSON(path, &affinity); err != nil {
+		return nil, err
+	}
+	return affinity, nil
+}
+
+// SaveAuthorAffinity writes per-author affinity to disk.
+func SaveAuthorAffinity(affinity map[string]AuthorAffinity) error {
+	path, err := cachePath(authorAffinityFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, affinity)
+}
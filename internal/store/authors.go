@@ -0,0 +1,30 @@
+package store
+
+import "github.com/ibeckermayer/scroll4me/internal/types"
+
+// authorProfilesFile caches author profiles fetched by the enrichment pass
+const authorProfilesFile = "author_profiles.json"
+
+// LoadAuthorProfiles reads cached author profiles keyed by lowercase handle.
+// Returns an empty map if none have been fetched yet.
+func LoadAuthorProfiles() (map[string]types.AuthorProfile, error) {
+	path, err := cachePath(authorProfilesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make(map[string]types.AuthorProfile)
+	if err := loadJSON(path, &profiles); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
+
+// SaveAuthorProfiles writes cached author profiles to disk.
+func SaveAuthorProfiles(profiles map[string]types.AuthorProfile) error {
+	path, err := cachePath(authorProfilesFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, profiles)
+}
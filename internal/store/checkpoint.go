@@ -0,0 +1,73 @@
+package store
+
+import (
+	"os"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// scrapeCheckpointFile holds posts collected by scrapes that haven't
+// finished, keyed by scrape target (e.g. "For You feed")
+const scrapeCheckpointFile = "scrape_checkpoint.json"
+
+// SaveScrapeCheckpoint records the posts collected so far by an in-progress
+// scrape of target, replacing its previous checkpoint.
+func SaveScrapeCheckpoint(target string, posts []types.Post) error {
+	return updateScrapeCheckpoints(func(checkpoints map[string][]types.Post) {
+		checkpoints[target] = posts
+	})
+}
+
+// ClearScrapeCheckpoint drops the checkpoint of a scrape that finished.
+func ClearScrapeCheckpoint(target string) error {
+	return updateScrapeCheckpoints(func(checkpoints map[string][]types.Post) {
+		delete(checkpoints, target)
+	})
+}
+
+// TakeScrapeCheckpoints returns the posts left behind by interrupted
+// scrapes, keyed by target, and clears them.
+func TakeScrapeCheckpoints() (map[string][]types.Post, error) {
+	path, err := cachePath(scrapeCheckpointFile)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoints, err := loadScrapeCheckpoints(path)
+	if err != nil || len(checkpoints) == 0 {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
+
+// updateScrapeCheckpoints applies update to the checkpoints on disk,
+// removing the file once none are left.
+func updateScrapeCheckpoints(update func(map[string][]types.Post)) error {
+	path, err := cachePath(scrapeCheckpointFile)
+	if err != nil {
+		return err
+	}
+
+	checkpoints, err := loadScrapeCheckpoints(path)
+	if err != nil {
+		return err
+	}
+	update(checkpoints)
+
+	if len(checkpoints) == 0 {
+		return removeFile(path)
+	}
+	return saveJSON(path, checkpoints)
+}
+
+// loadScrapeCheckpoints reads the checkpoint file, treating a missing file as empty.
+func loadScrapeCheckpoints(path string) (map[string][]types.Post, error) {
+	checkpoints := make(map[string][]types.Post)
+	if err := loadJSON(path, &checkpoints); err != nil {
+		return nil, err
+	}
+	return checkpoints, nil
+}
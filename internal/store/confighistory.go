@@ -0,0 +1,51 @@
+package store
+
+import "time"
+
+// configHistoryFile holds the audit trail of config changes
+const configHistoryFile = "config_history.json"
+
+// maxConfigChanges caps how many changes are kept; older ones are dropped
+const maxConfigChanges = 200
+
+// ConfigChange is one observed edit to the config
+type ConfigChange struct {
+	At      time.Time `json:"at"`
+	Changes []string  `json:"changes"` // One line per changed setting, see config.DiffSettings
+}
+
+// ConfigHistory is the audit trail of config changes, plus the settings as
+// last seen so the next load can be compared against them.
+type ConfigHistory struct {
+	Settings map[string]string `json:"settings"`
+	Changes  []ConfigChange    `json:"changes"` // Oldest first
+}
+
+// Record appends a change, dropping the oldest ones past the cap.
+func (h *ConfigHistory) Record(change ConfigChange) {
+	h.Changes = append(h.Changes, change)
+	if len(h.Changes) > maxConfigChanges {
+		h.Changes = h.Changes[len(h.Changes)-maxConfigChanges:]
+	}
+}
+
+// LoadConfigHistory reads the config audit trail. Returns an empty history
+// (nil Settings) if nothing has been recorded yet.
+func LoadConfigHistory() (ConfigHistory, error) {
+	var history ConfigHistory
+	path, err := cachePath(configHistoryFile)
+	if err != nil {
+		return history, err
+	}
+	err = loadJSON(path, &history)
+	return history, err
+}
+
+// SaveConfigHistory writes the config audit trail to disk.
+func SaveConfigHistory(history ConfigHistory) error {
+	path, err := cachePath(configHistoryFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, history)
+}
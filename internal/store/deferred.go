@@ -0,0 +1,77 @@
+package store
+
+import (
+	"slices"
+
+	"github.com/ibeckermayer/scroll4me/internal/types"
+)
+
+// deferredPostsFile holds posts whose analysis was put off to the next run
+const deferredPostsFile = "deferred_posts.json"
+
+// SaveDeferredPosts adds posts to the set waiting for the next run,
+// replacing any earlier copies of the same post.
+func SaveDeferredPosts(posts []types.Post) error {
+	path, err := cachePath(deferredPostsFile)
+	if err != nil {
+		return err
+	}
+
+	existing, err := loadDeferredPosts(path)
+	if err != nil {
+		return err
+	}
+	saving := make(map[string]bool, len(posts))
+	for _, p := range posts {
+		saving[p.ID] = true
+	}
+	for _, p := range existing {
+		if !saving[p.ID] {
+			posts = append(posts, p)
+		}
+	}
+
+	return saveJSON(path, posts)
+}
+
+// LoadDeferredPosts returns the posts waiting for analysis. They stay
+// waiting until RemoveDeferredPosts clears them.
+func LoadDeferredPosts() ([]types.Post, error) {
+	path, err := cachePath(deferredPostsFile)
+	if err != nil {
+		return nil, err
+	}
+	return loadDeferredPosts(path)
+}
+
+// RemoveDeferredPosts clears the posts with the given IDs from the set
+// waiting for the next run, once they've been analyzed.
+func RemoveDeferredPosts(ids []string) error {
+	path, err := cachePath(deferredPostsFile)
+	if err != nil {
+		return err
+	}
+
+	posts, err := loadDeferredPosts(path)
+	if err != nil || len(posts) == 0 {
+		return err
+	}
+	removing := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		removing[id] = true
+	}
+	kept := slices.DeleteFunc(posts, func(p types.Post) bool { return removing[p.ID] })
+	if len(kept) == 0 {
+		return removeFile(path)
+	}
+	return saveJSON(path, kept)
+}
+
+// loadDeferredPosts reads the deferred posts file, treating a missing file as empty.
+func loadDeferredPosts(path string) ([]types.Post, error) {
+	var posts []types.Post
+	if err := loadJSON(path, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// feedbackTokenFile holds the secret digest rating links carry, so only
+// links scroll4me wrote can rate posts
+const feedbackTokenFile = "feedback_token"
+
+// FeedbackToken returns this install's secret for digest rating links,
+// creating it on first use. It's kept in the config directory rather than
+// the cache so clearing the cache doesn't break the links in old digests.
+func FeedbackToken() (string, error) {
+	configDir, err := config.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(configDir, feedbackTokenFile)
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+
+	secret := make([]byte, 16)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	token := hex.EncodeToString(secret)
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, os.ErrExist) {
+		// Another process created it first; use theirs
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(token); err != nil {
+		f.Close()
+		return "", err
+	}
+	return token, f.Close()
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/ibeckermayer/scroll4me/internal/atomicfile"
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// cachePath returns the path of a file in the cache directory, given its
+// path relative to it
+func cachePath(elem ...string) (string, error) {
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append([]string{cacheDir}, elem...)...), nil
+}
+
+// loadJSON decodes the JSON file at path into v. A missing file isn't an
+// error and leaves v as it was, so callers set up its empty value first.
+func loadJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// saveJSON writes v to path as indented JSON, through atomicfile.Write
+func saveJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return atomicfile.Write(path, data, 0644)
+}
+
+// removeFile deletes the file at path, if there is one
+func removeFile(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
@@ -1,12 +1,8 @@
 package store
 
 import (
-	"encoding/json"
-	"os"
 	"path/filepath"
 	"time"
-
-	"github.com/ibeckermayer/scroll4me/internal/config"
 )
 
 // LLMExchange represents a prompt/response pair for caching
@@ -17,16 +13,17 @@ type LLMExchange struct {
 	Prompt    string    `json:"prompt"`
 	Response  string    `json:"response"`
 	Error     string    `json:"error,omitempty"`
+	// Token counts from the response's usage block
+	InputTokens      int64 `json:"input_tokens,omitempty"`
+	OutputTokens     int64 `json:"output_tokens,omitempty"`
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
 }
 
 // LLMCacheDir returns the path to the LLM cache directory.
 // On macOS this is ~/Library/Caches/scroll4me/llm/
 func LLMCacheDir() (string, error) {
-	cacheDir, err := config.CacheDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(cacheDir, "llm"), nil
+	return cachePath("llm")
 }
 
 // SaveLLMExchange serializes an LLM exchange to JSON and writes it to a timestamped file.
@@ -37,25 +34,15 @@ func SaveLLMExchange(exchange LLMExchange) (string, error) {
 		return "", err
 	}
 
-	// Create directory if it doesn't exist
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", err
-	}
-
 	// Generate filename with timestamp (using dashes instead of colons for filesystem compatibility)
 	filename := time.Now().Format("2006-01-02T15-04-05") + ".json"
 	path := filepath.Join(dir, filename)
 
-	// Serialize exchange to JSON with indentation for readability
-	data, err := json.MarshalIndent(exchange, "", "  ")
-	if err != nil {
-		return "", err
-	}
-
-	// Write to file
-	if err := os.WriteFile(path, data, 0644); err != nil {
+	// Indented for readability
+	if err := saveJSON(path, exchange); err != nil {
 		return "", err
 	}
 
+	recordArtifact(func(m *RunManifest) { m.LLMExchanges = append(m.LLMExchanges, path) })
 	return path, nil
 }
@@ -0,0 +1,189 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/config"
+)
+
+// runsDir holds one directory per run, each with a manifestFile
+const (
+	runsDir      = "runs"
+	manifestFile = "manifest.json"
+)
+
+// RunManifest lists every artifact a run wrote, so tools can find them
+// without matching up timestamps across cache directories
+type RunManifest struct {
+	ID           string                `json:"id"`   // Start time, in the step filename format
+	Kind         string                `json:"kind"` // e.g. "digest", "headlines", "focus"
+	StartedAt    time.Time             `json:"started_at"`
+	FinishedAt   time.Time             `json:"finished_at"`
+	ConfigHash   string                `json:"config_hash"` // Of the settings the run started with
+	StepOutputs  map[StepName][]string `json:"step_outputs,omitempty"`
+	Digests      []string              `json:"digests,omitempty"` // Saved to the digest output directory
+	LLMExchanges []string              `json:"llm_exchanges,omitempty"`
+	Error        string                `json:"error,omitempty"`
+}
+
+// Run records the artifacts of a run in progress. Saves made while it's
+// active are added to its manifest.
+type Run struct {
+	mu       sync.Mutex
+	manifest RunManifest
+}
+
+var (
+	activeRunMu sync.Mutex
+	activeRun   *Run
+)
+
+// BeginRun starts recording a run and returns it. If a run is already
+// being recorded, it returns nil and that run keeps recording; Finish on a
+// nil Run does nothing.
+func BeginRun(kind, configHash string) *Run {
+	activeRunMu.Lock()
+	defer activeRunMu.Unlock()
+	if activeRun != nil {
+		return nil
+	}
+	now := time.Now()
+	activeRun = &Run{manifest: RunManifest{
+		ID:          now.Format(filenameTimeFormat),
+		Kind:        kind,
+		StartedAt:   now,
+		ConfigHash:  configHash,
+		StepOutputs: make(map[StepName][]string),
+	}}
+	return activeRun
+}
+
+// Finish stops recording and saves the manifest, noting err if the run
+// failed. Returns the manifest path.
+func (r *Run) Finish(err error) (string, error) {
+	if r == nil {
+		return "", nil
+	}
+	activeRunMu.Lock()
+	if activeRun == r {
+		activeRun = nil
+	}
+	activeRunMu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.manifest.FinishedAt = time.Now()
+	if err != nil {
+		r.manifest.Error = err.Error()
+	}
+	return SaveRunManifest(r.manifest)
+}
+
+// recordArtifact applies add to the manifest of the active run, if any
+func recordArtifact(add func(m *RunManifest)) {
+	activeRunMu.Lock()
+	r := activeRun
+	activeRunMu.Unlock()
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	add(&r.manifest)
+}
+
+// recordStepOutput adds a step output to the active run
+func recordStepOutput(step StepName, path string) {
+	recordArtifact(func(m *RunManifest) { m.StepOutputs[step] = append(m.StepOutputs[step], path) })
+}
+
+// RecordDigest adds a digest saved outside the cache to the active run
+func RecordDigest(path string) {
+	recordArtifact(func(m *RunManifest) { m.Digests = append(m.Digests, path) })
+}
+
+// runDir returns the directory of the run with the given ID
+func runDir(id string) (string, error) {
+	return cachePath(runsDir, id)
+}
+
+// SaveRunManifest writes m to runs/<id>/manifest.json in the cache
+// directory. Returns the path to the saved file.
+func SaveRunManifest(m RunManifest) (string, error) {
+	dir, err := runDir(m.ID)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, manifestFile)
+	if err := saveJSON(path, m); err != nil {
+		return "", fmt.Errorf("failed to write run manifest: %w", err)
+	}
+	return path, nil
+}
+
+// LoadRunManifest reads the manifest of the run with the given ID
+func LoadRunManifest(id string) (RunManifest, error) {
+	var m RunManifest
+	dir, err := runDir(id)
+	if err != nil {
+		return m, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// RecentRunManifests loads the manifests of the n most recent runs that
+// match keep, newest first. Unreadable manifests are skipped.
+func RecentRunManifests(n int, keep func(m *RunManifest) bool) ([]RunManifest, error) {
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(cacheDir, runsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var manifests []RunManifest
+	for i := len(entries) - 1; i >= 0 && len(manifests) < n; i-- {
+		data, err := os.ReadFile(filepath.Join(cacheDir, runsDir, entries[i].Name(), manifestFile))
+		if err != nil {
+			continue
+		}
+		var m RunManifest
+		if err := json.Unmarshal(data, &m); err != nil || !keep(&m) {
+			continue
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests, nil
+}
+
+// LatestRunManifest returns the path to the most recent run's manifest
+func LatestRunManifest() (string, error) {
+	cacheDir, err := config.CacheDir()
+	if err != nil {
+		return "", err
+	}
+	entries, err := os.ReadDir(filepath.Join(cacheDir, runsDir))
+	if err != nil && !os.IsNotExist(err) {
+		return "", err
+	}
+	// os.ReadDir sorts by name, which is chronological for run IDs
+	for i := len(entries) - 1; i >= 0; i-- {
+		path := filepath.Join(cacheDir, runsDir, entries[i].Name(), manifestFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no run manifests found")
+}
@@ -0,0 +1,7 @@
+package store
+
+// MediaCacheDir returns the path to the downloaded media directory.
+// On macOS this is ~/Library/Caches/scroll4me/media/
+func MediaCacheDir() (string, error) {
+	return cachePath("media")
+}
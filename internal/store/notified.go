@@ -0,0 +1,31 @@
+package store
+
+import "time"
+
+// notifiedPostsFile holds the posts urgent notifications were sent for
+const notifiedPostsFile = "notified_posts.json"
+
+// LoadNotifiedPosts reads when a notification was sent for each post, by
+// post ID. Returns an empty map if none have been sent yet.
+func LoadNotifiedPosts() (map[string]time.Time, error) {
+	path, err := cachePath(notifiedPostsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	notified := make(map[string]time.Time)
+	if err := loadJSON(path, &notified); err != nil {
+		return nil, err
+	}
+	return notified, nil
+}
+
+// SaveNotifiedPosts writes when a notification was sent for each post to
+// disk.
+func SaveNotifiedPosts(notified map[string]time.Time) error {
+	path, err := cachePath(notifiedPostsFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, notified)
+}
@@ -0,0 +1,64 @@
+package store
+
+import "time"
+
+// outboxFile holds digest deliveries waiting for connectivity
+const outboxFile = "outbox.json"
+
+// failedDeliveriesFile holds deliveries given up on after too many
+// attempts, for the user to look into
+const failedDeliveriesFile = "outbox_failed.json"
+
+// QueuedDelivery is a digest upload that couldn't be completed, e.g.
+// because the machine was offline
+type QueuedDelivery struct {
+	Path     string    `json:"path"`   // Local digest file
+	Target   string    `json:"target"` // Sync target name, e.g. "webdav"
+	QueuedAt time.Time `json:"queued_at"`
+	Attempts int       `json:"attempts"`
+	// The error of the last failed attempt
+	LastError string `json:"last_error,omitempty"`
+}
+
+// AddFailedDeliveries appends deliveries given up on to the failed
+// deliveries file, which nothing retries.
+func AddFailedDeliveries(failed []QueuedDelivery) error {
+	path, err := cachePath(failedDeliveriesFile)
+	if err != nil {
+		return err
+	}
+
+	var all []QueuedDelivery
+	if err := loadJSON(path, &all); err != nil {
+		return err
+	}
+	return saveJSON(path, append(all, failed...))
+}
+
+// LoadOutbox reads the queued deliveries, oldest first.
+// Returns nil if nothing is queued.
+func LoadOutbox() ([]QueuedDelivery, error) {
+	path, err := cachePath(outboxFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var queue []QueuedDelivery
+	if err := loadJSON(path, &queue); err != nil {
+		return nil, err
+	}
+	return queue, nil
+}
+
+// SaveOutbox replaces the queued deliveries. An empty queue removes the file.
+func SaveOutbox(queue []QueuedDelivery) error {
+	path, err := cachePath(outboxFile)
+	if err != nil {
+		return err
+	}
+
+	if len(queue) == 0 {
+		return removeFile(path)
+	}
+	return saveJSON(path, queue)
+}
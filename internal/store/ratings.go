@@ -0,0 +1,30 @@
+package store
+
+import "github.com/ibeckermayer/scroll4me/internal/types"
+
+// ratingsFile holds the posts the user rated, for calibrating analysis
+const ratingsFile = "ratings.json"
+
+// LoadRatings reads the posts the user rated, oldest first. Returns nil if
+// none have been rated yet.
+func LoadRatings() ([]types.RatedPost, error) {
+	path, err := cachePath(ratingsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var ratings []types.RatedPost
+	if err := loadJSON(path, &ratings); err != nil {
+		return nil, err
+	}
+	return ratings, nil
+}
+
+// SaveRatings writes the posts the user rated to disk.
+func SaveRatings(ratings []types.RatedPost) error {
+	path, err := cachePath(ratingsFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, ratings)
+}
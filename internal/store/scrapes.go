@@ -0,0 +1,34 @@
+package store
+
+import "time"
+
+// scrapeHistoryFile holds recent browser launches for rate limiting
+const scrapeHistoryFile = "scrape_history.json"
+
+// ScrapeHistory records recent scrape activity for the rate limiter
+type ScrapeHistory struct {
+	Launches            []time.Time `json:"launches"` // Browser launches in the last 24h, oldest first
+	ConsecutiveFailures int         `json:"consecutive_failures"`
+	BackoffUntil        time.Time   `json:"backoff_until"`
+}
+
+// LoadScrapeHistory reads the scrape history, or returns an empty one if
+// none has been recorded yet.
+func LoadScrapeHistory() (ScrapeHistory, error) {
+	var history ScrapeHistory
+	path, err := cachePath(scrapeHistoryFile)
+	if err != nil {
+		return history, err
+	}
+	err = loadJSON(path, &history)
+	return history, err
+}
+
+// SaveScrapeHistory writes the scrape history to disk, atomically.
+func SaveScrapeHistory(history ScrapeHistory) error {
+	path, err := cachePath(scrapeHistoryFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, history)
+}
@@ -0,0 +1,74 @@
+package store
+
+import "time"
+
+// selectorReportFile holds the selector diagnosis of the latest DOM scrape
+const selectorReportFile = "selector_report.json"
+
+// Selector chain statuses
+const (
+	SelectorOK        = "ok"        // The primary selector matched
+	SelectorFallback  = "fallback"  // Only fallback selectors matched
+	SelectorBroken    = "broken"    // A required field was missing from most tweets
+	SelectorUnmatched = "unmatched" // An optional field never matched (may just not have appeared)
+)
+
+// SelectorReport diagnoses how well each selector chain worked in one scrape
+type SelectorReport struct {
+	At     time.Time             `json:"at"`
+	Scrape string                `json:"scrape"` // e.g. "For You feed"
+	Chains []SelectorChainReport `json:"chains"`
+}
+
+// SelectorChainReport is one chain's lookups, by selector
+type SelectorChainReport struct {
+	Key       string         `json:"key"`
+	Required  bool           `json:"required"`
+	Status    string         `json:"status"`
+	Selectors []SelectorHits `json:"selectors"` // In chain order
+	Missing   int            `json:"missing"`   // Lookups no selector answered
+}
+
+// SelectorHits is how many lookups a selector answered
+type SelectorHits struct {
+	Selector string `json:"selector"`
+	Hits     int    `json:"hits"`
+}
+
+// Problems returns the keys of chains that are broken and of those that
+// only work through fallbacks
+func (r SelectorReport) Problems() (broken, fallback []string) {
+	for _, c := range r.Chains {
+		switch c.Status {
+		case SelectorBroken:
+			broken = append(broken, c.Key)
+		case SelectorFallback:
+			fallback = append(fallback, c.Key)
+		}
+	}
+	return broken, fallback
+}
+
+// LoadSelectorReport reads the latest selector report. Returns nil if no DOM
+// scrape has been diagnosed yet.
+func LoadSelectorReport() (*SelectorReport, error) {
+	path, err := cachePath(selectorReportFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var report *SelectorReport
+	if err := loadJSON(path, &report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SaveSelectorReport replaces the latest selector report, returning its path.
+func SaveSelectorReport(report SelectorReport) (string, error) {
+	path, err := cachePath(selectorReportFile)
+	if err != nil {
+		return "", err
+	}
+	return path, saveJSON(path, report)
+}
@@ -0,0 +1,15 @@
+package store
+
+import "time"
+
+// HTMLSnapshot is the markup of the tweets one scrape saw, saved to
+// step1_html so extraction can be re-run on it offline
+type HTMLSnapshot struct {
+	Scrape     string    `json:"scrape"` // e.g. "For You feed"
+	URL        string    `json:"url"`
+	Mobile     bool      `json:"mobile"` // Captured with mobile emulation
+	CapturedAt time.Time `json:"captured_at"`
+	// outerHTML of each tweet's timeline cell, in the order first seen,
+	// after truncated tweets were expanded
+	Cells []string `json:"cells"`
+}
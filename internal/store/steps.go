@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/atomicfile"
 )
 
 // StepName identifies a pipeline step for caching purposes.
@@ -15,69 +16,66 @@ type StepName string
 
 const (
 	Step1Posts    StepName = "step1_posts"
+	Step1Trends   StepName = "step1_trends" // Scraped alongside posts, if enabled
+	Step1HTML     StepName = "step1_html"   // HTMLSnapshot of each scrape, if enabled
 	Step2Analyses StepName = "step2_analyses"
 	Step3Filtered StepName = "step3_filtered"
 	Step4Digests  StepName = "step4_digests"
 )
 
-// stepDir returns the cache directory for a given step.
-func stepDir(step StepName) (string, error) {
-	cacheDir, err := config.CacheDir()
-	if err != nil {
-		return "", err
-	}
-	return filepath.Join(cacheDir, string(step)), nil
+// StepDir returns the cache directory for a given step.
+func StepDir(step StepName) (string, error) {
+	return cachePath(string(step))
 }
 
+// filenameTimeFormat is the timestamp layout used for cached step filenames.
+const filenameTimeFormat = "2006-01-02T15-04-05"
+
 // generateFilename creates a timestamped filename with the given extension.
 func generateFilename(ext string) string {
-	return time.Now().Format("2006-01-02T15-04-05") + ext
+	return time.Now().Format(filenameTimeFormat) + ext
+}
+
+// StepFileTime parses the creation time encoded in a cached step file's name.
+func StepFileTime(path string) (time.Time, error) {
+	name := filepath.Base(path)
+	if len(name) < len(filenameTimeFormat) {
+		return time.Time{}, fmt.Errorf("not a step output file: %s", name)
+	}
+	return time.ParseInLocation(filenameTimeFormat, name[:len(filenameTimeFormat)], time.Local)
 }
 
 // SaveStepOutput saves JSON-serializable data to the step's cache directory.
 // Returns the path to the saved file.
 func SaveStepOutput[T any](step StepName, data T) (string, error) {
-	dir, err := stepDir(step)
+	dir, err := StepDir(step)
 	if err != nil {
 		return "", err
 	}
 
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create step cache dir: %w", err)
-	}
-
 	path := filepath.Join(dir, generateFilename(".json"))
-
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal step output: %w", err)
-	}
-
-	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+	if err := saveJSON(path, data); err != nil {
 		return "", fmt.Errorf("failed to write step output: %w", err)
 	}
 
+	recordStepOutput(step, path)
 	return path, nil
 }
 
 // SaveTextOutput saves text content (e.g., markdown) to the step's cache directory.
 // Returns the path to the saved file.
 func SaveTextOutput(step StepName, content string, ext string) (string, error) {
-	dir, err := stepDir(step)
+	dir, err := StepDir(step)
 	if err != nil {
 		return "", err
 	}
 
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", fmt.Errorf("failed to create step cache dir: %w", err)
-	}
-
 	path := filepath.Join(dir, generateFilename(ext))
-
-	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+	if err := atomicfile.Write(path, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("failed to write step output: %w", err)
 	}
 
+	recordStepOutput(step, path)
 	return path, nil
 }
 
@@ -117,32 +115,68 @@ func LoadStepOutput[T any](filepath string) (T, error) {
 
 // LatestStepFile returns the path to the most recent file in a step's cache directory.
 func LatestStepFile(step StepName) (string, error) {
-	dir, err := stepDir(step)
+	files, err := StepFiles(step)
+	if err != nil {
+		return "", err
+	}
+
+	if len(files) == 0 {
+		return "", fmt.Errorf("no cached output for step %s", step)
+	}
+
+	return files[len(files)-1], nil
+}
+
+// StepFileBefore returns the path to the most recent file in a step's cache
+// directory created at or before t.
+func StepFileBefore(step StepName, t time.Time) (string, error) {
+	files, err := StepFiles(step)
 	if err != nil {
 		return "", err
 	}
 
+	for i := len(files) - 1; i >= 0; i-- {
+		created, err := StepFileTime(files[i])
+		if err != nil {
+			continue
+		}
+		if !created.After(t) {
+			return files[i], nil
+		}
+	}
+
+	return "", fmt.Errorf("no cached output for step %s before %s", step, t.Format(time.RFC3339))
+}
+
+// StepFiles returns the paths of all files in a step's cache directory, oldest first.
+// A missing cache directory yields an empty list.
+func StepFiles(step StepName) ([]string, error) {
+	dir, err := StepDir(step)
+	if err != nil {
+		return nil, err
+	}
+	return FilesIn(dir)
+}
+
+// FilesIn returns the paths of all files in a step cache directory (see
+// StepDir), oldest first. A missing directory yields an empty list.
+func FilesIn(dir string) ([]string, error) {
 	entries, err := os.ReadDir(dir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", fmt.Errorf("no cached output for step %s", step)
+			return nil, nil
 		}
-		return "", err
+		return nil, err
 	}
 
-	// Filter to regular files (os.ReadDir already sorts by name, which is chronological for our timestamps)
+	// Filter to regular files, leaving out hidden ones such as writes in
+	// progress (os.ReadDir already sorts by name, which is chronological for our timestamps)
 	var files []string
 	for _, entry := range entries {
-		if !entry.IsDir() {
-			files = append(files, entry.Name())
+		if !entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			files = append(files, filepath.Join(dir, entry.Name()))
 		}
 	}
 
-	if len(files) == 0 {
-		return "", fmt.Errorf("no cached output for step %s", step)
-	}
-
-	latestFile := files[len(files)-1]
-
-	return filepath.Join(dir, latestFile), nil
+	return files, nil
 }
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ibeckermayer/scroll4me/internal/atomicfile"
+)
+
+// tokenUsageFile logs the tokens each LLM call used, for cost reports. It
+// holds one JSON record per line and is only ever appended to, apart from
+// the occasional compaction.
+const tokenUsageFile = "token_usage.jsonl"
+
+// tokenUsageRetention is how long token usage records are kept
+const tokenUsageRetention = 180 * 24 * time.Hour
+
+// tokenUsageCompactSlack is how far past retention the oldest record may be
+// before the log is rewritten without the expired ones, so compaction runs
+// about monthly rather than on every call
+const tokenUsageCompactSlack = 30 * 24 * time.Hour
+
+// tokenUsageMu serializes updates from concurrent analysis batches
+var tokenUsageMu sync.Mutex
+
+// TokenUsage is the tokens one LLM call used, as reported by the provider
+type TokenUsage struct {
+	At           time.Time `json:"at"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Call         string    `json:"call"`          // What it was for, e.g. "analyze"
+	Run          string    `json:"run,omitempty"` // ID of the run it was part of, if any
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	// Prompt cache tokens, billed apart from InputTokens: written to the
+	// cache (at a premium), and read from it (at a discount)
+	CacheWriteTokens int64 `json:"cache_write_tokens,omitempty"`
+	CacheReadTokens  int64 `json:"cache_read_tokens,omitempty"`
+}
+
+// LoadTokenUsage reads the token usage log, oldest first, leaving out
+// records past retention. Returns nil if nothing has been recorded yet.
+func LoadTokenUsage() ([]TokenUsage, error) {
+	path, err := cachePath(tokenUsageFile)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := readTokenUsage(path)
+	if err != nil {
+		return nil, err
+	}
+	return unexpired(usage), nil
+}
+
+// RecordTokenUsage appends a call to the token usage log, attributing it to
+// the active run if there is one. Each record is a single appended line, so
+// a crash can at worst leave a torn last line, which readers skip.
+func RecordTokenUsage(u TokenUsage) error {
+	tokenUsageMu.Lock()
+	defer tokenUsageMu.Unlock()
+
+	path, err := cachePath(tokenUsageFile)
+	if err != nil {
+		return err
+	}
+	recordArtifact(func(m *RunManifest) { u.Run = m.ID })
+	line, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	if err := compactTokenUsage(path); err != nil {
+		log.Printf("Warning: failed to compact token usage log: %v", err)
+	}
+	return nil
+}
+
+// readTokenUsage reads the records in the log at path, skipping lines that
+// don't parse. Returns nil if the log doesn't exist.
+func readTokenUsage(path string) ([]TokenUsage, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var usage []TokenUsage
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var u TokenUsage
+		if err := json.Unmarshal(line, &u); err != nil {
+			continue
+		}
+		usage = append(usage, u)
+	}
+	return usage, scanner.Err()
+}
+
+// writeTokenUsage replaces the log at path with usage, atomically
+func writeTokenUsage(path string, usage []TokenUsage) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, u := range usage {
+		if err := enc.Encode(u); err != nil {
+			return err
+		}
+	}
+	return atomicfile.Write(path, buf.Bytes(), 0644)
+}
+
+// unexpired returns the records of usage that are still within retention
+func unexpired(usage []TokenUsage) []TokenUsage {
+	cutoff := time.Now().Add(-tokenUsageRetention)
+	kept := usage[:0]
+	for _, u := range usage {
+		if u.At.After(cutoff) {
+			kept = append(kept, u)
+		}
+	}
+	return kept
+}
+
+// compactTokenUsage rewrites the log at path without its expired records,
+// once the oldest is well past retention. Only the first line is read
+// otherwise, so recording a call doesn't read the whole log.
+func compactTokenUsage(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var oldest TokenUsage
+	if scanner.Scan() {
+		json.Unmarshal(scanner.Bytes(), &oldest)
+	}
+	f.Close()
+	if oldest.At.IsZero() || time.Since(oldest.At) < tokenUsageRetention+tokenUsageCompactSlack {
+		return nil
+	}
+
+	usage, err := readTokenUsage(path)
+	if err != nil {
+		return err
+	}
+	return writeTokenUsage(path, unexpired(usage))
+}
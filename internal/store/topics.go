@@ -0,0 +1,42 @@
+package store
+
+import "time"
+
+// topicMemoryFile holds what recent digests were about
+const topicMemoryFile = "topic_memory.json"
+
+// DigestedPost is what a post that made a digest was about, kept so later
+// runs can tell when they're showing yet another take on the same subject
+type DigestedPost struct {
+	ID       string    `json:"id"`
+	Topics   []string  `json:"topics"`
+	Summary  string    `json:"summary"`
+	Content  string    `json:"content"`
+	QuotedID string    `json:"quoted_id,omitempty"`
+	Link     string    `json:"link,omitempty"` // Key of the article it links to (see ranking.LinkKey)
+	At       time.Time `json:"at"`             // When it last made a digest
+}
+
+// LoadTopicMemory reads the posts of recent digests, oldest first. Returns
+// an empty list if nothing has been recorded yet.
+func LoadTopicMemory() ([]DigestedPost, error) {
+	path, err := cachePath(topicMemoryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []DigestedPost
+	if err := loadJSON(path, &posts); err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// SaveTopicMemory writes the posts of recent digests to disk.
+func SaveTopicMemory(posts []DigestedPost) error {
+	path, err := cachePath(topicMemoryFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, posts)
+}
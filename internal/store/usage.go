@@ -0,0 +1,75 @@
+package store
+
+import "time"
+
+// usageFile holds the local reading-habits log. It never leaves the machine.
+const usageFile = "usage.json"
+
+// usageRetention is how long usage events are kept
+const usageRetention = 90 * 24 * time.Hour
+
+// Usage event kinds
+const (
+	UsageDigestGenerated = "digest_generated"
+	UsageDigestOpened    = "digest_opened"    // Opened again after the automatic open on generation
+	UsageDigestShown     = "digest_shown"     // Opened automatically on generation, which doesn't make it read
+	UsageDigestRolledUp  = "digest_rolled_up" // Listed as unread at the top of a later digest
+)
+
+// UsageEvent is one recorded interaction with a digest
+type UsageEvent struct {
+	Kind   string    `json:"kind"`
+	At     time.Time `json:"at"`
+	Digest string    `json:"digest"` // Digest file path
+}
+
+// UsageLog is the local reading-habits log
+type UsageLog struct {
+	Events []UsageEvent `json:"events"` // Oldest first
+	// When tracking started or a digest last included the reading habits
+	// section
+	HabitsReportedAt time.Time `json:"habits_reported_at"`
+}
+
+// LoadUsage reads the usage log. Returns an empty log if nothing has been
+// recorded yet.
+func LoadUsage() (UsageLog, error) {
+	var usage UsageLog
+	path, err := cachePath(usageFile)
+	if err != nil {
+		return usage, err
+	}
+	err = loadJSON(path, &usage)
+	return usage, err
+}
+
+// SaveUsage writes the usage log to disk, dropping events past retention.
+func SaveUsage(usage UsageLog) error {
+	path, err := cachePath(usageFile)
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-usageRetention)
+	kept := usage.Events[:0]
+	for _, e := range usage.Events {
+		if e.At.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	usage.Events = kept
+	return saveJSON(path, usage)
+}
+
+// RecordUsage appends an event to the usage log.
+func RecordUsage(kind, digest string) error {
+	usage, err := LoadUsage()
+	if err != nil {
+		return err
+	}
+	if usage.HabitsReportedAt.IsZero() {
+		usage.HabitsReportedAt = time.Now()
+	}
+	usage.Events = append(usage.Events, UsageEvent{Kind: kind, At: time.Now(), Digest: digest})
+	return SaveUsage(usage)
+}
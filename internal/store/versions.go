@@ -0,0 +1,44 @@
+package store
+
+import "time"
+
+// postVersionsFile holds the text history of scraped posts, for spotting
+// edits
+const postVersionsFile = "post_versions.json"
+
+// PostHistory is every distinct text a post has been scraped with, oldest
+// first
+type PostHistory struct {
+	Versions []PostVersion `json:"versions"`
+	LastSeen time.Time     `json:"last_seen"`
+}
+
+// PostVersion is a post's text as first seen at SeenAt
+type PostVersion struct {
+	Content string    `json:"content"`
+	SeenAt  time.Time `json:"seen_at"`
+}
+
+// LoadPostVersions reads post text histories keyed by post ID. Returns an
+// empty map if none have been recorded yet.
+func LoadPostVersions() (map[string]PostHistory, error) {
+	path, err := cachePath(postVersionsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[string]PostHistory)
+	if err := loadJSON(path, &history); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// SavePostVersions writes post text histories to disk.
+func SavePostVersions(history map[string]PostHistory) error {
+	path, err := cachePath(postVersionsFile)
+	if err != nil {
+		return err
+	}
+	return saveJSON(path, history)
+}
@@ -1,26 +1,39 @@
 package tray
 
 import (
+	"context"
 	_ "embed"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
 
 	"github.com/getlantern/systray"
 	"github.com/pkg/browser"
 
 	"github.com/ibeckermayer/scroll4me/internal/app"
 	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/scraper"
 )
 
 //go:embed icon.png
 var iconBytes []byte
 
+const tooltip = "scroll4me - X digest without the doomscrolling"
+
 // OnReady returns a systray onReady callback that sets up the menu.
 func OnReady(a *app.App) func() {
 	return func() {
 		// Set icon (template icon for macOS menu bar styling)
 		systray.SetTemplateIcon(iconBytes, iconBytes)
 		systray.SetTitle("")
-		systray.SetTooltip("scroll4me - X digest without the doomscrolling")
+		systray.SetTooltip(tooltip)
+
+		// Deliver digests that were generated while offline
+		go a.WatchOutbox(context.Background())
+
+		// Answer the rating links in digests, if they're on
+		go a.ServeFeedback(context.Background())
 
 		// Auth status (disabled, just for display)
 		var authStatusLabel string
@@ -41,11 +54,21 @@ func OnReady(a *app.App) func() {
 		}
 		mAuthAction := systray.AddMenuItem(authActionLabel, "Login or logout from X")
 
+		// Shown when a run hits X's login wall
+		mRelogin := systray.AddMenuItem("⚠ Session expired - Re-login now", "Log in to X again and resume digest generation")
+		mRelogin.Hide()
+
 		systray.AddSeparator()
 
 		// Generate Digest (combined scrape + analyze + build)
 		mGenerateDigest := systray.AddMenuItem("Generate Digest", "Scrape, analyze, and create digest")
 
+		// Quick headlines (no LLM analysis)
+		mGenerateHeadlines := systray.AddMenuItem("Quick Headlines", "Top posts by engagement, no analysis")
+
+		// Regenerate from cached analyses with the current config
+		mRegenerateDigest := systray.AddMenuItem("Regenerate Digest", "Rebuild last digest with current settings")
+
 		systray.AddSeparator()
 
 		// View last digest
@@ -57,6 +80,10 @@ func OnReady(a *app.App) func() {
 		// Reload config
 		mReloadConfig := systray.AddMenuItem("Reload Config", "Reload configuration from disk")
 
+		// Shown when a reload is rejected; lists the problems
+		mConfigError := systray.AddMenuItem("⚠ Config has errors", "Open config file to fix")
+		mConfigError.Hide()
+
 		systray.AddSeparator()
 
 		// Quit
@@ -73,6 +100,47 @@ func OnReady(a *app.App) func() {
 			}
 		}
 
+		// reloadConfig swaps in the config from disk, or shows what's wrong with it
+		reloadConfig := func() {
+			err := a.ReloadConfig()
+			if err == nil {
+				mConfigError.Hide()
+				systray.SetTooltip(tooltip)
+				return
+			}
+			log.Printf("Failed to reload config: %v", err)
+			problems := strings.Split(err.Error(), "\n")
+			if len(problems) > 1 {
+				// The first line just says the reload was refused
+				problems = problems[1:]
+			}
+			title := "⚠ Config error: " + problems[0]
+			if len(problems) > 1 {
+				title += fmt.Sprintf(" (+%d more)", len(problems)-1)
+			}
+			mConfigError.SetTitle(title)
+			mConfigError.SetTooltip(strings.Join(problems, "\n"))
+			mConfigError.Show()
+			systray.SetTooltip("scroll4me - config not reloaded, fix errors and reload")
+		}
+
+		// generateDigest runs the pipeline, surfacing an expired session as a tray alert
+		generateDigest := func() {
+			systray.SetTooltip(tooltip)
+			err := a.GenerateDigest()
+			if errors.Is(err, scraper.ErrSessionInvalid) {
+				systray.SetTooltip("scroll4me - X session expired, re-login needed")
+				mRelogin.Show()
+				return
+			}
+			if errors.Is(err, scraper.ErrAccountChallenge) || errors.Is(err, scraper.ErrAccountLocked) {
+				systray.SetTooltip("scroll4me - X account needs attention, check x.com")
+			}
+			if err != nil {
+				log.Printf("Generate digest error: %v", err)
+			}
+		}
+
 		// Handle menu clicks
 		go func() {
 			for {
@@ -90,10 +158,37 @@ func OnReady(a *app.App) func() {
 					updateAuthUI()
 
 				case <-mGenerateDigest.ClickedCh:
+					go generateDigest()
+
+				case <-mRelogin.ClickedCh:
+					mRelogin.Hide()
+					systray.SetTooltip(tooltip)
 					go func() {
-						if err := a.GenerateDigest(); err != nil {
-							log.Printf("Generate digest error: %v", err)
+						if err := a.TriggerLogin(); err != nil {
+							log.Printf("Login error: %v", err)
+							mRelogin.Show()
+							return
+						}
+						updateAuthUI()
+						// Resume the interrupted run
+						generateDigest()
+					}()
+
+				case <-mGenerateHeadlines.ClickedCh:
+					go func() {
+						if err := a.GenerateHeadlines(); err != nil {
+							log.Printf("Generate headlines error: %v", err)
+						}
+					}()
+
+				case <-mRegenerateDigest.ClickedCh:
+					go func() {
+						path, err := a.RegenerateDigest(0, 0)
+						if err != nil {
+							log.Printf("Regenerate digest error: %v", err)
+							return
 						}
+						a.OpenNewDigest(path)
 					}()
 
 				case <-mViewDigest.ClickedCh:
@@ -112,11 +207,21 @@ func OnReady(a *app.App) func() {
 					}
 
 				case <-mReloadConfig.ClickedCh:
-					if err := a.ReloadConfig(); err != nil {
-						log.Printf("Failed to reload config: %v", err)
+					reloadConfig()
+
+				case <-mConfigError.ClickedCh:
+					path, err := config.ConfigPath()
+					if err != nil {
+						log.Printf("Failed to get config path: %v", err)
+						continue
+					}
+					if err := browser.OpenFile(path); err != nil {
+						log.Printf("Failed to open config file: %v", err)
 					}
 
 				case <-mQuit.ClickedCh:
+					// Stop any run first, so its Chrome doesn't outlive us
+					a.CancelPipeline()
 					systray.Quit()
 				}
 			}
@@ -128,4 +233,3 @@ func OnReady(a *app.App) func() {
 func OnExit() {
 	log.Println("scroll4me shutting down...")
 }
-
@@ -1,24 +1,185 @@
 package types
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+)
 
 // Post represents a scraped X post
 type Post struct {
-	ID           string    `json:"id"`
+	ID             string    `json:"id"`
+	AuthorHandle   string    `json:"author_handle"`
+	AuthorName     string    `json:"author_name"`
+	AuthorVerified bool      `json:"author_verified"`
+	Content        string    `json:"content"`
+	Lang           string    `json:"lang,omitempty"` // Language X detected in Content, e.g. "en"; see PrimaryLang
+	MediaURLs      []string  `json:"media_urls"`
+	MediaAltText   []string  `json:"media_alt_text,omitempty"` // Author-provided image descriptions
+	LocalMedia     []string  `json:"local_media,omitempty"`    // Cached copies of MediaURLs, if downloaded
+	Timestamp      time.Time `json:"timestamp"`
+	Likes          int       `json:"likes"`
+	Retweets       int       `json:"retweets"`
+	Replies        int       `json:"replies"`
+	QuoteTweets    int       `json:"quote_tweets"`
+	Views          int       `json:"views"`
+	IsRetweet      bool      `json:"is_retweet"`
+	IsPromoted     bool      `json:"is_promoted"` // Ad placed in the timeline
+	IsQuoteTweet   bool      `json:"is_quote_tweet"`
+	QuotedPost     *Post     `json:"quoted_post,omitempty"` // The post being quoted, if IsQuoteTweet
+	ReplyTo        *Post     `json:"reply_to,omitempty"`    // The post replied to, if IsReply and it was fetched
+	Poll           *Poll     `json:"poll,omitempty"`
+	Card           *LinkCard `json:"card,omitempty"` // Link preview card, if the post links out
+	IsReply        bool      `json:"is_reply"`
+	// ID and author handle of the post replied to, if IsReply and the
+	// timeline's GraphQL data said (DOM extraction can't tell)
+	ReplyToID     string    `json:"reply_to_id,omitempty"`
+	ReplyToHandle string    `json:"reply_to_handle,omitempty"`
+	ThreadParts   []string  `json:"thread_parts,omitempty"` // Text of each post in a stitched self-thread; Content joins them
+	OriginalURL   string    `json:"original_url"`
+	Source        string    `json:"source"`                // Where the post was scraped from, e.g. SourceFeed
+	FetchedVia    string    `json:"fetched_via,omitempty"` // Feed name, list ID, or search query within Source
+	Community     string    `json:"community,omitempty"`   // Name of the X Community the post was scraped from
+	ScrapedAt     time.Time `json:"scraped_at"`
+
+	// The author's profile, if it was fetched in an earlier run
+	AuthorProfile *AuthorProfile `json:"author_profile,omitempty"`
+	// Set if the post's text changed since an earlier run first scraped it,
+	// with the text as first seen
+	Edited           bool   `json:"edited,omitempty"`
+	FirstSeenContent string `json:"first_seen_content,omitempty"`
+	// Handles of the accounts whose reposts of this post were scraped. X
+	// shows each repost as the original post, so they're deduplicated into
+	// one post by its ID.
+	RetweetedBy []string `json:"retweeted_by,omitempty"`
+	// Near-identical posts by other accounts, collapsed into this one so
+	// only it is analyzed
+	AlsoCoveredBy []PostRef `json:"also_covered_by,omitempty"`
+	// Replies by other accounts read from the post's conversation page, if
+	// its discussion was summarized
+	Discussion []Post `json:"discussion,omitempty"`
+}
+
+// PostRef identifies another post by its author and URL
+type PostRef struct {
+	ID           string `json:"id"`
+	AuthorHandle string `json:"author_handle"`
+	URL          string `json:"url"`
+}
+
+// MergeRetweeters adds the accounts other was reposted by to p's, skipping
+// handles already listed. Used when the same post is scraped again.
+func (p *Post) MergeRetweeters(other Post) {
+	for _, handle := range other.RetweetedBy {
+		if !slices.ContainsFunc(p.RetweetedBy, func(h string) bool { return strings.EqualFold(h, handle) }) {
+			p.RetweetedBy = append(p.RetweetedBy, handle)
+		}
+	}
+}
+
+// PrimaryLang returns the primary subtag of a language code in lowercase
+// (e.g. "pt" for "pt-BR"), or "" if the code says X couldn't tell: empty,
+// "und", "zxx", or one of X's private "q" codes for posts of only media,
+// hashtags, mentions, or links.
+func PrimaryLang(code string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(code)), "-")
+	if lang == "" || lang == "und" || lang == "zxx" || strings.HasPrefix(lang, "q") {
+		return ""
+	}
+	return lang
+}
+
+// Poll represents a poll attached to a post
+type Poll struct {
+	Choices    []PollChoice `json:"choices"`
+	TotalVotes int          `json:"total_votes"`
+	Closed     bool         `json:"closed"`
+}
+
+// PollChoice is one option in a poll. Percent is 0-100, or 0 if results
+// aren't visible (open polls the user hasn't voted in).
+type PollChoice struct {
+	Label   string  `json:"label"`
+	Percent float64 `json:"percent"`
+}
+
+// LinkCard is the preview card X shows for a linked web page. Excerpt is
+// filled in by the optional article-fetching step before analysis.
+type LinkCard struct {
+	URL         string `json:"url"`
+	Domain      string `json:"domain"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Excerpt     string `json:"excerpt,omitempty"`
+}
+
+// AuthorProfile is what an author's profile page says about them
+type AuthorProfile struct {
+	Name      string    `json:"name"`
+	Bio       string    `json:"bio,omitempty"`
+	Followers int       `json:"followers"`
+	Verified  bool      `json:"verified"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// Trend is one entry of X's Trending list
+type Trend struct {
+	Rank      int       `json:"rank"` // 1-based position in the list
+	Name      string    `json:"name"` // e.g. "#GoLang" or "Rust 2.0"
+	Category  string    `json:"category,omitempty"`
+	PostCount int       `json:"post_count,omitempty"` // 0 if X doesn't show one
+	ScrapedAt time.Time `json:"scraped_at"`
+}
+
+// InterestTopic is a candidate interest found in the user's own feed,
+// offered when they haven't configured any
+type InterestTopic struct {
+	Keyword     string `json:"keyword"`     // As it would go in interests.keywords
+	Description string `json:"description"` // What the posts about it discuss
+	PostCount   int    `json:"post_count"`  // Scraped posts about it
+}
+
+// RatedPost is a post the user rated, shown to the LLM as an example of
+// what they do and don't want to see
+type RatedPost struct {
+	PostID       string    `json:"post_id"`
 	AuthorHandle string    `json:"author_handle"`
-	AuthorName   string    `json:"author_name"`
 	Content      string    `json:"content"`
-	MediaURLs    []string  `json:"media_urls"`
-	Timestamp    time.Time `json:"timestamp"`
-	Likes        int       `json:"likes"`
-	Retweets     int       `json:"retweets"`
-	Replies      int       `json:"replies"`
-	QuoteTweets  int       `json:"quote_tweets"`
-	IsRetweet    bool      `json:"is_retweet"`
-	IsQuoteTweet bool      `json:"is_quote_tweet"`
-	IsReply      bool      `json:"is_reply"`
-	OriginalURL  string    `json:"original_url"`
-	ScrapedAt    time.Time `json:"scraped_at"`
+	Good         bool      `json:"good"`           // Whether the user wanted to see it
+	Note         string    `json:"note,omitempty"` // Why, in the user's words
+	RatedAt      time.Time `json:"rated_at"`
+}
+
+// TopicSection is a digest section the LLM grouped posts into
+type TopicSection struct {
+	Name    string   `json:"name"`     // Short and broad, e.g. "AI" or "Politics"
+	PostIDs []string `json:"post_ids"` // The posts in it
+}
+
+// Post sources
+const (
+	SourceFeed      = "feed"
+	SourceList      = "list"
+	SourceSearch    = "search"
+	SourceBookmarks = "bookmarks"
+	SourceMentions  = "mentions"
+	SourceProfile   = "profile"
+	SourceCommunity = "community"
+)
+
+// LikeRate returns likes per view, or 0 if the view count is unknown.
+// It's a rough engagement-quality signal: viral posts with few likes per
+// view tend to be bait, while niche posts with a high rate resonate.
+func (p Post) LikeRate() float64 {
+	if p.Views == 0 {
+		return 0
+	}
+	return float64(p.Likes) / float64(p.Views)
+}
+
+// Engagement returns the post's total interactions (likes, retweets, and replies)
+func (p Post) Engagement() int {
+	return p.Likes + p.Retweets + p.Replies
 }
 
 // Analysis represents LLM analysis results for a post
@@ -27,11 +188,38 @@ type Analysis struct {
 	RelevanceScore float64   `json:"relevance_score"`
 	Topics         []string  `json:"topics"`
 	Summary        string    `json:"summary"`
+	EngagementBait bool      `json:"engagement_bait"`
+	Reason         string    `json:"reason,omitempty"` // Why the post got its score, e.g. the interests it matches
 	AnalyzedAt     time.Time `json:"analyzed_at"`
+	// How time-sensitive the post is for the user, from 0 (can wait for
+	// the next digest) to 1 (an outage, security advisory, or breaking news
+	// in their interests)
+	Urgency float64 `json:"urgency,omitempty"`
+	// If true, the triage model ruled the post out and it was never fully
+	// analyzed; its score stays 0 whatever rules or ratings it matches
+	RuledOut bool `json:"ruled_out,omitempty"`
 }
 
 // PostWithAnalysis combines a post with its analysis
 type PostWithAnalysis struct {
 	Post     Post
 	Analysis *Analysis
+	// RankScore orders posts in the digest when set: relevance blended with
+	// other signals such as author affinity. 0 means rank by relevance.
+	RankScore float64
+	// How many posts in recent digests were about the same subject
+	Repeats int
+	// Topic section of the digest the post was grouped into, e.g. "AI"
+	Section string
+}
+
+// Rank returns the score the digest orders posts by
+func (p PostWithAnalysis) Rank() float64 {
+	if p.RankScore != 0 {
+		return p.RankScore
+	}
+	if p.Analysis == nil {
+		return 0
+	}
+	return p.Analysis.RelevanceScore
 }
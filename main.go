@@ -1,11 +1,21 @@
 package main
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/chromedp/chromedp"
 	"github.com/getlantern/systray"
@@ -17,6 +27,8 @@ import (
 	"github.com/ibeckermayer/scroll4me/internal/auth"
 	browseropts "github.com/ibeckermayer/scroll4me/internal/browser"
 	"github.com/ibeckermayer/scroll4me/internal/config"
+	"github.com/ibeckermayer/scroll4me/internal/container"
+	"github.com/ibeckermayer/scroll4me/internal/e2e"
 	"github.com/ibeckermayer/scroll4me/internal/scraper"
 	"github.com/ibeckermayer/scroll4me/internal/store"
 	"github.com/ibeckermayer/scroll4me/internal/tray"
@@ -33,7 +45,15 @@ func main() {
 		}
 		log.Fatal(err)
 	}
-	if err := root.Run(context.Background()); err != nil {
+
+	// The first Ctrl-C cancels the command, which closes any Chrome it
+	// launched; a second one exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if err := root.Run(ctx); err != nil {
 		if err == flag.ErrHelp {
 			os.Exit(0)
 		}
@@ -48,18 +68,26 @@ func buildCLI() *ffcli.Command {
 		ShortHelp:  "AI-powered social media digest",
 		LongHelp:   "Running with no command starts the system tray application.",
 		Subcommands: []*ffcli.Command{
+			nowCmd(),
 			openCmd(),
+			configCmd(),
 			stepCmd(),
+			digestsCmd(),
+			statsCmd(),
+			reportCmd(),
+			rateCmd(),
+			graphCmd(),
 			loginCmd(),
 			logoutCmd(),
 			clearCmd(),
 			botTestCmd(),
+			devCmd(),
 		},
 		Exec: func(ctx context.Context, args []string) error {
 			if len(args) > 0 {
 				return fmt.Errorf("unknown command: %s\nRun 'scroll4me --help' for usage", args[0])
 			}
-			runTrayApp()
+			runTrayApp(ctx)
 			return nil
 		},
 	}
@@ -81,6 +109,9 @@ func stepCmd() *ffcli.Command {
 			stepDigestCmd(),
 			stepOpenCmd(),
 			stepAllCmd(),
+			stepHeadlinesCmd(),
+			stepRegenerateCmd(),
+			stepReparseCmd(),
 		},
 		Exec: func(ctx context.Context, args []string) error {
 			return flag.ErrHelp
@@ -89,19 +120,36 @@ func stepCmd() *ffcli.Command {
 }
 
 func stepScrapeCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("scrape", flag.ExitOnError)
+	source := fs.String("source", "feed", "where to scrape from: feed (configured feed, lists, and searches) or bookmarks")
+	replay := fs.Bool("replay", false, "re-parse the latest saved HTML snapshot of each page instead of contacting X")
+
 	return &ffcli.Command{
 		Name:       "scrape",
-		ShortUsage: "scroll4me step scrape",
-		ShortHelp:  "Step 1: Scrape posts from X For You feed",
+		ShortUsage: "scroll4me step scrape [-source feed|bookmarks] [-replay]",
+		ShortHelp:  "Step 1: Scrape posts from the configured X sources, or bookmarks",
+		FlagSet:    fs,
 		Exec: func(ctx context.Context, args []string) error {
 			a, err := initApp()
 			if err != nil {
 				return err
 			}
-			if !a.IsAuthenticated() {
+			if *replay {
+				if err := a.UseReplay(); err != nil {
+					return err
+				}
+			}
+			if !a.IsAuthenticated() && (*source != "feed" || !a.Config().Scraping.GuestFallback) {
 				return fmt.Errorf("not authenticated - run 'scroll4me login' first")
 			}
-			_, err = a.ScrapeForYou(ctx)
+			switch *source {
+			case "feed":
+				_, err = a.ScrapePosts(ctx)
+			case "bookmarks":
+				_, err = a.ScrapeBookmarks(ctx)
+			default:
+				return fmt.Errorf("unknown source: %s (use 'feed' or 'bookmarks')", *source)
+			}
 			return err
 		},
 	}
@@ -197,9 +245,7 @@ func stepDigestCmd() *ffcli.Command {
 				return err
 			}
 			if !*noOpen {
-				if err := browser.OpenFile(digestPath); err != nil {
-					log.Printf("Failed to open digest: %v", err)
-				}
+				a.OpenNewDigest(digestPath)
 			}
 			return nil
 		},
@@ -222,20 +268,228 @@ func stepOpenCmd() *ffcli.Command {
 }
 
 func stepAllCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("all", flag.ExitOnError)
+	inContainer := fs.Bool("container", false, "scrape with headless Chrome running in a Docker container")
+	image := fs.String("image", container.DefaultChromeImage, "Chrome image for -container (pin a version tag for reproducible runs)")
+	replay := fs.Bool("replay", false, "re-parse the latest saved HTML snapshot of each page instead of contacting X")
+	breakAfter := fs.String("break-after", "", "pause after these steps, comma-separated ("+strings.Join(app.BreakpointSteps, ", ")+"), showing where their output was saved")
+	edit := fs.Bool("edit", false, "at each -break-after pause, open the step's output in $EDITOR; the run continues with your edits")
+	profile := fs.String("profile", "", "analyze the scrape against these interest profiles from [profiles], comma-separated, building a digest for each")
+
 	return &ffcli.Command{
 		Name:       "all",
-		ShortUsage: "scroll4me step all",
+		ShortUsage: "scroll4me step all [-container [-image name] | -replay] [-break-after steps [-edit]] [-profile names]",
 		ShortHelp:  "Run the full pipeline (scrape -> analyze -> filter -> digest -> open)",
+		FlagSet:    fs,
 		Exec: func(ctx context.Context, args []string) error {
+			if *inContainer && *replay {
+				return fmt.Errorf("-container and -replay can't be combined")
+			}
 			a, err := initApp()
 			if err != nil {
 				return err
 			}
+			if *inContainer {
+				log.Printf("Starting Chrome container (%s)...", *image)
+				chrome, err := container.StartChrome(ctx, *image)
+				if err != nil {
+					return err
+				}
+				defer func() {
+					if err := chrome.Stop(); err != nil {
+						log.Printf("Failed to stop Chrome container: %v", err)
+					}
+				}()
+				a.UseRemoteBrowser(chrome.URL())
+			}
+			if *replay {
+				if err := a.UseReplay(); err != nil {
+					return err
+				}
+			}
+			if *breakAfter != "" {
+				if err := a.SetBreakpoints(strings.Split(*breakAfter, ","), pauseAtBreakpoint(*edit)); err != nil {
+					return err
+				}
+			}
+			if *profile != "" {
+				if err := a.UseProfiles(strings.Split(*profile, ",")); err != nil {
+					return err
+				}
+			}
+			defer context.AfterFunc(ctx, a.CancelPipeline)()
 			return a.GenerateDigest()
 		},
 	}
 }
 
+func stepHeadlinesCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "headlines",
+		ShortUsage: "scroll4me step headlines",
+		ShortHelp:  "Fast digest without LLM analysis (scrape -> top posts by engagement -> digest -> open)",
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			defer context.AfterFunc(ctx, a.CancelPipeline)()
+			return a.GenerateHeadlines()
+		},
+	}
+}
+
+func stepRegenerateCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("regenerate", flag.ExitOnError)
+	threshold := fs.Float64("threshold", 0, "relevance threshold override (default: from config)")
+	maxPosts := fs.Int("max-posts", 0, "max posts override (default: from config)")
+	noOpen := fs.Bool("no-open", false, "don't open digest after generating")
+
+	return &ffcli.Command{
+		Name:       "regenerate",
+		ShortUsage: "scroll4me step regenerate [-threshold n] [-max-posts n] [-no-open]",
+		ShortHelp:  "Re-filter and rebuild the digest from cached posts and analyses",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			digestPath, err := a.RegenerateDigest(*threshold, *maxPosts)
+			if err != nil {
+				return err
+			}
+			if !*noOpen {
+				a.OpenNewDigest(digestPath)
+			}
+			return nil
+		},
+	}
+}
+
+func stepReparseCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("reparse", flag.ExitOnError)
+	file := fs.String("file", "", "HTML snapshot file (default: latest from cache)")
+	out := fs.String("out", "", "write the posts JSON here instead of stdout")
+
+	return &ffcli.Command{
+		Name:       "reparse",
+		ShortUsage: "scroll4me step reparse [-file path] [-out path]",
+		ShortHelp:  "Re-run post extraction on a saved HTML snapshot, without contacting X",
+		LongHelp: "Snapshots are saved by scrapes with scraping.save_html_snapshots = true. The posts are\n" +
+			"extracted with the current selectors.toml and can be fed to 'step analyze -file'.",
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			posts, err := a.ReparseSnapshot(ctx, *file)
+			if err != nil {
+				return err
+			}
+			log.Printf("Extracted %d posts", len(posts))
+			data, err := json.MarshalIndent(posts, "", "  ")
+			if err != nil {
+				return err
+			}
+			if *out != "" {
+				return os.WriteFile(*out, data, 0644)
+			}
+			fmt.Println(string(data))
+			return nil
+		},
+	}
+}
+
+// =============================================================================
+// Digest Archive Commands
+// =============================================================================
+
+func digestsCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "digests",
+		ShortUsage: "scroll4me digests <subcommand>",
+		ShortHelp:  "Manage the digest archive",
+		Subcommands: []*ffcli.Command{
+			digestsRerenderCmd(),
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+func digestsRerenderCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("rerender", flag.ExitOnError)
+	since := fs.String("since", "7d", "re-render digests newer than this age (e.g. 7d, 36h)")
+
+	return &ffcli.Command{
+		Name:       "rerender",
+		ShortUsage: "scroll4me digests rerender [-since age]",
+		ShortHelp:  "Re-render archived digests with the current format",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			age, err := parseAge(*since)
+			if err != nil {
+				return err
+			}
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			_, err = a.RerenderDigests(time.Now().Add(-age))
+			return err
+		},
+	}
+}
+
+// parseAge parses a duration like time.ParseDuration, additionally
+// accepting a whole number of days such as "7d".
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q: %w", s, err)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q: %w", s, err)
+	}
+	return d, nil
+}
+
+// =============================================================================
+// Focus Command
+// =============================================================================
+
+func nowCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("now", flag.ExitOnError)
+	minutes := fs.Int("minutes", 5, "reading time to size the digest for")
+	scrape := fs.Bool("scrape", false, "scrape the feed now instead of using recently analyzed posts")
+
+	return &ffcli.Command{
+		Name:       "now",
+		ShortUsage: "scroll4me now [-minutes n] [-scrape]",
+		ShortHelp:  "Open a micro-digest of the freshest posts, sized to a reading time",
+		LongHelp: "For a deliberate, bounded check-in instead of opening X. Uses posts analyzed in the\n" +
+			"last few hours, or quickly scrapes and analyzes the feed if there are none.",
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if *minutes <= 0 {
+				return fmt.Errorf("-minutes must be positive, got %d", *minutes)
+			}
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			return a.FocusDigest(ctx, *minutes, *scrape)
+		},
+	}
+}
+
 // =============================================================================
 // Utility Commands
 // =============================================================================
@@ -243,17 +497,133 @@ func stepAllCmd() *ffcli.Command {
 func openCmd() *ffcli.Command {
 	return &ffcli.Command{
 		Name:       "open",
-		ShortUsage: "scroll4me open <config|cache|digest>",
-		ShortHelp:  "Open config file, cache directory, or latest digest",
+		ShortUsage: "scroll4me open <config|cache|selectors|digest|run>",
+		ShortHelp:  "Open config file, cache directory, selectors file, latest digest, or latest run manifest",
 		Exec: func(ctx context.Context, args []string) error {
 			if len(args) < 1 {
-				return fmt.Errorf("usage: scroll4me open <config|cache|digest>")
+				return fmt.Errorf("usage: scroll4me open <config|cache|selectors|digest|run>")
 			}
 			return runOpen(args[0])
 		},
 	}
 }
 
+func configBootstrapCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "bootstrap",
+		ShortUsage: "scroll4me config bootstrap",
+		ShortHelp:  "Pick interest keywords from the subjects that come up most in your feed",
+		LongHelp: "Has the LLM group the latest scraped posts (or a fresh scrape) by subject, lists the\n" +
+			"subjects, and adds the ones you pick to interests.keywords.",
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			topics, err := a.SuggestInterests(ctx)
+			if err != nil {
+				return err
+			}
+			if len(topics) == 0 {
+				return fmt.Errorf("no topics found in the feed")
+			}
+
+			fmt.Println("Subjects that come up most in your feed:")
+			for i, t := range topics {
+				fmt.Printf("  %2d. %s - %s (%d posts)\n", i+1, t.Keyword, t.Description, t.PostCount)
+			}
+			fmt.Print("Numbers to add as interests (e.g. 1,3,4), or Enter for none: ")
+			line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+			var picked []string
+			for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || r == ' ' }) {
+				n, err := strconv.Atoi(field)
+				if err != nil || n < 1 || n > len(topics) {
+					return fmt.Errorf("not a listed number: %q", field)
+				}
+				picked = append(picked, topics[n-1].Keyword)
+			}
+			if len(picked) == 0 {
+				fmt.Println("No interests added")
+				return nil
+			}
+
+			added, err := a.AddInterestKeywords(picked)
+			if err != nil {
+				return err
+			}
+			if len(added) == 0 {
+				fmt.Println("interests.keywords already has every picked subject")
+				return nil
+			}
+			fmt.Printf("Added %d keywords to interests.keywords: %s\n", len(added), strings.Join(added, ", "))
+			return nil
+		},
+	}
+}
+
+func configCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "config",
+		ShortUsage: "scroll4me config <subcommand>",
+		ShortHelp:  "Learn about config options, or fill them in from X",
+		Subcommands: []*ffcli.Command{
+			configExplainCmd(),
+			configImportMutedCmd(),
+			configBootstrapCmd(),
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+func configExplainCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "explain",
+		ShortUsage: "scroll4me config explain [key|section]",
+		ShortHelp:  "Print every config key (or one key or section) with its type, default, and effect",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("usage: scroll4me config explain [key|section]")
+			}
+			options := config.Options()
+			if len(args) == 1 {
+				var ok bool
+				if options, ok = config.LookupOptions(args[0]); !ok {
+					return fmt.Errorf("unknown config key or section: %s (run 'scroll4me config explain' to list them all)", args[0])
+				}
+			}
+			runExplain(options)
+			return nil
+		},
+	}
+}
+
+func configImportMutedCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "import-muted",
+		ShortUsage: "scroll4me config import-muted",
+		ShortHelp:  "Add the accounts you've muted or blocked on X to interests.muted_accounts",
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			added, err := a.ImportModeratedAccounts(ctx)
+			if err != nil {
+				return err
+			}
+			if len(added) == 0 {
+				fmt.Println("interests.muted_accounts already has every account muted or blocked on X")
+				return nil
+			}
+			fmt.Printf("Added %d accounts to interests.muted_accounts: %s\n", len(added), strings.Join(added, ", "))
+			return nil
+		},
+	}
+}
+
 func clearCmd() *ffcli.Command {
 	return &ffcli.Command{
 		Name:       "clear",
@@ -268,6 +638,240 @@ func clearCmd() *ffcli.Command {
 	}
 }
 
+func statsCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	changes := fs.Int("changes", 10, "number of recent config changes to show")
+
+	return &ffcli.Command{
+		Name:       "stats",
+		ShortUsage: "scroll4me stats [-changes n]",
+		ShortHelp:  "Show reading habits, selector health, and recent config changes",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			// Loading the config records any edits made since the last run
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			return runStats(a, *changes)
+		},
+	}
+}
+
+func reportCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "report",
+		ShortUsage: "scroll4me report <subcommand>",
+		ShortHelp:  "Reports for tuning the config",
+		Subcommands: []*ffcli.Command{
+			reportTuningCmd(),
+			reportCostCmd(),
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+func reportTuningCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("tuning", flag.ExitOnError)
+	runs := fs.Int("runs", 10, "number of recent runs to cover")
+	samples := fs.Int("samples", 3, "reasons to show per keyword for included posts and for near misses")
+
+	return &ffcli.Command{
+		Name:       "tuning",
+		ShortUsage: "scroll4me report tuning [-runs n] [-samples n]",
+		ShortHelp:  "Show how each interest keyword and mute fared over recent runs",
+		LongHelp: "Groups the posts analyzed in recent runs by the interest keywords they mention. For each keyword it\n" +
+			"shows how many posts made a digest and how many scored just under the threshold, with the model's\n" +
+			"reasons, and points out keywords and mutes that never matched a post, as candidates for removal.",
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			return runTuningReport(a, *runs, *samples)
+		},
+	}
+}
+
+func reportCostCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("cost", flag.ExitOnError)
+	since := fs.String("since", "30d", "include LLM calls newer than this age (e.g. 30d, 72h)")
+	perDay := fs.Int("per-day", 2, "full digest runs a day to project the monthly cost for")
+
+	return &ffcli.Command{
+		Name:       "cost",
+		ShortUsage: "scroll4me report cost [-since age] [-per-day n]",
+		ShortHelp:  "Show the tokens and cost of LLM calls per run",
+		LongHelp: "Totals the input and output tokens each run's LLM calls used, as reported by the API, and prices\n" +
+			"them at the model's list price. Ends with the average cost of a full digest run and what running\n" +
+			"that many a day would cost per month. Gateway discounts aren't accounted for.",
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			age, err := parseAge(*since)
+			if err != nil {
+				return err
+			}
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			return runCostReport(a, time.Now().Add(-age), *perDay)
+		},
+	}
+}
+
+func rateCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "rate",
+		ShortUsage: "scroll4me rate <subcommand>",
+		ShortHelp:  "Rate posts to calibrate relevance scoring to your taste",
+		LongHelp: "Rated posts are shown to the LLM with every analysis, the most recent 3 wanted and 3 unwanted,\n" +
+			"as examples of what you do and don't want in a digest.",
+		Subcommands: []*ffcli.Command{
+			rateVerdictCmd("good", true),
+			rateVerdictCmd("bad", false),
+			rateListCmd(),
+			rateRemoveCmd(),
+			rateServeCmd(),
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+// rateVerdictCmd builds "rate good" or "rate bad"
+func rateVerdictCmd(name string, good bool) *ffcli.Command {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	note := fs.String("note", "", "why, in a few words (shown to the LLM)")
+	wanted := "didn't want"
+	if good {
+		wanted = "wanted"
+	}
+
+	return &ffcli.Command{
+		Name:       name,
+		ShortUsage: fmt.Sprintf("scroll4me rate %s [-note text] <post ID or URL>", name),
+		ShortHelp:  fmt.Sprintf("Record that you %s to see a post", wanted),
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("usage: scroll4me rate %s [-note text] <post ID or URL>", name)
+			}
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			rating, err := a.RatePost(args[0], good, *note)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Rated %s by @%s as %s\n", rating.PostID, rating.AuthorHandle, name)
+			return nil
+		},
+	}
+}
+
+func rateListCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "list",
+		ShortUsage: "scroll4me rate list",
+		ShortHelp:  "List rated posts, newest first",
+		Exec: func(ctx context.Context, args []string) error {
+			ratings, err := store.LoadRatings()
+			if err != nil {
+				return err
+			}
+			if len(ratings) == 0 {
+				fmt.Println("No posts rated yet")
+				return nil
+			}
+			for _, r := range slices.Backward(ratings) {
+				verdict := "bad "
+				if r.Good {
+					verdict = "good"
+				}
+				fmt.Printf("%s  %s  %-20s @%s\n", r.RatedAt.Local().Format("2006-01-02"), verdict, r.PostID, r.AuthorHandle)
+				if r.Note != "" {
+					fmt.Printf("      %s\n", r.Note)
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func rateRemoveCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "remove",
+		ShortUsage: "scroll4me rate remove <post ID or URL>",
+		ShortHelp:  "Forget a post's rating",
+		Exec: func(ctx context.Context, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("usage: scroll4me rate remove <post ID or URL>")
+			}
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			removed, err := a.RemoveRating(args[0])
+			if err != nil {
+				return err
+			}
+			if !removed {
+				return fmt.Errorf("%s isn't rated", args[0])
+			}
+			fmt.Println("Rating removed")
+			return nil
+		},
+	}
+}
+
+func rateServeCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "serve",
+		ShortUsage: "scroll4me rate serve",
+		ShortHelp:  "Answer the 👍/👎 links in digests without the tray app running",
+		Exec: func(ctx context.Context, args []string) error {
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			if a.Config().Digest.FeedbackPort == 0 {
+				return fmt.Errorf("digest.feedback_port isn't set, so digests have no rating links")
+			}
+			a.ServeFeedback(ctx)
+			return nil
+		},
+	}
+}
+
+func graphCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("graph", flag.ExitOnError)
+	since := fs.String("since", "30d", "include analyses newer than this age (e.g. 30d, 72h)")
+
+	return &ffcli.Command{
+		Name:       "graph",
+		ShortUsage: "scroll4me graph [-since age]",
+		ShortHelp:  "Open a graph of topics over time and author co-occurrence",
+		FlagSet:    fs,
+		Exec: func(ctx context.Context, args []string) error {
+			age, err := parseAge(*since)
+			if err != nil {
+				return err
+			}
+			a, err := initApp()
+			if err != nil {
+				return err
+			}
+			return a.ViewGraph(time.Now().Add(-age))
+		},
+	}
+}
+
 func loginCmd() *ffcli.Command {
 	return &ffcli.Command{
 		Name:       "login",
@@ -296,13 +900,56 @@ func logoutCmd() *ffcli.Command {
 }
 
 func botTestCmd() *ffcli.Command {
+	fs := flag.NewFlagSet("bottest", flag.ExitOnError)
+	headless := fs.Bool("headless", false, "score headless Chrome, as scheduled scrapes run, and exit")
+
 	return &ffcli.Command{
 		Name:       "bottest",
-		ShortUsage: "scroll4me bottest",
-		ShortHelp:  "Open bot.sannysoft.com to audit browser fingerprint",
+		ShortUsage: "scroll4me bottest [-headless]",
+		ShortHelp:  "Score the browser fingerprint on bot.sannysoft.com",
+		LongHelp: "Loads bot.sannysoft.com with the scraper's stealth options and fingerprint patches,\n" +
+			"then prints how many of its checks pass and which fail. Exits non-zero if any fail.",
+		FlagSet: fs,
 		Exec: func(ctx context.Context, args []string) error {
-			runBotTest()
-			return nil
+			return runBotTest(ctx, *headless)
+		},
+	}
+}
+
+// =============================================================================
+// Developer Commands
+// =============================================================================
+
+func devCmd() *ffcli.Command {
+	return &ffcli.Command{
+		Name:       "dev",
+		ShortUsage: "scroll4me dev <subcommand>",
+		ShortHelp:  "Tools for working on scroll4me",
+		Subcommands: []*ffcli.Command{
+			devE2ECmd(),
+		},
+		Exec: func(ctx context.Context, args []string) error {
+			return flag.ErrHelp
+		},
+	}
+}
+
+func devE2ECmd() *ffcli.Command {
+	fs := flag.NewFlagSet("e2e", flag.ExitOnError)
+	headless := fs.Bool("headless", true, "run Chrome without a window")
+	keep := fs.Bool("keep", false, "keep the run's config, cache, and digest even if it passes")
+
+	return &ffcli.Command{
+		Name:       "e2e",
+		ShortUsage: "scroll4me dev e2e [-headless=false] [-keep]",
+		ShortHelp:  "Run the full pipeline against a local fake X and check the results",
+		LongHelp: "Serves a fake X home timeline from bundled fixtures on a local port, points the scraper at it,\n" +
+			"and runs scrape, analyze, filter, and digest with a fake LLM provider. Then compares the scraped\n" +
+			"posts and the digest with the fixtures and exits non-zero on any mismatch. Needs Chrome, but no\n" +
+			"X account or API key; the run uses a temporary data directory, not your config or cache.",
+		FlagSet: fs,
+		Exec: func(ctx context.Context, args []string) error {
+			return runE2E(ctx, *headless, *keep)
 		},
 	}
 }
@@ -376,15 +1023,23 @@ func initApp() (*app.App, error) {
 		}
 	}
 
+	// Clean up after earlier runs that crashed with Chrome open
+	if _, err := browseropts.SweepOrphans(); err != nil {
+		log.Printf("Failed to look for orphaned Chrome processes: %v", err)
+	}
+
 	cookieStorePath, err := auth.DefaultCookieStorePath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cookie store path: %w", err)
 	}
 	cookieStore := auth.NewCookieStore(cookieStorePath)
-	authManager := auth.NewManager(cookieStore)
+	authManager := auth.NewManager(cookieStore, cfg.Scraping.ProfileDir)
 
 	// Use headless for CLI
-	postScraper := scraper.New(true, false)
+	scraping := cfg.Scraping
+	scraping.Headless = true
+	scraping.DebugPauseAfterScrape = false
+	postScraper := scraper.FromConfig(scraping)
 
 	postAnalyzer, err := analyzer.New(cfg.Analysis, cfg.Interests)
 	if err != nil {
@@ -398,7 +1053,7 @@ func initApp() (*app.App, error) {
 // Command Implementations
 // =============================================================================
 
-func runTrayApp() {
+func runTrayApp(ctx context.Context) {
 	cfg, err := config.Load()
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -415,14 +1070,19 @@ func runTrayApp() {
 		}
 	}
 
+	// Clean up after earlier runs that crashed with Chrome open
+	if _, err := browseropts.SweepOrphans(); err != nil {
+		log.Printf("Failed to look for orphaned Chrome processes: %v", err)
+	}
+
 	cookieStorePath, err := auth.DefaultCookieStorePath()
 	if err != nil {
 		log.Fatalf("Failed to get cookie store path: %v", err)
 	}
 	cookieStore := auth.NewCookieStore(cookieStorePath)
-	authManager := auth.NewManager(cookieStore)
+	authManager := auth.NewManager(cookieStore, cfg.Scraping.ProfileDir)
 
-	postScraper := scraper.New(cfg.Scraping.Headless, cfg.Scraping.DebugPauseAfterScrape)
+	postScraper := scraper.FromConfig(cfg.Scraping)
 
 	postAnalyzer, err := analyzer.New(cfg.Analysis, cfg.Interests)
 	if err != nil {
@@ -433,33 +1093,118 @@ func runTrayApp() {
 
 	log.Println("scroll4me starting...")
 
+	// Ctrl-C quits like the tray's Quit item
+	defer context.AfterFunc(ctx, func() {
+		a.CancelPipeline()
+		systray.Quit()
+	})()
 	systray.Run(tray.OnReady(a), tray.OnExit)
 }
 
-func runBotTest() {
-	log.Println("Opening bot.sannysoft.com with stealth browser options...")
+func runBotTest(ctx context.Context, headless bool) error {
+	log.Printf("Opening %s with stealth browser options...", browseropts.BotTestURL)
 
-	opts := browseropts.Options(false)
+	opts := browseropts.Options(headless, "")
 
-	allocCtx, cancel := chromedp.NewExecAllocator(context.Background(), opts...)
+	allocCtx, cancel := chromedp.NewExecAllocator(ctx, opts...)
 	defer cancel()
 
-	ctx, cancel := chromedp.NewContext(allocCtx)
+	browserCtx, cancel := chromedp.NewContext(allocCtx)
 	defer cancel()
 
-	go func() {
-		err := chromedp.Run(ctx,
-			chromedp.Navigate("https://bot.sannysoft.com"),
-		)
-		if err != nil {
-			log.Printf("Failed to navigate: %v", err)
+	timedCtx, cancel := context.WithTimeout(browserCtx, time.Minute)
+	defer cancel()
+	checks, err := browseropts.ScoreBotTest(timedCtx)
+	if err != nil {
+		return fmt.Errorf("bot test failed: %w", err)
+	}
+
+	var failed []browseropts.BotCheck
+	for _, c := range checks {
+		if !c.Passed {
+			failed = append(failed, c)
 		}
-	}()
+	}
+	fmt.Printf("Passed %d of %d checks\n", len(checks)-len(failed), len(checks))
+	for _, c := range failed {
+		fmt.Printf("  FAILED %s: %s\n", c.Name, c.Result)
+	}
 
-	fmt.Println("Press Enter to end program...")
-	fmt.Scanln()
+	if !headless {
+		fmt.Println("Press Enter to end program...")
+		fmt.Scanln()
+	}
 
-	log.Println("Done.")
+	if len(failed) > 0 {
+		return fmt.Errorf("%d fingerprint checks failed", len(failed))
+	}
+	return nil
+}
+
+func runE2E(ctx context.Context, headless, keep bool) error {
+	result, err := e2e.Run(ctx, e2e.Options{Headless: headless, Keep: keep})
+	if result != nil && result.Dir != "" {
+		fmt.Printf("Run data kept in %s\n", result.Dir)
+	}
+	if err != nil {
+		return fmt.Errorf("e2e run failed: %w", err)
+	}
+
+	fmt.Printf("Scraped %d posts, %d relevant, digest %s\n", result.Scraped, result.Relevant, result.Digest)
+	for _, p := range result.Problems {
+		fmt.Printf("  MISMATCH %s\n", p)
+	}
+	if len(result.Problems) > 0 {
+		return fmt.Errorf("%d mismatches with the fixtures", len(result.Problems))
+	}
+	fmt.Println("All checks passed")
+	return nil
+}
+
+// pauseAtBreakpoint returns a breakpoint that prints where the step's output
+// was saved, opens it in $EDITOR if edit is set, and waits for Enter. Typing
+// q stops the run.
+func pauseAtBreakpoint(edit bool) app.Breakpoint {
+	stdin := bufio.NewReader(os.Stdin)
+	return func(ctx context.Context, step, path string) error {
+		fmt.Printf("\nPaused after %s. Output: %s\n", step, path)
+		if edit {
+			if err := runEditor(ctx, path); err != nil {
+				fmt.Printf("Failed to run editor: %v\n", err)
+			}
+		}
+		fmt.Print("Press Enter to continue, or q then Enter to stop: ")
+
+		line := make(chan string, 1)
+		go func() {
+			s, _ := stdin.ReadString('\n')
+			line <- s
+		}()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case s := <-line:
+			if strings.EqualFold(strings.TrimSpace(s), "q") {
+				return fmt.Errorf("stopped by user")
+			}
+			return nil
+		}
+	}
+}
+
+// runEditor opens path in $EDITOR (notepad or vi if unset) and waits for it
+// to exit
+func runEditor(ctx context.Context, path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+		if runtime.GOOS == "windows" {
+			editor = []string{"notepad"}
+		}
+	}
+	cmd := exec.CommandContext(ctx, editor[0], append(editor[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return cmd.Run()
 }
 
 func runOpen(target string) error {
@@ -471,6 +1216,19 @@ func runOpen(target string) error {
 		path, err = config.ConfigPath()
 	case "cache":
 		path, err = config.CacheDir()
+	case "selectors":
+		// Start from the built-in selectors the first time
+		path, err = scraper.SelectorsPath()
+		if err == nil {
+			if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+				if err := scraper.WriteDefaultSelectors(path); err != nil {
+					return fmt.Errorf("failed to write %s: %w", path, err)
+				}
+				log.Printf("Wrote built-in selectors to %s", path)
+			}
+		}
+	case "run":
+		path, err = store.LatestRunManifest()
 	case "digest":
 		a, initErr := initApp()
 		if initErr != nil {
@@ -478,7 +1236,7 @@ func runOpen(target string) error {
 		}
 		return a.ViewLastDigest()
 	default:
-		return fmt.Errorf("unknown target: %s (use 'config', 'cache', or 'digest')", target)
+		return fmt.Errorf("unknown target: %s (use 'config', 'cache', 'selectors', 'digest', or 'run')", target)
 	}
 
 	if err != nil {
@@ -491,6 +1249,173 @@ func runOpen(target string) error {
 	return nil
 }
 
+func runStats(a *app.App, maxChanges int) error {
+	habits, err := a.ReadingHabits(time.Now().AddDate(0, 0, -30))
+	if err != nil {
+		return fmt.Errorf("failed to load usage log: %w", err)
+	}
+	fmt.Println("Reading habits (last 30 days):")
+	fmt.Printf("  Digests generated: %d\n", habits.Generated)
+	fmt.Printf("  Came back to:      %d\n", habits.Reopened)
+	fmt.Printf("  Times reopened:    %d\n", habits.Opens)
+	if habits.Reopened > 0 {
+		fmt.Printf("  Average wait:      %s\n", habits.AvgTimeToReopen.Round(time.Minute))
+	}
+	unread, err := a.UnreadDigests()
+	if err != nil {
+		return fmt.Errorf("failed to load usage log: %w", err)
+	}
+	fmt.Printf("  Never opened:      %d\n", len(unread))
+	fmt.Println()
+
+	report, err := store.LoadSelectorReport()
+	if err != nil {
+		return fmt.Errorf("failed to load selector report: %w", err)
+	}
+	if report != nil {
+		fmt.Printf("Selector health (%s, %s):\n", report.Scrape, report.At.Local().Format("2006-01-02 15:04"))
+		broken, fallback := report.Problems()
+		if len(broken) == 0 && len(fallback) == 0 {
+			fmt.Println("  all selectors healthy")
+		}
+		if len(broken) > 0 {
+			fmt.Printf("  broken:        %s\n", strings.Join(broken, ", "))
+		}
+		if len(fallback) > 0 {
+			fmt.Printf("  on fallbacks:  %s\n", strings.Join(fallback, ", "))
+		}
+		fmt.Println()
+	}
+
+	history, err := store.LoadConfigHistory()
+	if err != nil {
+		return fmt.Errorf("failed to load config history: %w", err)
+	}
+
+	fmt.Println("Recent config changes:")
+	if len(history.Changes) == 0 {
+		fmt.Println("  none recorded yet")
+		return nil
+	}
+	recent := history.Changes[max(len(history.Changes)-maxChanges, 0):]
+	for i := len(recent) - 1; i >= 0; i-- {
+		c := recent[i]
+		fmt.Printf("  %s\n", c.At.Local().Format("2006-01-02 15:04"))
+		for _, line := range c.Changes {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+	return nil
+}
+
+func runTuningReport(a *app.App, runs, samples int) error {
+	report, err := a.TuningReport(runs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Last %d runs: %d posts analyzed, %d in digests (threshold %.0f%%, near misses from %.0f%%)\n\n",
+		report.Runs, report.Posts, report.Included, report.Threshold*100, (report.Threshold-app.NearMissMargin)*100)
+
+	printKeyword := func(name string, k app.KeywordTuning) {
+		if k.Matched == 0 {
+			fmt.Printf("  %s: never matched - consider removing it\n", name)
+			return
+		}
+		fmt.Printf("  %s: %d posts, %d in digests, %d near misses\n", name, k.Matched, len(k.Included), len(k.NearMisses))
+		if len(k.Included) == 0 {
+			fmt.Println("    none made a digest - consider rewording or removing it")
+		}
+		for _, p := range k.Included[:min(samples, len(k.Included))] {
+			fmt.Printf("    + %3.0f%% @%s: %s\n", p.Score*100, p.AuthorHandle, p.Reason)
+		}
+		for _, p := range k.NearMisses[:min(samples, len(k.NearMisses))] {
+			fmt.Printf("    ~ %3.0f%% @%s: %s\n", p.Score*100, p.AuthorHandle, p.Reason)
+		}
+	}
+
+	fmt.Println("Interest keywords:")
+	if len(report.Keywords) == 0 {
+		fmt.Println("  none configured")
+	}
+	for _, k := range report.Keywords {
+		printKeyword(k.Keyword, k)
+	}
+	if len(report.Other.Included)+len(report.Other.NearMisses) > 0 {
+		printKeyword("(no keyword)", report.Other)
+	}
+
+	printMutes := func(title string, mutes []app.MuteTuning) {
+		if len(mutes) == 0 {
+			return
+		}
+		fmt.Printf("\n%s:\n", title)
+		for _, m := range mutes {
+			if m.Hits == 0 {
+				fmt.Printf("  %s: never matched - consider removing it\n", m.Mute)
+			} else {
+				fmt.Printf("  %s: %d posts\n", m.Mute, m.Hits)
+			}
+		}
+	}
+	printMutes("Muted keywords", report.MutedKeywords)
+	printMutes("Muted accounts", report.MutedAccounts)
+	return nil
+}
+
+func runCostReport(a *app.App, since time.Time, perDay int) error {
+	report, err := a.CostReport(since)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("LLM usage since %s:\n", since.Local().Format("2006-01-02 15:04"))
+	if report.Total.Calls == 0 {
+		fmt.Println("  no calls recorded")
+		return nil
+	}
+
+	printCost := func(label string, c app.RunCost) {
+		fmt.Printf("  %-32s %3d calls %9d in %8d out  $%.4f", label, c.Calls, c.InputTokens, c.OutputTokens, c.Cost)
+		if c.CachedTokens > 0 {
+			fmt.Printf("  (%.0f%% of input cached)", float64(c.CachedTokens)/float64(c.InputTokens)*100)
+		}
+		fmt.Println()
+	}
+	for _, run := range report.Runs {
+		kind := run.Kind
+		if kind == "" {
+			kind = "run"
+		}
+		printCost(run.Run+" "+kind, run)
+	}
+	if report.Other.Calls > 0 {
+		printCost("outside runs", report.Other)
+	}
+	printCost("total", report.Total)
+
+	if avg, n := report.AverageCost("digest"); n > 0 {
+		fmt.Printf("\nAverage full digest run: $%.4f over %d runs\n", avg, n)
+		fmt.Printf("At %d a day: about $%.2f per 30 days\n", perDay, avg*float64(perDay)*30)
+	}
+	if len(report.Unpriced) > 0 {
+		fmt.Printf("\nNo known price for %s; their tokens are counted but not their cost\n", strings.Join(report.Unpriced, ", "))
+	}
+	return nil
+}
+
+func runExplain(options []config.Option) {
+	for i, o := range options {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%s, default %s)\n", o.Key, o.Type, o.Default)
+		description := o.Description
+		if description == "" {
+			description = "(undocumented)"
+		}
+		fmt.Printf("  %s\n", description)
+	}
+}
+
 func runClear(target string) error {
 	switch target {
 	case "cache":